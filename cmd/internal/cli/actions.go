@@ -131,9 +131,6 @@ func replaceURIWithImage(ctx context.Context, cmd *cobra.Command, args []string)
 		return
 	}
 
-	var image string
-	var err error
-
 	// Create a cache handle only when we know we are are using a URI
 	imgCache := getCacheHandle(cache.Config{Disable: disableCache})
 	if imgCache == nil {
@@ -148,28 +145,35 @@ func replaceURIWithImage(ctx context.Context, cmd *cobra.Command, args []string)
 		return
 	}
 
+	image, err := handleURI(ctx, imgCache, cmd, t, args[0])
+	if err != nil {
+		sylog.Fatalf("Unable to handle %s uri: %v", args[0], err)
+	}
+
+	args[0] = image
+}
+
+// handleURI returns the path of the image src, of transport t, in imgCache,
+// fetching it if it is not already cached. Pulling with --to-cache uses it
+// too, so that the images it caches are resolved by the actions.
+func handleURI(ctx context.Context, imgCache *cache.Handle, cmd *cobra.Command, t, src string) (string, error) {
 	switch t {
 	case uri.Library:
-		image, err = handleLibrary(ctx, imgCache, args[0])
+		return handleLibrary(ctx, imgCache, src)
 	case uri.Oras:
-		image, err = handleOras(ctx, imgCache, cmd, args[0])
+		return handleOras(ctx, imgCache, cmd, src)
 	case uri.Shub:
-		image, err = handleShub(ctx, imgCache, args[0])
+		return handleShub(ctx, imgCache, src)
 	case oci.IsSupported(t):
-		image, err = handleOCI(ctx, imgCache, cmd, args[0])
+		return handleOCI(ctx, imgCache, cmd, src)
 	case uri.HTTP:
-		image, err = handleNet(ctx, imgCache, args[0])
+		return handleNet(ctx, imgCache, src)
 	case uri.HTTPS:
-		image, err = handleNet(ctx, imgCache, args[0])
+		return handleNet(ctx, imgCache, src)
 	default:
 		sylog.Fatalf("Unsupported transport type: %s", t)
 	}
-
-	if err != nil {
-		sylog.Fatalf("Unable to handle %s uri: %v", args[0], err)
-	}
-
-	args[0] = image
+	return "", nil
 }
 
 // setVM will set the --vm option if needed by other options
@@ -0,0 +1,37 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/docs"
+	"github.com/sylabs/singularity/pkg/cmdline"
+)
+
+func init() {
+	addCmdInit(func(cmdManager *cmdline.CommandManager) {
+		cmdManager.RegisterCmd(AliasCmd)
+		cmdManager.RegisterSubCmd(AliasCmd, AliasListCmd)
+		cmdManager.RegisterSubCmd(AliasCmd, AliasRemoveCmd)
+	})
+}
+
+// AliasCmd is the 'alias' command that manages the image aliases recorded
+// with pull --alias.
+var AliasCmd = &cobra.Command{
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return errors.New("invalid command")
+	},
+	DisableFlagsInUseLine: true,
+
+	Use:           docs.AliasUse,
+	Short:         docs.AliasShort,
+	Long:          docs.AliasLong,
+	Example:       docs.AliasExample,
+	SilenceErrors: true,
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/docs"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/syfs"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// AliasListCmd is 'singularity alias list', listing the recorded aliases.
+var AliasListCmd = &cobra.Command{
+	Args:                  cobra.ExactArgs(0),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		aliases, err := client.ReadAliases(syfs.Aliases())
+		if err != nil {
+			sylog.Fatalf("While reading aliases: %v", err)
+		}
+		list := make([]client.Alias, 0, len(aliases))
+		for _, a := range aliases {
+			list = append(list, a)
+		}
+		client.SortAliases(list)
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tSOURCE\tDIGEST\tCREATED")
+		for _, a := range list {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Name, a.Source, a.Digest, a.Created.Local().Format("2006-01-02 15:04:05"))
+		}
+		tw.Flush()
+	},
+
+	Use:     docs.AliasListUse,
+	Short:   docs.AliasListShort,
+	Long:    docs.AliasListLong,
+	Example: docs.AliasListExample,
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/docs"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/syfs"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// AliasRemoveCmd is 'singularity alias remove <name>...', removing aliases.
+// The images pulled for them are kept.
+var AliasRemoveCmd = &cobra.Command{
+	Args:                  cobra.MinimumNArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := syfs.Aliases()
+		aliases, err := client.ReadAliases(path)
+		if err != nil {
+			sylog.Fatalf("While reading aliases: %v", err)
+		}
+		for _, name := range args {
+			if _, ok := aliases[name]; !ok {
+				sylog.Fatalf("No alias named %s", name)
+			}
+			delete(aliases, name)
+		}
+		if err := client.WriteAliases(path, aliases); err != nil {
+			sylog.Fatalf("While removing aliases: %v", err)
+		}
+	},
+
+	Use:     docs.AliasRemoveUse,
+	Short:   docs.AliasRemoveShort,
+	Long:    docs.AliasRemoveLong,
+	Example: docs.AliasRemoveExample,
+}
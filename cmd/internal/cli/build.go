@@ -48,6 +48,8 @@ var buildArgs struct {
 	nvccli        bool
 	rocm          bool
 	writableTmpfs bool // For test section only
+	safeSymlinks  bool
+	rawSymlinks   bool
 }
 
 // -s|--sandbox
@@ -207,6 +209,26 @@ var buildFixPermsFlag = cmdline.Flag{
 	EnvKeys:      []string{"FIXPERMS"},
 }
 
+// --safe-symlinks
+var buildSafeSymlinksFlag = cmdline.Flag{
+	ID:           "buildSafeSymlinksFlag",
+	Value:        &buildArgs.safeSymlinks,
+	DefaultValue: true,
+	Name:         "safe-symlinks",
+	Usage:        "rewrite symlinks resolving outside of a --sandbox when followed from the host",
+	EnvKeys:      []string{"SAFE_SYMLINKS"},
+}
+
+// --raw-symlinks
+var buildRawSymlinksFlag = cmdline.Flag{
+	ID:           "buildRawSymlinksFlag",
+	Value:        &buildArgs.rawSymlinks,
+	DefaultValue: false,
+	Name:         "raw-symlinks",
+	Usage:        "keep the symlinks of a --sandbox as they are in the image (disables --safe-symlinks)",
+	EnvKeys:      []string{"RAW_SYMLINKS"},
+}
+
 // --nv
 var buildNvFlag = cmdline.Flag{
 	ID:           "nvFlag",
@@ -313,6 +335,8 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&buildBindFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&buildMountFlag, buildCmd)
 		cmdManager.RegisterFlagForCmd(&buildWritableTmpfsFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&buildSafeSymlinksFlag, buildCmd)
+		cmdManager.RegisterFlagForCmd(&buildRawSymlinksFlag, buildCmd)
 	})
 }
 
@@ -141,6 +141,20 @@ func runBuild(cmd *cobra.Command, args []string) {
 		os.Setenv("SINGULARITY_WRITABLE_TMPFS", "1")
 	}
 
+	if buildArgs.rawSymlinks && buildArgs.safeSymlinks && cmd.Flags().Changed(buildSafeSymlinksFlag.Name) {
+		sylog.Fatalf("Conflicting arguments; --safe-symlinks cannot be used with --raw-symlinks")
+	}
+	if !buildArgs.sandbox {
+		for _, name := range []string{buildSafeSymlinksFlag.Name, buildRawSymlinksFlag.Name} {
+			if cmd.Flags().Changed(name) {
+				sylog.Warningf("--%s only applies to --sandbox builds, ignoring", name)
+			}
+		}
+	}
+	if !buildArgs.safeSymlinks {
+		buildArgs.rawSymlinks = true
+	}
+
 	if buildArgs.arch != runtime.GOARCH && !buildArgs.remote {
 		sylog.Fatalf("Requested architecture (%s) does not match host (%s). Cannot build locally.", buildArgs.arch, runtime.GOARCH)
 	}
@@ -271,6 +285,8 @@ func runBuildRemote(ctx context.Context, cmd *cobra.Command, dst, spec string) {
 						TmpDir:   tmpDir,
 						Update:   buildArgs.update,
 						Force:    forceOverwrite,
+
+						RawSymlinks: buildArgs.rawSymlinks,
 					},
 				})
 			if err != nil {
@@ -405,6 +421,7 @@ func runBuildLocal(ctx context.Context, cmd *cobra.Command, dst, spec string) {
 				EncryptionKeyInfo: keyInfo,
 				FixPerms:          buildArgs.fixPerms,
 				SandboxTarget:     sandboxTarget,
+				RawSymlinks:       buildArgs.rawSymlinks,
 			},
 		})
 	if err != nil {
@@ -0,0 +1,52 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/docs"
+	"github.com/sylabs/singularity/internal/app/singularity"
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+func init() {
+	addCmdInit(func(cmdManager *cmdline.CommandManager) {
+		cmdManager.RegisterFlagForCmd(&cacheGCDryFlag, cacheGCCmd)
+	})
+}
+
+var (
+	cacheGCDry bool
+
+	// -n|--dry-run
+	cacheGCDryFlag = cmdline.Flag{
+		ID:           "cacheGCDryFlag",
+		Value:        &cacheGCDry,
+		DefaultValue: false,
+		Name:         "dry-run",
+		ShortHand:    "n",
+		Usage:        "report duplicate cache entries without deduplicating them",
+	}
+
+	// cacheGCCmd is 'singularity cache gc' and will deduplicate your local singularity cache
+	cacheGCCmd = &cobra.Command{
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(0),
+		Run: func(cmd *cobra.Command, args []string) {
+			imgCache := getCacheHandle(cache.Config{})
+			if err := singularity.GarbageCollectSingularityCache(imgCache, cacheGCDry); err != nil {
+				sylog.Fatalf("Cache garbage collection failed: %v", err)
+			}
+		},
+
+		Use:     docs.CacheGCUse,
+		Short:   docs.CacheGCShort,
+		Long:    docs.CacheGCLong,
+		Example: docs.CacheGCExample,
+	}
+)
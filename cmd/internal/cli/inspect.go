@@ -21,6 +21,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/sylabs/sif/v2/pkg/sif"
 	"github.com/sylabs/singularity/docs"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/util/env"
 	"github.com/sylabs/singularity/pkg/cmdline"
 	"github.com/sylabs/singularity/pkg/image"
@@ -44,6 +45,7 @@ var (
 	labels      bool
 	deffile     bool
 	jsonfmt     bool
+	showArch    bool
 )
 
 // -l|--labels
@@ -145,6 +147,15 @@ var inspectHelpfileFlag = cmdline.Flag{
 	Usage:        "inspect the runscript helpfile, if it exists",
 }
 
+// --arch
+var inspectArchFlag = cmdline.Flag{
+	ID:           "inspectArchFlag",
+	Value:        &showArch,
+	DefaultValue: false,
+	Name:         "arch",
+	Usage:        "show the architecture recorded in a SIF image",
+}
+
 // --all
 var inspectAllFlag = cmdline.Flag{
 	ID:           "inspectAllFlag",
@@ -169,6 +180,7 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&inspectTestFlag, InspectCmd)
 		cmdManager.RegisterFlagForCmd(&inspectAppsListFlag, InspectCmd)
 		cmdManager.RegisterFlagForCmd(&inspectAllFlag, InspectCmd)
+		cmdManager.RegisterFlagForCmd(&inspectArchFlag, InspectCmd)
 	})
 }
 
@@ -609,6 +621,27 @@ func printSortedMap(m map[string]string, fn func(key string)) {
 	}
 }
 
+// inspectArch prints the architecture recorded in the SIF image at path,
+// as JSON if requested.
+func inspectArch(path string) {
+	arch, err := client.ImageArch(path)
+	if err != nil {
+		sylog.Fatalf("Could not inspect architecture of %s: %s", path, err)
+	}
+	if !jsonfmt {
+		fmt.Println(arch)
+		return
+	}
+
+	md := inspect.NewMetadata()
+	md.Attributes.Arch = arch
+	jsonObj, err := json.MarshalIndent(md, "", "\t")
+	if err != nil {
+		sylog.Fatalf("Could not format inspected data as JSON")
+	}
+	fmt.Printf("%s\n", string(jsonObj))
+}
+
 // returns true if flags for other forms of information are unset.
 func defaultToLabels() bool {
 	return !(helpfile || deffile || runscript || startscript || testfile || environment || listApps)
@@ -626,6 +659,13 @@ var InspectCmd = &cobra.Command{
 	Example: docs.InspectExample,
 
 	Run: func(cmd *cobra.Command, args []string) {
+		// The architecture is read before the image is opened, as an image
+		// that can't run on the host is refused then.
+		if showArch && !allData {
+			inspectArch(args[0])
+			return
+		}
+
 		img, err := image.Init(args[0], false)
 		if err != nil {
 			sylog.Fatalf("Failed to open image %s: %s", args[0], err)
@@ -685,6 +725,14 @@ var InspectCmd = &cobra.Command{
 			sylog.Debugf("Listing all apps in container")
 		}
 
+		if allData && img.Type == image.SIF {
+			arch, err := client.ImageArch(img.Path)
+			if err != nil {
+				sylog.Warningf("Unable to read architecture: %s", err)
+			}
+			inspectCmd.metadata.Attributes.Arch = arch
+		}
+
 		inspectData, err := inspectCmd.getMetadata()
 		if err != nil {
 			sylog.Fatalf("%s", err)
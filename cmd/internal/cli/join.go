@@ -0,0 +1,50 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/docs"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+func init() {
+	addCmdInit(func(cmdManager *cmdline.CommandManager) {
+		cmdManager.RegisterCmd(JoinCmd)
+		cmdManager.RegisterFlagForCmd(&commonForceFlag, JoinCmd)
+	})
+}
+
+// JoinCmd is 'singularity join <manifest> [<output>]', reassembling a SIF
+// split into chunks by pull --split.
+var JoinCmd = &cobra.Command{
+	Args:                  cobra.RangeArgs(1, 2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifest := args[0]
+		m, err := client.ReadSplitManifest(manifest)
+		if err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		dest := filepath.Join(filepath.Dir(manifest), m.File)
+		if len(args) == 2 {
+			dest = args[1]
+		}
+		if err := client.JoinChunks(manifest, dest, forceOverwrite); err != nil {
+			sylog.Fatalf("While reassembling %s: %v", m.File, err)
+		}
+		sylog.Infof("Reassembled %s from %d chunks, digest %s verified", dest, len(m.Chunks), m.Digest)
+	},
+
+	Use:     docs.JoinUse,
+	Short:   docs.JoinShort,
+	Long:    docs.JoinLong,
+	Example: docs.JoinExample,
+}
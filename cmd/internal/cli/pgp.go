@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/sylabs/singularity/internal/pkg/buildcfg"
 
@@ -77,6 +78,20 @@ func selectEntityAtIndex(i int) sypgp.EntitySelector {
 	}
 }
 
+// selectEntityByFingerprint returns an EntitySelector that selects the entity with the
+// fingerprint fp, ignoring case and any whitespace in fp.
+func selectEntityByFingerprint(fp string) sypgp.EntitySelector {
+	fp = strings.Join(strings.Fields(fp), "")
+	return func(el openpgp.EntityList) (*openpgp.Entity, error) {
+		for _, e := range el {
+			if strings.EqualFold(hex.EncodeToString(e.PrimaryKey.Fingerprint), fp) {
+				return e, nil
+			}
+		}
+		return nil, fmt.Errorf("key with fingerprint %s not found in private keyring", fp)
+	}
+}
+
 // decryptSelectedEntityInteractive wraps f, attempting to decrypt the private key in the selected
 // entity with a passpharse provided interactively by the user.
 func decryptSelectedEntityInteractive(f sypgp.EntitySelector) sypgp.EntitySelector {
@@ -7,13 +7,21 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
+	units "github.com/docker/go-units"
 	"github.com/spf13/cobra"
+	scslibrary "github.com/sylabs/scs-library-client/client"
 	"github.com/sylabs/singularity/docs"
 	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/client/library"
 	"github.com/sylabs/singularity/internal/pkg/client/net"
 	"github.com/sylabs/singularity/internal/pkg/client/oci"
@@ -23,6 +31,7 @@ import (
 	"github.com/sylabs/singularity/internal/pkg/util/uri"
 	"github.com/sylabs/singularity/pkg/cmdline"
 	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/sypgp"
 )
 
 const (
@@ -38,26 +47,154 @@ const (
 	HTTPSProtocol = "https"
 	// OrasProtocol holds the oras URI.
 	OrasProtocol = "oras"
+	// StdinSource is the pull source reading an image archive from stdin.
+	StdinSource = "-"
+	// AliasProtocol is the transport of the aliases recorded with --alias.
+	AliasProtocol = "alias"
+
+	// pullDestEnv holds the name template used to compute the destination
+	// of a pull when no output file is specified, e.g. {name}_{tag}_{arch}.sif
+	pullDestEnv = "SINGULARITY_PULL_DEST"
+	// pullMetricsTokenEnv holds the bearer token sent to the
+	// --metrics-remote-write endpoint, if set.
+	pullMetricsTokenEnv = "SINGULARITY_METRICS_REMOTE_WRITE_TOKEN"
 )
 
-var (
-	// pullLibraryURI holds the base URI to a Sylabs library API instance.
-	pullLibraryURI string
-	// pullImageName holds the name to be given to the pulled image.
-	pullImageName string
-	// unauthenticatedPull when true; won't ask to keep a unsigned container after pulling it.
-	unauthenticatedPull bool
-	// pullDir is the path that the containers will be pulled to, if set.
-	pullDir string
-	// pullArch is the architecture for which containers will be pulled from the
+// pullArgs holds the values of the flags of the pull command.
+var pullArgs struct {
+	// libraryURI holds the base URI to a Sylabs library API instance.
+	libraryURI string
+	// imageName holds the name to be given to the pulled image.
+	imageName string
+	// unauthenticated when true; won't ask to keep a unsigned container after pulling it.
+	unauthenticated bool
+	// dir is the path that the containers will be pulled to, if set.
+	dir string
+	// arch is the architecture for which containers will be pulled from the
 	// SCS library.
-	pullArch string
-)
+	arch string
+	// noVerify when true; skips signature verification of library images entirely.
+	noVerify bool
+	// signKey holds the fingerprint of the PGP key used to sign the pulled image, if set.
+	signKey string
+	// preferCached when true; uses a cached image for the reference without checking the remote.
+	preferCached bool
+	// importAnnotations lists the OCI annotation keys to import as labels.
+	importAnnotations []string
+	// onlyMetadata when true; writes a SIF with the image metadata only.
+	onlyMetadata bool
+	// signature holds the URL or path of a detached signature of an http(s) image.
+	signature string
+	// noXattrs when true; removes extended attributes from files extracted from OCI layers.
+	noXattrs bool
+	// normalizePerms when true; normalizes permissions of files extracted from OCI layers.
+	normalizePerms bool
+	// progressSocket holds the path of a Unix socket to send progress events to, if set.
+	progressSocket string
+	// cacheKeys when true; adds the keys of the signers of a verified library image to the local keyring.
+	cacheKeys bool
+	// postExtractScript holds the path of a script to run in the extracted root filesystem, if set.
+	postExtractScript string
+	// registryTimeouts holds HOST=DURATION connect and read timeouts for oras registries.
+	registryTimeouts []string
+	// verifyReproducible when true; converts a docker/OCI image twice and checks the SIFs are identical.
+	verifyReproducible bool
+	// services holds the path of a Compose-like services file whose service images are pulled, if set.
+	services string
+	// maxLayers holds the maximum number of layers of a docker/OCI image to convert, if non-zero.
+	maxLayers int
+	// attest holds the path to write a signed attestation of the pull to, if set.
+	attest string
+	// attestKey holds the fingerprint of the PGP key used to sign the attestation, if set.
+	attestKey string
+	// socks5 holds the [user[:password]@]host:port address of a SOCKS5 proxy to pull through, if set.
+	socks5 string
+	// warmThenExit when true; exits without pulling if the destination already holds the current image.
+	warmThenExit bool
+	// isJSON when true; reports the --warm-then-exit status, and writes the --emit-layers list, as JSON.
+	isJSON bool
+	// maxRedirects holds the maximum number of redirects followed by a request.
+	maxRedirects int
+	// sync when true; pulls the new or changed tags of a docker repository to --dir.
+	sync bool
+	// prune when true; removes the SIFs of tags removed from the repository on --sync.
+	prune bool
+	// detectOS when true; reports the OS distribution of the image, and records it as labels of converted images.
+	detectOS bool
+	// excludePaths holds glob patterns of the paths removed from docker/OCI images on conversion.
+	excludePaths []string
+	// dnsCacheTTL holds the duration the addresses of hosts are cached for, if set.
+	dnsCacheTTL string
+	// emitLayers holds the path to write the layers of a docker/OCI image to, if set.
+	emitLayers string
+	// requireNonroot when true; fails the pull of a docker/OCI image whose default user is root.
+	requireNonroot bool
+	// keepOnPolicyFail when true; keeps the pulled image when it fails a policy check.
+	keepOnPolicyFail bool
+	// tmpfsWork when true; converts a docker/OCI image in a tmpfs-backed work directory.
+	tmpfsWork bool
+	// tmpfsSize holds the size limit of the tmpfs work directory, if set.
+	tmpfsSize string
+	// dedup when true; stores identical files of a docker/OCI image once, as hardlinks.
+	dedup bool
+	// policyURL holds the URL of the policy endpoint to admit the pulled image, if set.
+	policyURL string
+	// policyFailOpen when true; admits the pulled image if the policy endpoint can't be queried.
+	policyFailOpen bool
+	// toCache when true; pulls the image into the cache only, as run/exec by reference would.
+	toCache bool
+	// existing holds the action to take when the output file already exists.
+	existing string
+	// trace when true; logs the headers of the HTTP requests and responses of the pull.
+	trace bool
+	// exportRootfs holds the path to write the root filesystem of a docker/OCI image to as a tar archive, if set.
+	exportRootfs string
+	// gzip when true; gzip compresses the --export-rootfs archive.
+	gzip bool
+	// setArch holds the architecture to record in the SIF of a docker/OCI image, if set.
+	setArch string
+	// safeSymlinks when true; rewrites the symlinks of a docker/OCI image resolving outside of its root filesystem.
+	safeSymlinks bool
+	// rawSymlinks when true; keeps the symlinks of a docker/OCI image as they are.
+	rawSymlinks bool
+	// allowedRegistries holds the hosts of the only registries images can be pulled from, if set.
+	allowedRegistries []string
+	// metricsRemoteWrite holds the Prometheus remote-write URL to push the metrics of the pull to, if set.
+	metricsRemoteWrite string
+	// alias holds the name of the alias to record for the digest of the pulled image, if set.
+	alias string
+	// checkPolicy when true; checks the capabilities and seccomp profile the image declares against the host.
+	checkPolicy bool
+	// verifyBlobs when true; reads back the blobs of a docker/OCI image downloaded to the cache to verify their digests.
+	verifyBlobs bool
+	// verifyJobs holds the number of cached blobs verified in parallel by verifyBlobs, or 0 for one per CPU.
+	verifyJobs int
+	// fromStdin when true; pulls the images whose references are read from stdin, one per line.
+	fromStdin bool
+	// split holds the size of the chunks the SIF is split into when it is larger, if set.
+	split string
+	// splitAlways when true; splits the SIF into chunks of --split size even when it is smaller.
+	splitAlways bool
+	// maxAge holds the maximum time since the image was created, if set.
+	maxAge string
+	// requireProvenance when true; fails unless a docker image has a trusted SLSA provenance attestation.
+	requireProvenance bool
+	// provenanceKey holds the path to the PEM public key SLSA provenance must be signed with.
+	provenanceKey string
+	// provenanceBuilders holds the builder IDs accepted in SLSA provenance, or any if empty.
+	provenanceBuilders []string
+	// benchmark when true; pulls the image without keeping it, and reports the timings of the pull.
+	benchmark bool
+	// benchmarkRuns holds the number of times the image is pulled with --benchmark.
+	benchmarkRuns int
+	// withOverlays holds the URIs of the images embedded, in order, as overlays of the pulled image.
+	withOverlays []string
+}
 
 // --arch
 var pullArchFlag = cmdline.Flag{
 	ID:           "pullArchFlag",
-	Value:        &pullArch,
+	Value:        &pullArgs.arch,
 	DefaultValue: runtime.GOARCH,
 	Name:         "arch",
 	Usage:        "architecture to pull from library",
@@ -67,7 +204,7 @@ var pullArchFlag = cmdline.Flag{
 // --library
 var pullLibraryURIFlag = cmdline.Flag{
 	ID:           "pullLibraryURIFlag",
-	Value:        &pullLibraryURI,
+	Value:        &pullArgs.libraryURI,
 	DefaultValue: "",
 	Name:         "library",
 	Usage:        "download images from the provided library",
@@ -77,7 +214,7 @@ var pullLibraryURIFlag = cmdline.Flag{
 // --name
 var pullNameFlag = cmdline.Flag{
 	ID:           "pullNameFlag",
-	Value:        &pullImageName,
+	Value:        &pullArgs.imageName,
 	DefaultValue: "",
 	Name:         "name",
 	Hidden:       true,
@@ -88,7 +225,7 @@ var pullNameFlag = cmdline.Flag{
 // --dir
 var pullDirFlag = cmdline.Flag{
 	ID:           "pullDirFlag",
-	Value:        &pullDir,
+	Value:        &pullArgs.dir,
 	DefaultValue: "",
 	Name:         "dir",
 	Usage:        "download images to the specific directory",
@@ -108,7 +245,7 @@ var pullDisableCacheFlag = cmdline.Flag{
 // -U|--allow-unsigned
 var pullAllowUnsignedFlag = cmdline.Flag{
 	ID:           "pullAllowUnauthenticatedFlag",
-	Value:        &unauthenticatedPull,
+	Value:        &pullArgs.unauthenticated,
 	DefaultValue: false,
 	Name:         "allow-unsigned",
 	ShortHand:    "U",
@@ -117,10 +254,59 @@ var pullAllowUnsignedFlag = cmdline.Flag{
 	Deprecated:   `pull no longer exits with an error code in case of unsigned image. Now the flag only suppress warning message.`,
 }
 
+// --no-verify
+var pullNoVerifyFlag = cmdline.Flag{
+	ID:           "pullNoVerifyFlag",
+	Value:        &pullArgs.noVerify,
+	DefaultValue: false,
+	Name:         "no-verify",
+	Usage:        "skip signature verification of library images (logged as a warning)",
+	EnvKeys:      []string{"PULL_NO_VERIFY"},
+}
+
+// --prefer-cached
+var pullPreferCachedFlag = cmdline.Flag{
+	ID:           "pullPreferCachedFlag",
+	Value:        &pullArgs.preferCached,
+	DefaultValue: false,
+	Name:         "prefer-cached",
+	Usage:        "use a cached image for the reference if present, without checking for a newer version",
+	EnvKeys:      []string{"PULL_PREFER_CACHED"},
+}
+
+// --signature
+var pullSignatureFlag = cmdline.Flag{
+	ID:           "pullSignatureFlag",
+	Value:        &pullArgs.signature,
+	DefaultValue: "",
+	Name:         "signature",
+	Usage:        "URL or path of a detached PGP signature to verify an http(s) image against",
+	EnvKeys:      []string{"PULL_SIGNATURE"},
+}
+
+// --cache-keys
+var pullCacheKeysFlag = cmdline.Flag{
+	ID:           "pullCacheKeysFlag",
+	Value:        &pullArgs.cacheKeys,
+	DefaultValue: true,
+	Name:         "cache-keys",
+	Usage:        "add the public keys of the signers of a verified library image to the local keyring",
+	EnvKeys:      []string{"PULL_CACHE_KEYS"},
+}
+
+// --json
+var pullJSONFlag = cmdline.Flag{
+	ID:           "pullJSONFlag",
+	Value:        &pullArgs.isJSON,
+	DefaultValue: false,
+	Name:         "json",
+	Usage:        "report the --warm-then-exit status, and write the --emit-layers list, as JSON",
+}
+
 // --allow-unauthenticated
 var pullAllowUnauthenticatedFlag = cmdline.Flag{
 	ID:           "pullAllowUnauthenticatedFlag",
-	Value:        &unauthenticatedPull,
+	Value:        &pullArgs.unauthenticated,
 	DefaultValue: false,
 	Name:         "allow-unauthenticated",
 	ShortHand:    "",
@@ -149,6 +335,64 @@ func init() {
 		cmdManager.RegisterFlagForCmd(&buildNoCleanupFlag, PullCmd)
 		cmdManager.RegisterFlagForCmd(&pullAllowUnsignedFlag, PullCmd)
 		cmdManager.RegisterFlagForCmd(&pullAllowUnauthenticatedFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullNoVerifyFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSignKeyFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullPreferCachedFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullImportAnnotationsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullOnlyMetadataFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSignatureFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullNoXattrsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullNormalizePermsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullProgressSocketFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullCacheKeysFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullPostExtractScriptFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullRegistryTimeoutFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullVerifyReproducibleFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullServicesFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullMaxLayersFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullAttestFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullAttestKeyFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSOCKS5Flag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullWarmThenExitFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullJSONFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullMaxRedirectsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSyncFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullPruneFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullDetectOSFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullExcludePathFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullDNSCacheTTLFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullEmitLayersFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullRequireNonrootFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullKeepOnPolicyFailFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullTmpfsWorkFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullTmpfsSizeFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullDedupFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullPolicyURLFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullPolicyFailOpenFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullToCacheFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullExistingFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullTraceFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullExportRootfsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullGzipFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSetArchFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSafeSymlinksFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullRawSymlinksFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullAllowedRegistryFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullMetricsRemoteWriteFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullAliasFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullCheckPolicyFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullVerifyBlobsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullVerifyJobsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullFromStdinFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSplitFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullSplitAlwaysFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullMaxAgeFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullRequireProvenanceFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullProvenanceKeyFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullProvenanceBuilderFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullBenchmarkFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullBenchmarkRunsFlag, PullCmd)
+		cmdManager.RegisterFlagForCmd(&pullWithOverlayFlag, PullCmd)
 		cmdManager.RegisterFlagForCmd(&pullArchFlag, PullCmd)
 	})
 }
@@ -156,130 +400,796 @@ func init() {
 // PullCmd singularity pull
 var PullCmd = &cobra.Command{
 	DisableFlagsInUseLine: true,
-	Args:                  cobra.RangeArgs(1, 2),
+	Args:                  cobra.RangeArgs(0, 2),
 	Run:                   pullRun,
+	ValidArgsFunction:     pullCompletion,
 	Use:                   docs.PullUse,
 	Short:                 docs.PullShort,
 	Long:                  docs.PullLong,
 	Example:               docs.PullExample,
 }
 
+// pullOptions holds the state of a run of the pull command, shared by the
+// pulls it makes.
+type pullOptions struct {
+	cmd      *cobra.Command
+	imgCache *cache.Handle
+	metrics  *pullMetricsRecorder
+	// destTemplate is the template of the destination of an image pulled
+	// without a name, from SINGULARITY_PULL_DEST.
+	destTemplate string
+	// attestKey is the fingerprint of the key signing the attestation.
+	attestKey string
+	// overlays are the sources of the images embedded as overlays.
+	overlays []string
+	// overwrite is set if existing image files are replaced.
+	overwrite bool
+}
+
 func pullRun(cmd *cobra.Command, args []string) {
 	ctx := cmd.Context()
 
+	if len(args) == 0 && pullArgs.services == "" && !pullArgs.fromStdin {
+		sylog.Fatalf("An image URI is required, unless --services or --from-stdin is used")
+	}
+
+	// A post extract script runs as root in the root filesystem, as a %post
+	// section does, so an unprivileged pull is run again under fakeroot.
+	if pullArgs.postExtractScript != "" && os.Getuid() != 0 {
+		sylog.Verbosef("Running pull under fakeroot for --post-extract-script")
+		fakerootExec(args)
+	}
+
+	destTemplate := os.Getenv(pullDestEnv)
+	if destTemplate != "" {
+		if err := uri.ValidateNameTemplate(destTemplate); err != nil {
+			sylog.Fatalf("Invalid %s: %v", pullDestEnv, err)
+		}
+	}
+
+	for _, p := range pullArgs.excludePaths {
+		if err := validateExcludePath(p); err != nil {
+			sylog.Fatalf("Invalid --exclude-path: %v", err)
+		}
+	}
+
+	switch pullArgs.existing {
+	case existingError, existingSkip, existingOverwrite:
+	default:
+		sylog.Fatalf("Invalid --existing %q: must be one of %s, %s or %s", pullArgs.existing, existingError, existingSkip, existingOverwrite)
+	}
+	if pullArgs.existing == existingSkip {
+		if err := checkConflicts(cmd, "--existing skip", existingSkipConflicts); err != nil {
+			sylog.Fatalf("%v", err)
+		}
+	}
+	for _, c := range pullConflicts {
+		if flagSet(cmd, c.flag) {
+			if err := checkConflicts(cmd, "--"+c.flag, c.conflicts); err != nil {
+				sylog.Fatalf("%v", err)
+			}
+		}
+	}
+	if pullArgs.preferCached && disableCache {
+		sylog.Fatalf("Conflicting arguments; --prefer-cached cannot be used with --disable-cache")
+	}
+
+	if pullArgs.prune && !pullArgs.sync {
+		sylog.Fatalf("--prune can only be used with --sync")
+	}
+
+	if pullArgs.maxLayers < 0 {
+		sylog.Fatalf("Invalid --max-layers %d: must not be negative", pullArgs.maxLayers)
+	}
+
+	if pullArgs.policyURL != "" {
+		if u, err := url.Parse(pullArgs.policyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			sylog.Fatalf("Invalid --policy-url %q: must be an http(s) URL", pullArgs.policyURL)
+		}
+	} else if pullArgs.policyFailOpen {
+		sylog.Warningf("--policy-fail-open only applies with --policy-url, ignoring")
+	}
+
+	if pullArgs.tmpfsSize != "" {
+		if n, err := units.RAMInBytes(pullArgs.tmpfsSize); err != nil || n <= 0 {
+			sylog.Fatalf("Invalid --tmpfs-size %q: must be a positive size, e.g. 8G", pullArgs.tmpfsSize)
+		}
+		if !pullArgs.tmpfsWork {
+			sylog.Warningf("--tmpfs-size only applies with --tmpfs-work, ignoring")
+		}
+	}
+
+	if pullArgs.split != "" {
+		if n, err := units.RAMInBytes(pullArgs.split); err != nil || n <= 0 {
+			sylog.Fatalf("Invalid --split %q: must be a positive size, e.g. 4000M", pullArgs.split)
+		}
+	} else if pullArgs.splitAlways {
+		sylog.Fatalf("--split-always requires --split")
+	}
+
+	if pullArgs.maxAge != "" {
+		if _, err := client.ParseAge(pullArgs.maxAge); err != nil {
+			sylog.Fatalf("Invalid --max-age %q: must be a number of days, e.g. 90d, or a duration, e.g. 36h", pullArgs.maxAge)
+		}
+	}
+
+	if pullArgs.signKey != "" {
+		// Fail early, rather than after a potentially long pull.
+		el, err := sypgp.NewHandle("").LoadPrivKeyring()
+		if err != nil {
+			sylog.Fatalf("Could not load private keyring: %v", err)
+		}
+		if _, err := selectEntityByFingerprint(pullArgs.signKey)(el); err != nil {
+			sylog.Fatalf("Cannot sign pulled image: %v", err)
+		}
+	}
+
+	attestKey := pullArgs.attestKey
+	if pullArgs.attest != "" {
+		if attestKey == "" {
+			attestKey = pullArgs.signKey
+		}
+		if attestKey == "" {
+			sylog.Fatalf("--attest requires a signing key, set with --attest-key or --sign-key")
+		}
+		el, err := sypgp.NewHandle("").LoadPrivKeyring()
+		if err != nil {
+			sylog.Fatalf("Could not load private keyring: %v", err)
+		}
+		if _, err := selectEntityByFingerprint(attestKey)(el); err != nil {
+			sylog.Fatalf("Cannot sign attestation: %v", err)
+		}
+	}
+
+	if pullArgs.maxRedirects < 0 {
+		sylog.Fatalf("Invalid --max-redirects %d: must not be negative", pullArgs.maxRedirects)
+	}
+	ctx = client.WithMaxRedirects(ctx, pullArgs.maxRedirects)
+
+	if len(pullArgs.registryTimeouts) > 0 {
+		t, err := client.ParseRegistryTimeouts(pullArgs.registryTimeouts)
+		if err != nil {
+			sylog.Fatalf("Invalid --registry-timeout: %v", err)
+		}
+		ctx = client.WithRegistryTimeouts(ctx, t)
+	}
+
+	if pullArgs.verifyJobs < 0 {
+		sylog.Fatalf("Invalid --verify-jobs %d: must not be negative", pullArgs.verifyJobs)
+	}
+	if pullArgs.verifyJobs > 0 && !pullArgs.verifyBlobs {
+		sylog.Fatalf("--verify-jobs requires --verify-blobs")
+	}
+	if pullArgs.verifyBlobs {
+		ctx = client.WithVerifyJobs(ctx, pullArgs.verifyJobs)
+	}
+
+	overlays := make([]string, 0, len(pullArgs.withOverlays))
+	for _, o := range pullArgs.withOverlays {
+		t, _ := uri.Split(o)
+		if t == "" {
+			t = LibraryProtocol
+			o = "library://" + o
+		}
+		if t != LibraryProtocol && !isOCISource(t) {
+			sylog.Fatalf("Invalid --with-overlay %q: only library and docker/OCI images can be embedded as overlays", o)
+		}
+		if err := checkAllowedRegistry(t, o); err != nil {
+			sylog.Fatalf("%v", err)
+		}
+		overlays = append(overlays, o)
+	}
+
 	imgCache := getCacheHandle(cache.Config{Disable: disableCache})
 	if imgCache == nil {
 		sylog.Fatalf("Failed to create an image cache handle")
 	}
 
+	// Before any connection is made.
+	if pullArgs.trace {
+		setupTrace()
+	}
+	setupDNSCache()
+	setupSOCKS5(ctx)
+
+	// Connected after the checks that exit, so that a summary is always sent.
+	var progress *client.ProgressReporter
+	if pullArgs.progressSocket != "" {
+		var err error
+		progress, err = client.DialProgressSocket(pullArgs.progressSocket)
+		if err != nil {
+			sylog.Fatalf("Could not connect to progress socket: %v", err)
+		}
+		ctx = client.WithProgressReporter(ctx, progress)
+	}
+
+	p := &pullOptions{
+		cmd:          cmd,
+		imgCache:     imgCache,
+		metrics:      newPullMetricsRecorder(imgCache),
+		destTemplate: destTemplate,
+		attestKey:    attestKey,
+		overlays:     overlays,
+		overwrite:    forceOverwrite,
+	}
+
+	var err error
+	switch {
+	case pullArgs.services != "":
+		err = pullServicesFile(ctx, p, args)
+	case pullArgs.fromStdin:
+		err = pullStdinRefs(ctx, p, args)
+	case pullArgs.benchmark:
+		err = benchmarkPull(ctx, p, args)
+	case pullArgs.sync:
+		err = pullSyncRepo(ctx, p, args)
+	case pullArgs.toCache:
+		err = pullURIToCache(ctx, p, args)
+	default:
+		err = pullImage(ctx, p, args)
+	}
+	// The metrics are pushed, and the progress summary sent, when the pull
+	// ends, including on failure.
+	p.metrics.push(ctx, err == nil)
+	if progress != nil {
+		if err := progress.CloseWithError(err); err != nil {
+			sylog.Debugf("While closing progress socket: %v", err)
+		}
+	}
+	if err != nil {
+		sylog.Fatalf("%v", err)
+	}
+}
+
+// pullImage pulls the image given as argument to the destination given as
+// argument, by --name or computed from the source, with the name template
+// p.destTemplate if set.
+func pullImage(ctx context.Context, p *pullOptions, args []string) error {
 	pullFrom := args[len(args)-1]
 	transport, ref := uri.Split(pullFrom)
 	if ref == "" {
-		sylog.Fatalf("Bad URI %s", pullFrom)
+		return fmt.Errorf("bad URI %s", pullFrom)
+	}
+	// A name recorded with --alias pulls the image it was pinned to, when
+	// given as alias://NAME. Without transport, a name is a library image.
+	aliasName := ""
+	switch transport {
+	case AliasProtocol:
+		a, err := lookupAlias(strings.TrimPrefix(ref, "//"))
+		if err != nil {
+			return err
+		}
+		sylog.Infof("Pulling %s, alias of %s", a.URI, a.Name)
+		aliasName, pullFrom = a.Name, a.URI
+		transport, _ = uri.Split(pullFrom)
+	case "":
+		warnAliasCollision(ref)
+	}
+	if pullFrom == StdinSource {
+		if len(args) == 1 && pullArgs.imageName == "" {
+			return fmt.Errorf("an output file must be given when pulling from standard input")
+		}
+		transport = StdinSource
+	}
+	if err := checkAllowedRegistry(transport, pullFrom); err != nil {
+		return err
+	}
+
+	if pullArgs.isJSON && !pullArgs.warmThenExit && pullArgs.emitLayers == "" {
+		sylog.Warningf("--json only applies with --warm-then-exit, --emit-layers or --benchmark, ignoring")
+	}
+
+	if pullArgs.noVerify && transport != LibraryProtocol && transport != "" {
+		sylog.Warningf("--no-verify only applies to library images, ignoring")
 	}
 
-	pullTo := pullImageName
+	if pullArgs.signature != "" && transport != HTTPProtocol && transport != HTTPSProtocol {
+		return fmt.Errorf("--signature only applies to http(s) images")
+	}
+
+	if err := checkSourceFlags(p.cmd, transport); err != nil {
+		return err
+	}
+
+	// containers/image only honors the proxy of the environment, which
+	// --socks5 doesn't change.
+	if pullArgs.socks5 != "" && isOCISource(transport) && transport != StdinSource {
+		return fmt.Errorf("--socks5 is not supported for docker/OCI sources, set HTTPS_PROXY=socks5://HOST:PORT instead")
+	}
+	if ttl, _ := time.ParseDuration(pullArgs.dnsCacheTTL); ttl > 0 && isOCISource(transport) && transport != StdinSource {
+		sylog.Warningf("--dns-cache-ttl does not apply to docker/OCI sources, whose registries are resolved by containers/image")
+	}
+
+	if pullArgs.exportRootfs == "" && pullArgs.gzip {
+		sylog.Warningf("--gzip only applies with --export-rootfs, ignoring")
+	}
+
+	if pullArgs.rawSymlinks && pullArgs.safeSymlinks && p.cmd.Flags().Changed(pullSafeSymlinksFlag.Name) {
+		return fmt.Errorf("conflicting arguments; --safe-symlinks cannot be used with --raw-symlinks")
+	}
+	if !pullArgs.safeSymlinks {
+		pullArgs.rawSymlinks = true
+	}
+
+	if pullArgs.alias != "" {
+		if err := client.CheckAliasName(pullArgs.alias); err != nil {
+			return err
+		}
+		if transport != LibraryProtocol && transport != "" && transport != "docker" {
+			return fmt.Errorf("--alias is only supported for library and docker sources")
+		}
+	}
+
+	if pullArgs.requireProvenance {
+		switch {
+		case transport != "docker":
+			return fmt.Errorf("--require-provenance is only supported for docker sources")
+		case pullArgs.provenanceKey == "":
+			return fmt.Errorf("--require-provenance requires --provenance-key")
+		}
+	} else if pullArgs.provenanceKey != "" || len(pullArgs.provenanceBuilders) > 0 {
+		sylog.Warningf("--provenance-key and --provenance-builder only apply with --require-provenance, ignoring")
+	}
+
+	var postScript string
+	if pullArgs.postExtractScript != "" {
+		b, err := os.ReadFile(pullArgs.postExtractScript)
+		if err != nil {
+			return fmt.Errorf("while reading post extract script: %v", err)
+		}
+		postScript = string(b)
+	}
+
+	if pullArgs.onlyMetadata {
+		switch transport {
+		case LibraryProtocol, "", oci.IsSupported(transport):
+		default:
+			return fmt.Errorf("--only-metadata is only supported for library and docker/OCI sources")
+		}
+	}
+
+	// suggestName is set when the destination is derived from the URI of an
+	// http(s) source, which the filename suggested by the server overrides.
+	suggestName := false
+	pullTo := pullArgs.imageName
 	if pullTo == "" {
 		pullTo = args[0]
 		if len(args) == 1 {
+			fullURI := pullFrom
 			if transport == "" {
-				pullTo = uri.GetName("library://" + pullFrom)
+				fullURI = "library://" + pullFrom
+			}
+			if p.destTemplate != "" {
+				arch, err := templateArch(ctx, p, transport, pullFrom)
+				if err != nil {
+					return fmt.Errorf("while computing destination from %s: %v", pullDestEnv, err)
+				}
+				pullTo, err = uri.ExpandNameTemplate(p.destTemplate, fullURI, arch)
+				if err != nil {
+					return fmt.Errorf("while computing destination from %s: %v", pullDestEnv, err)
+				}
+				sylog.Debugf("Destination computed from %s: %s", pullDestEnv, pullTo)
+			} else if aliasName != "" {
+				pullTo = aliasName + ".sif"
 			} else {
-				pullTo = uri.GetName(pullFrom) // TODO: If not library/shub & no name specified, simply put to cache
+				pullTo = uri.GetName(fullURI) // TODO: If not library/shub & no name specified, simply put to cache
+				suggestName = transport == HTTPProtocol || transport == HTTPSProtocol
 			}
 		}
 	}
 
-	if pullDir != "" {
-		pullTo = filepath.Join(pullDir, pullTo)
+	// A destination computed from the source is always relative.
+	explicitDest := pullArgs.imageName != "" || len(args) == 2
+	pullTo, err := joinPullDir(pullArgs.dir, pullTo, explicitDest)
+	if err != nil {
+		return err
 	}
 
-	_, err := os.Stat(pullTo)
-	if !os.IsNotExist(err) {
-		// image already exists
-		if !forceOverwrite {
-			sylog.Fatalf("Image file already exists: %q - will not overwrite", pullTo)
-		}
-	}
+	p.metrics.begin(pullFrom, transport, pullTo)
 
-	switch transport {
-	case LibraryProtocol, "":
-		ref, err := library.NormalizeLibraryRef(pullFrom)
+	var warm *warmStatus
+	if pullArgs.warmThenExit {
+		warm, err = checkWarm(ctx, p.cmd, transport, pullFrom, pullTo)
 		if err != nil {
-			sylog.Fatalf("Malformed library reference: %v", err)
+			return err
 		}
-
-		if pullLibraryURI != "" && ref.Host != "" {
-			sylog.Fatalf("Conflicting arguments; do not use --library with a library URI containing host name")
+		if warm.Status == warmAlreadyPresent {
+			if recordCreated() {
+				if warm.Created, err = checkCreated(pullTo); err != nil {
+					return err
+				}
+			}
+			if pullArgs.detectOS {
+				osr := detectOS(pullTo)
+				warm.OS = &osr
+			}
+			if pullArgs.emitLayers != "" {
+				if err := emitLayers(ctx, p.cmd, pullFrom); err != nil {
+					return err
+				}
+			}
+			return warm.print()
 		}
+	}
 
-		var libraryURI string
-		if pullLibraryURI != "" {
-			libraryURI = pullLibraryURI
-		} else if ref.Host != "" {
-			// override libraryURI if ref contains host name
-			if noHTTPS {
-				libraryURI = "http://" + ref.Host
-			} else {
-				libraryURI = "https://" + ref.Host
+	// An image found stale by --warm-then-exit is replaced.
+	overwrite := p.overwrite || pullArgs.warmThenExit || pullArgs.existing == existingOverwrite
+	if _, err := os.Stat(pullTo); !os.IsNotExist(err) {
+		// image already exists
+		switch {
+		case overwrite:
+			sylog.Infof("Overwriting existing image file %s", pullTo)
+		case pullArgs.existing == existingSkip:
+			current, source, _, err := isCurrentImage(ctx, p.cmd, transport, pullFrom, pullTo, "--existing skip")
+			if err != nil {
+				return err
 			}
+			if current {
+				sylog.Infof("Skipping pull, %s already holds the current image of %s", pullTo, source)
+				return nil
+			}
+			sylog.Infof("Replacing %s, which does not hold the current image of %s", pullTo, pullFrom)
+		default:
+			return fmt.Errorf("image file already exists: %q - will not overwrite", pullTo)
 		}
+	}
 
-		lc, err := getLibraryClientConfig(libraryURI)
-		if err != nil {
-			sylog.Fatalf("Unable to get library client configuration: %v", err)
+	// resolvedDigest is the digest of the source at the time of the pull,
+	// for the alias, provenance check, attestation and policy check.
+	var resolvedDigest string
+
+	// With --alias, --require-provenance, --attest or --policy-url, the tag
+	// of a library or docker/OCI source is resolved to a digest first, and
+	// the image is pulled by digest, so the digest recorded or checked is
+	// the one of the image pulled even if the tag moves during the pull.
+	source := pullSource(transport, pullFrom)
+	resolve := pullArgs.alias != "" || pullArgs.requireProvenance || pullArgs.attest != "" || pullArgs.policyURL != ""
+	if resolve && (transport == LibraryProtocol || transport == "" || oci.IsSupported(transport) == transport) {
+		if resolvedDigest, pullFrom, err = resolvePinned(ctx, p.cmd, transport, source); err != nil {
+			return err
+		}
+	}
+	if pullArgs.requireProvenance {
+		if err := checkProvenance(ctx, p.cmd, pullFrom, resolvedDigest); err != nil {
+			return err
 		}
-		co, err := getKeyserverClientOpts("", endpoint.KeyserverVerifyOp)
+	}
+
+	switch transport {
+	case LibraryProtocol, "":
+		ref, pullOpts, err := libraryPullOptions(ctx, pullFrom, pullArgs.arch)
 		if err != nil {
-			sylog.Fatalf("Unable to get keyserver client configuration: %v", err)
+			return err
 		}
 
-		_, err = library.PullToFile(ctx, imgCache, pullTo, ref, pullArch, tmpDir, lc, co)
+		if pullArgs.onlyMetadata {
+			md, err := library.PullMetadata(ctx, ref, pullOpts)
+			if err != nil {
+				return fmt.Errorf("while fetching library image metadata: %v", err)
+			}
+			if err := writeMetadataSIF(pullTo, md); err != nil {
+				return err
+			}
+			break
+		}
+
+		_, err = library.PullToFile(ctx, p.imgCache, pullTo, ref, pullOpts)
 		if err != nil && err != library.ErrLibraryPullUnsigned {
-			sylog.Fatalf("While pulling library image: %v", err)
+			return fmt.Errorf("while pulling library image: %v", err)
 		}
 		if err == library.ErrLibraryPullUnsigned {
 			sylog.Warningf("Skipping container verification")
 		}
 	case ShubProtocol:
-		_, err := shub.PullToFile(ctx, imgCache, pullTo, pullFrom, tmpDir, noHTTPS)
+		_, err := shub.PullToFile(ctx, p.imgCache, pullTo, pullFrom, tmpDir, noHTTPS)
 		if err != nil {
-			sylog.Fatalf("While pulling shub image: %v\n", err)
+			return fmt.Errorf("while pulling shub image: %v", err)
 		}
 	case OrasProtocol:
-		ociAuth, err := makeDockerCredentials(cmd)
+		ociAuth, err := makeDockerCredentials(p.cmd)
 		if err != nil {
-			sylog.Fatalf("Unable to make docker oci credentials: %s", err)
+			return fmt.Errorf("unable to make docker oci credentials: %s", err)
 		}
 
-		_, err = oras.PullToFile(ctx, imgCache, pullTo, pullFrom, tmpDir, ociAuth)
+		pullOpts := oras.PullOptions{
+			TmpDir:            tmpDir,
+			OciAuth:           ociAuth,
+			PreferCached:      pullArgs.preferCached,
+			AllowedRegistries: pullArgs.allowedRegistries,
+		}
+
+		_, err = oras.PullToFile(ctx, p.imgCache, pullTo, pullFrom, pullOpts)
 		if err != nil {
-			sylog.Fatalf("While pulling image from oci registry: %v", err)
+			return fmt.Errorf("while pulling image from oci registry: %v", err)
 		}
 	case HTTPProtocol, HTTPSProtocol:
-		_, err := net.PullToFile(ctx, imgCache, pullTo, pullFrom, tmpDir)
+		pullOpts := net.PullOptions{
+			TmpDir:        tmpDir,
+			Signature:     pullArgs.signature,
+			AllowUnsigned: pullArgs.unauthenticated,
+			SuggestedName: suggestName,
+			Overwrite:     overwrite,
+		}
+		if pullArgs.signature != "" {
+			pullOpts.KeyClientOpts, err = getKeyserverClientOpts("", endpoint.KeyserverVerifyOp)
+			if err != nil {
+				return fmt.Errorf("unable to get keyserver client configuration: %v", err)
+			}
+		}
+
+		pullTo, err = net.PullToFile(ctx, p.imgCache, pullTo, pullFrom, pullOpts)
 		if err != nil {
-			sylog.Fatalf("While pulling from image from http(s): %v\n", err)
+			return fmt.Errorf("while pulling from image from http(s): %v", err)
+		}
+		p.metrics.setDest(pullTo)
+	case StdinSource:
+		pullOpts := oci.PullOptions{
+			TmpDir:    tmpDir,
+			NoCleanUp: buildArgs.noCleanUp,
+
+			ImportAnnotations: pullArgs.importAnnotations,
+			NoXattrs:          pullArgs.noXattrs,
+			NormalizePerms:    pullArgs.normalizePerms,
+			PostScript:        postScript,
+			MaxLayers:         pullArgs.maxLayers,
+			DetectOS:          pullArgs.detectOS,
+			RecordCreated:     recordCreated(),
+			ExcludePaths:      pullArgs.excludePaths,
+			Dedup:             pullArgs.dedup,
+			ExportRootfs:      pullArgs.exportRootfs,
+			ExportGzip:        pullArgs.gzip,
+			TmpfsWork:         pullArgs.tmpfsWork,
+			TmpfsSize:         tmpfsSize(),
+			SetArch:           pullArgs.setArch,
+			RawSymlinks:       pullArgs.rawSymlinks,
+			AllowedRegistries: pullArgs.allowedRegistries,
+		}
+
+		_, err := oci.PullStreamToFile(ctx, p.imgCache, pullTo, os.Stdin, pullOpts)
+		if err != nil {
+			return fmt.Errorf("while making image from standard input: %v", err)
 		}
 	case oci.IsSupported(transport):
-		ociAuth, err := makeDockerCredentials(cmd)
+		pullOpts, err := ociPullOptions(p.cmd)
 		if err != nil {
-			sylog.Fatalf("While creating Docker credentials: %v", err)
+			return fmt.Errorf("while creating Docker credentials: %v", err)
 		}
+		pullOpts.PostScript = postScript
 
-		pullOpts := oci.PullOptions{
-			TmpDir:     tmpDir,
-			OciAuth:    ociAuth,
-			DockerHost: dockerHost,
-			NoHTTPS:    noHTTPS,
-			NoCleanUp:  buildArgs.noCleanUp,
+		if pullArgs.onlyMetadata {
+			md, err := oci.PullMetadata(ctx, pullFrom, pullOpts)
+			if err != nil {
+				return fmt.Errorf("while fetching image metadata from oci registry: %v", err)
+			}
+			if err := writeMetadataSIF(pullTo, md); err != nil {
+				return err
+			}
+			break
+		}
+
+		if pullArgs.verifyReproducible {
+			if err := verifyReproducible(ctx, p.imgCache, pullTo, pullFrom, pullOpts); err != nil {
+				return err
+			}
+			break
 		}
 
-		_, err = oci.PullToFile(ctx, imgCache, pullTo, pullFrom, pullOpts)
+		_, err = oci.PullToFile(ctx, p.imgCache, pullTo, pullFrom, pullOpts)
 		if err != nil {
-			sylog.Fatalf("While making image from oci registry: %v", err)
+			return fmt.Errorf("while making image from oci registry: %v", err)
 		}
 	default:
-		sylog.Fatalf("Unsupported transport type: %s", transport)
+		return fmt.Errorf("unsupported transport type: %s", transport)
 	}
+
+	if recordCreated() && !pullArgs.onlyMetadata {
+		created, err := checkCreated(pullTo)
+		if err != nil {
+			return err
+		}
+		if warm != nil {
+			warm.Created = created
+		}
+	}
+
+	if pullArgs.detectOS && !pullArgs.onlyMetadata {
+		osr := detectOS(pullTo)
+		if warm != nil {
+			warm.OS = &osr
+		}
+	}
+
+	if len(p.overlays) > 0 {
+		if err := embedOverlays(ctx, p, pullTo); err != nil {
+			return err
+		}
+	}
+
+	if pullArgs.signKey != "" {
+		if err := signPulledImage(ctx, pullTo, pullArgs.signKey); err != nil {
+			return fmt.Errorf("while signing pulled image: %v", err)
+		}
+	}
+
+	// The policies are checked on the final image, with its overlays and
+	// signatures.
+	if pullArgs.requireNonroot {
+		if err := checkNonroot(pullTo); err != nil {
+			return err
+		}
+	}
+
+	if pullArgs.checkPolicy {
+		if err := checkHostPolicy(pullTo); err != nil {
+			return err
+		}
+	}
+
+	if pullArgs.policyURL != "" {
+		if err := checkPolicy(ctx, pullTo, source, resolvedDigest); err != nil {
+			return err
+		}
+	}
+
+	if pullArgs.emitLayers != "" {
+		if err := emitLayers(ctx, p.cmd, pullFrom); err != nil {
+			return err
+		}
+	}
+
+	if warm != nil {
+		warm.Status = warmPulled
+		if err := warm.print(); err != nil {
+			return err
+		}
+	}
+
+	if pullArgs.attest != "" {
+		if err := attestPull(pullTo, source, resolvedDigest, p.attestKey); err != nil {
+			return fmt.Errorf("while writing attestation: %v", err)
+		}
+	}
+
+	if pullArgs.alias != "" {
+		if err := recordAlias(pullArgs.alias, source, resolvedDigest, pullFrom); err != nil {
+			return err
+		}
+	}
+
+	if pullArgs.split != "" {
+		return splitPulledImage(pullTo, overwrite)
+	}
+	return nil
+}
+
+// resolvePinned returns the digest the tag of the library or docker/OCI
+// image source, of transport, currently refers to, and the URI of the image
+// pinned to that digest. The URI of a docker/OCI source which can't be
+// pinned, such as an archive, is source itself.
+func resolvePinned(ctx context.Context, cmd *cobra.Command, transport, source string) (digest, pinned string, err error) {
+	var md client.Metadata
+	switch transport {
+	case LibraryProtocol, "":
+		ref, pullOpts, err := libraryPullOptions(ctx, source, pullArgs.arch)
+		if err != nil {
+			return "", "", err
+		}
+		if md, err = library.PullMetadata(ctx, ref, pullOpts); err != nil {
+			return "", "", fmt.Errorf("while resolving digest of %s: %v", source, err)
+		}
+	default:
+		pullOpts, err := ociPullOptions(cmd)
+		if err != nil {
+			return "", "", fmt.Errorf("while creating Docker credentials: %v", err)
+		}
+		if md, err = oci.PullMetadata(ctx, source, pullOpts); err != nil {
+			return "", "", fmt.Errorf("while resolving digest of %s: %v", source, err)
+		}
+	}
+
+	pinned = source
+	if transport == LibraryProtocol || transport == "" || transport == "docker" {
+		if pinned, err = client.PinnedURI(source, md.Digest); err != nil {
+			return "", "", err
+		}
+	}
+	sylog.Infof("Resolved %s to %s", source, md.Digest)
+	return md.Digest, pinned, nil
+}
+
+// pullSource returns the URI pullFrom was pulled from, with the library
+// transport it defaults to.
+func pullSource(transport, pullFrom string) string {
+	if transport == "" {
+		return "library://" + pullFrom
+	}
+	return pullFrom
+}
+
+// templateArch returns the architecture substituted for {arch} in the name
+// template of the destination of the pull of pullFrom. It is the architecture
+// set with --set-arch, or that of the image resolved for the platform of the
+// pull for docker/OCI sources, fetching its config when the template uses it.
+// Library images are pulled for --arch.
+func templateArch(ctx context.Context, p *pullOptions, transport, pullFrom string) (string, error) {
+	if !strings.Contains(p.destTemplate, "{arch}") {
+		return "", nil
+	}
+
+	switch transport {
+	case oci.IsSupported(transport):
+		if pullArgs.setArch != "" {
+			return pullArgs.setArch, nil
+		}
+		pullOpts, err := ociPullOptions(p.cmd)
+		if err != nil {
+			return "", fmt.Errorf("while creating Docker credentials: %v", err)
+		}
+		md, err := oci.PullMetadata(ctx, pullFrom, pullOpts)
+		if err != nil {
+			return "", err
+		}
+		return md.Architecture, nil
+	default:
+		return pullArgs.arch, nil
+	}
+}
+
+// joinPullDir returns the path of the destination dest of a pull in the
+// directory dir set by --dir, if any. An absolute destination given by the
+// user is rejected when --dir is set, as it is unclear which was intended.
+func joinPullDir(dir, dest string, explicitDest bool) (string, error) {
+	if dir == "" {
+		return dest, nil
+	}
+	if explicitDest && filepath.IsAbs(dest) {
+		return "", fmt.Errorf("destination %q is an absolute path and cannot be used with --dir %q: "+
+			"give a path relative to --dir, or remove --dir to pull to %q", dest, dir, dest)
+	}
+	return filepath.Join(dir, dest), nil
+}
+
+// libraryPullOptions returns the normalized reference of the library image
+// pullFrom, and the options to pull it for the architecture arch.
+func libraryPullOptions(ctx context.Context, pullFrom, arch string) (*scslibrary.Ref, library.PullOptions, error) {
+	ref, err := library.NormalizeLibraryRef(pullFrom)
+	if err != nil {
+		return nil, library.PullOptions{}, fmt.Errorf("malformed library reference: %v", err)
+	}
+
+	if pullArgs.libraryURI != "" && ref.Host != "" {
+		return nil, library.PullOptions{}, fmt.Errorf("conflicting arguments; do not use --library with a library URI containing host name")
+	}
+
+	var libraryURI string
+	if pullArgs.libraryURI != "" {
+		libraryURI = pullArgs.libraryURI
+	} else if ref.Host != "" {
+		// override libraryURI if ref contains host name
+		if noHTTPS {
+			libraryURI = "http://" + ref.Host
+		} else {
+			libraryURI = "https://" + ref.Host
+		}
+	}
+
+	lc, err := getLibraryClientConfig(libraryURI)
+	if err != nil {
+		return nil, library.PullOptions{}, fmt.Errorf("unable to get library client configuration: %v", err)
+	}
+	lc.HTTPClient = client.HTTPClient(ctx)
+	co, err := getKeyserverClientOpts("", endpoint.KeyserverVerifyOp)
+	if err != nil {
+		return nil, library.PullOptions{}, fmt.Errorf("unable to get keyserver client configuration: %v", err)
+	}
+
+	return ref, library.PullOptions{
+		Architecture:  arch,
+		TmpDir:        tmpDir,
+		LibraryConfig: lc,
+		KeyClientOpts: co,
+		SkipVerify:    pullArgs.noVerify,
+		PreferCached:  pullArgs.preferCached,
+		CacheKeys:     pullArgs.cacheKeys,
+	}, nil
 }
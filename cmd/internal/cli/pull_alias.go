@@ -0,0 +1,82 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/syfs"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --alias
+var pullAliasFlag = cmdline.Flag{
+	ID:           "pullAliasFlag",
+	Value:        &pullArgs.alias,
+	DefaultValue: "",
+	Name:         "alias",
+	Usage:        "record the digest of a library or docker image as an alias with the given name, which 'pull alias://NAME' pulls again",
+}
+
+// lookupAlias returns the alias recorded with --alias under name.
+func lookupAlias(name string) (client.Alias, error) {
+	if err := client.CheckAliasName(name); err != nil {
+		return client.Alias{}, err
+	}
+	aliases, err := client.ReadAliases(syfs.Aliases())
+	if err != nil {
+		return client.Alias{}, fmt.Errorf("while reading aliases: %v", err)
+	}
+	a, ok := aliases[name]
+	if !ok {
+		return client.Alias{}, fmt.Errorf("no alias %s, see 'singularity alias list'", name)
+	}
+	return a, nil
+}
+
+// warnAliasCollision warns that ref, given without transport, is pulled from
+// the library, if an alias of the same name was recorded with --alias.
+func warnAliasCollision(ref string) {
+	if client.CheckAliasName(ref) != nil {
+		return
+	}
+	aliases, err := client.ReadAliases(syfs.Aliases())
+	if err != nil {
+		sylog.Debugf("Could not read aliases: %v", err)
+		return
+	}
+	if _, ok := aliases[ref]; ok {
+		sylog.Warningf("Pulling %s from the library, not the image of the alias %s, which is pulled with %s://%s", ref, ref, AliasProtocol, ref)
+	}
+}
+
+// recordAlias records name as an alias of pinned, the image of source at
+// digest, replacing any alias of the same name.
+func recordAlias(name, source, digest, pinned string) error {
+	path := syfs.Aliases()
+	aliases, err := client.ReadAliases(path)
+	if err != nil {
+		return fmt.Errorf("while reading aliases: %v", err)
+	}
+	if prev, ok := aliases[name]; ok && prev.Digest != digest {
+		sylog.Infof("Alias %s moved from %s to %s", name, prev.Digest, digest)
+	}
+	aliases[name] = client.Alias{
+		Name:    name,
+		Source:  source,
+		Digest:  digest,
+		URI:     pinned,
+		Created: time.Now().UTC(),
+	}
+	if err := client.WriteAliases(path, aliases); err != nil {
+		return fmt.Errorf("while recording alias %s: %v", name, err)
+	}
+	sylog.Infof("Recorded alias %s for %s", name, pinned)
+	return nil
+}
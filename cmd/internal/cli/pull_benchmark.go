@@ -0,0 +1,141 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/util/uri"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --benchmark
+var pullBenchmarkFlag = cmdline.Flag{
+	ID:           "pullBenchmarkFlag",
+	Value:        &pullArgs.benchmark,
+	DefaultValue: false,
+	Name:         "benchmark",
+	Usage:        "pull a library or docker/OCI image without keeping it, and report the timings, throughput and cache hits of the pull, as JSON with --json",
+	EnvKeys:      []string{"PULL_BENCHMARK"},
+}
+
+// --benchmark-runs
+var pullBenchmarkRunsFlag = cmdline.Flag{
+	ID:           "pullBenchmarkRunsFlag",
+	Value:        &pullArgs.benchmarkRuns,
+	DefaultValue: 1,
+	Name:         "benchmark-runs",
+	Usage:        "number of times the image is pulled with --benchmark, reporting the minimum, median and maximum",
+	EnvKeys:      []string{"PULL_BENCHMARK_RUNS"},
+}
+
+// benchmarkReport is the report of pull --benchmark.
+type benchmarkReport struct {
+	Source  string                 `json:"source"`
+	Runs    []client.BenchmarkRun  `json:"runs"`
+	Summary []client.BenchmarkStat `json:"summary"`
+}
+
+// benchmarkPull pulls the library or docker/OCI image given as argument
+// --benchmark-runs times, each time to a temporary file removed after the
+// pull, and reports the timings, throughput and cache hits of the pulls, as
+// a table, or as JSON with --json. Unless --disable-cache is set, the pulls
+// after the first are served from the cache.
+func benchmarkPull(ctx context.Context, p *pullOptions, args []string) error {
+	switch {
+	case len(args) != 1:
+		return fmt.Errorf("--benchmark requires a single image URI, and no destination")
+	case pullArgs.benchmarkRuns < 1:
+		return fmt.Errorf("invalid --benchmark-runs %d: must be at least 1", pullArgs.benchmarkRuns)
+	}
+	if err := checkConflicts(p.cmd, "--benchmark", benchmarkConflicts, "sync"); err != nil {
+		return err
+	}
+
+	source := args[0]
+	transport, _ := uri.Split(source)
+	if transport == "" {
+		transport = LibraryProtocol
+		source = "library://" + source
+	}
+	if err := checkAllowedRegistry(transport, source); err != nil {
+		return err
+	}
+
+	report := benchmarkReport{Source: source}
+	for i := 1; i <= pullArgs.benchmarkRuns; i++ {
+		dir, err := os.MkdirTemp(tmpDir, "pull-benchmark-")
+		if err != nil {
+			return fmt.Errorf("while creating temporary directory: %v", err)
+		}
+		dest := filepath.Join(dir, "image.sif")
+
+		b := client.NewPullBenchmark()
+		runCtx := httptrace.WithClientTrace(ctx, b.ClientTrace())
+		r := client.ProgressReporterFromContext(ctx)
+		if r == nil {
+			r = client.NewProgressReporter()
+			runCtx = client.WithProgressReporter(runCtx, r)
+		}
+		r.Observe(b.Observe)
+
+		sylog.Infof("Benchmark run %d/%d: pulling %s", i, pullArgs.benchmarkRuns, source)
+		hits, misses := p.imgCache.Stats()
+		p.metrics.begin(source, transport, dest)
+		err = pullService(runCtx, p, client.Service{Name: source, Image: source}, dest)
+		p.metrics.end(err == nil)
+		r.Observe(nil)
+		if rmErr := os.RemoveAll(dir); rmErr != nil {
+			sylog.Warningf("Could not remove %s: %v", dir, rmErr)
+		}
+		if err != nil {
+			return fmt.Errorf("while pulling %s: %v", source, err)
+		}
+		runHits, runMisses := p.imgCache.Stats()
+		report.Runs = append(report.Runs, b.Run(runHits-hits, runMisses-misses))
+	}
+	report.Summary = client.SummarizeBenchmark(report.Runs)
+
+	if pullArgs.isJSON {
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("while encoding benchmark report: %v", err)
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tMIN\tMEDIAN\tMAX")
+	for _, s := range report.Summary {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, formatBenchmarkValue(s.Min, s.Unit), formatBenchmarkValue(s.Median, s.Unit), formatBenchmarkValue(s.Max, s.Unit))
+	}
+	return tw.Flush()
+}
+
+// formatBenchmarkValue formats v, in unit, for the --benchmark table.
+func formatBenchmarkValue(v float64, unit string) string {
+	switch unit {
+	case client.UnitSeconds:
+		return time.Duration(v * float64(time.Second)).Round(time.Millisecond).String()
+	case client.UnitBytes:
+		return units.BytesSize(v)
+	case client.UnitBytesPerSecond:
+		return units.BytesSize(v) + "/s"
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sylabs/singularity/internal/pkg/util/uri"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --to-cache
+var pullToCacheFlag = cmdline.Flag{
+	ID:           "pullToCacheFlag",
+	Value:        &pullArgs.toCache,
+	DefaultValue: false,
+	Name:         "to-cache",
+	Usage:        "pull the image into the cache only, where run/exec/shell of the same URI will find it",
+}
+
+// pullURIToCache pulls the image URI given as argument into the cache only,
+// as run/exec/shell of the same URI would, so that they find it there without
+// fetching it again.
+func pullURIToCache(ctx context.Context, p *pullOptions, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("--to-cache requires a single image URI, and no destination")
+	}
+	if p.imgCache.IsDisabled() {
+		return fmt.Errorf("conflicting arguments; --to-cache cannot be used with the cache disabled")
+	}
+	if err := checkConflicts(p.cmd, "--to-cache", toCacheConflicts); err != nil {
+		return err
+	}
+
+	src := args[0]
+	if src == StdinSource {
+		return fmt.Errorf("--to-cache cannot be used with standard input, as it has no URI to run by")
+	}
+	transport, ref := uri.Split(src)
+	if ref == "" {
+		return fmt.Errorf("bad URI %s", src)
+	}
+	if transport == "" {
+		transport = LibraryProtocol
+		src = "library://" + src
+	}
+	if err := checkAllowedRegistry(transport, src); err != nil {
+		return err
+	}
+
+	p.metrics.begin(src, transport, "")
+	path, err := handleURI(ctx, p.imgCache, p.cmd, transport, src)
+	if err != nil {
+		return fmt.Errorf("while pulling %s to cache: %v", src, err)
+	}
+	p.metrics.setDest(path)
+	sylog.Infof("Cached %s as %s", src, path)
+	sylog.Infof("Run it with: singularity run %s", src)
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/client/library"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/internal/pkg/util/uri"
+)
+
+// pullCompletionTimeout bounds the time spent listing the tags of a
+// repository for shell completion.
+const pullCompletionTimeout = 3 * time.Second
+
+// pullCompletion completes the tag of a library, docker or oras image
+// reference, e.g. docker://alpine:3.<TAB>, with the tags of the repository,
+// cached briefly. Other arguments are completed as files. Any error listing
+// the tags, or a registry not allowed, gives no suggestions.
+func pullCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 2 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	transport, ref := uri.Split(toComplete)
+	if transport == "" {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	repo, prefix, ok := client.SplitTagPrefix(toComplete)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pullCompletionTimeout)
+	defer cancel()
+
+	var listFrom string
+	var listTags func() ([]string, error)
+	switch transport {
+	case LibraryProtocol:
+		libRef, opts, err := libraryPullOptions(ctx, repo, pullArgs.arch)
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("While configuring the library: %v", err), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		// The same repository may be in different libraries.
+		listFrom = opts.LibraryConfig.BaseURL + " " + repo
+		listTags = func() ([]string, error) {
+			return library.ListTags(ctx, libRef, opts)
+		}
+	case "docker", OrasProtocol:
+		if err := checkAllowedRegistry(transport, repo); err != nil {
+			cobra.CompDebugln(fmt.Sprintf("Not listing tags of %s: %v", repo, err), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		listFrom = repo
+		if transport == OrasProtocol {
+			// An oras repository is listed as any other OCI registry.
+			listFrom = "docker:" + strings.TrimSuffix(ref, ":"+prefix)
+		}
+		listTags = func() ([]string, error) {
+			return oci.ListTags(ctx, listFrom, oci.PullOptions{
+				NoHTTPS:           noHTTPS,
+				AllowedRegistries: pullArgs.allowedRegistries,
+			})
+		}
+	default:
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var cacheDir string
+	if h, err := cache.New(cache.Config{ParentDir: os.Getenv(cache.DirEnv)}); err == nil {
+		cacheDir = h.GetCompletionCacheDir()
+	}
+
+	tags, ok := client.CachedTags(cacheDir, listFrom, client.TagCompletionTTL)
+	if cacheDir == "" || !ok {
+		var err error
+		tags, err = listTags()
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("While listing tags of %s: %v", listFrom, err), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if cacheDir != "" {
+			if err := client.CacheTags(cacheDir, listFrom, tags); err != nil {
+				cobra.CompDebugln(fmt.Sprintf("While caching tags of %s: %v", listFrom, err), false)
+			}
+		}
+	}
+
+	return client.CompleteTags(repo, tags, prefix), cobra.ShellCompDirectiveNoFileComp
+}
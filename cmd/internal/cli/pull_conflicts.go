@@ -0,0 +1,124 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+)
+
+// toCacheConflicts are the flags that alter the image pulled, or apply to a
+// pulled file, which can't be used with --to-cache, as the image is cached as
+// run/exec/shell would cache it.
+var toCacheConflicts = []string{
+	"arch", "name", "dir", "sign-key", "prefer-cached", "import-annotations", "only-metadata",
+	"signature", "no-xattrs", "normalize-perms", "post-extract-script", "verify-reproducible",
+	"max-layers", "attest", "warm-then-exit", "exclude-path", "emit-layers", "require-nonroot",
+	"tmpfs-work", "dedup", "policy-url", "export-rootfs", "set-arch", "alias", "check-policy",
+	"split", "split-always", "max-age", "require-provenance", "provenance-key", "provenance-builder",
+	"benchmark", "benchmark-runs", "with-overlay",
+}
+
+// batchConflicts are the flags that apply to the pull of a single image to a
+// file, which can't be used by the modes pulling many images: --services,
+// --from-stdin and --sync.
+var batchConflicts = []string{
+	"name", "to-cache", "export-rootfs", "only-metadata", "verify-reproducible", "post-extract-script",
+	"attest", "warm-then-exit", "emit-layers", "alias", "split", "max-age", "require-provenance",
+	"with-overlay",
+}
+
+// benchmarkConflicts are the flags that apply to the pulled file, which
+// --benchmark removes after each run.
+var benchmarkConflicts = []string{
+	"name", "to-cache", "only-metadata", "warm-then-exit", "alias", "with-overlay",
+}
+
+// existingSkipConflicts are the flags that can't be used with --existing
+// skip, as the existing file would never be the image they would pull.
+var existingSkipConflicts = []string{
+	// A signed image never matches the image it was pulled as, and an image
+	// with overlays is not the image of its source.
+	"force", "warm-then-exit", "only-metadata", "sign-key", "post-extract-script", "export-rootfs",
+	"with-overlay",
+}
+
+// pullConflicts are the flags that can't be used together in the pull of a
+// single image, each with the flags it conflicts with.
+var pullConflicts = []struct {
+	flag      string
+	conflicts []string
+}{
+	// No image is pulled with --only-metadata.
+	{"only-metadata", []string{
+		"require-nonroot", "check-policy", "export-rootfs", "post-extract-script", "verify-reproducible",
+		"alias", "with-overlay", "warm-then-exit",
+	}},
+	// The image is converted twice with --verify-reproducible.
+	{"verify-reproducible", []string{"export-rootfs", "alias", "with-overlay", "warm-then-exit"}},
+	// An image left in place by --warm-then-exit is not pulled again, and a
+	// signed image never matches the image it was pulled as.
+	{"warm-then-exit", []string{
+		"export-rootfs", "alias", "with-overlay", "require-provenance", "sign-key", "post-extract-script",
+	}},
+}
+
+// ociSourceFlags are the flags that only apply to docker/OCI sources,
+// including an archive read from standard input, whose images are converted
+// to SIF on pull. The architecture of a library image can't be changed
+// without invalidating its signatures.
+var ociSourceFlags = []string{
+	"require-nonroot", "export-rootfs", "dedup", "tmpfs-work", "exclude-path", "set-arch", "post-extract-script",
+	"safe-symlinks", "raw-symlinks",
+}
+
+// ociRegistryFlags are the flags that only apply to docker/OCI sources other
+// than standard input, as the image is fetched again.
+var ociRegistryFlags = []string{"emit-layers", "verify-reproducible", "verify-blobs"}
+
+// isOCISource reports whether transport is a docker/OCI transport, or
+// standard input, whose images are converted to SIF on pull.
+func isOCISource(transport string) bool {
+	return transport == StdinSource || (transport != "" && oci.IsSupported(transport) == transport)
+}
+
+// flagSet reports whether the flag name of cmd is set, on the command line or
+// in the environment, to a value other than its default.
+func flagSet(cmd *cobra.Command, name string) bool {
+	f := cmd.Flags().Lookup(name)
+	return f != nil && f.Changed && f.Value.String() != f.DefValue
+}
+
+// checkConflicts returns an error if any of the flags conflicts, or more, is
+// set, as they can't be used with option.
+func checkConflicts(cmd *cobra.Command, option string, conflicts []string, more ...string) error {
+	for _, list := range [][]string{conflicts, more} {
+		for _, name := range list {
+			if flagSet(cmd, name) {
+				return fmt.Errorf("conflicting arguments; %s cannot be used with --%s", option, name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkSourceFlags returns an error if a flag only applying to docker/OCI
+// sources is set for a source of transport.
+func checkSourceFlags(cmd *cobra.Command, transport string) error {
+	for _, name := range ociSourceFlags {
+		if flagSet(cmd, name) && !isOCISource(transport) {
+			return fmt.Errorf("--%s is only supported for docker/OCI sources", name)
+		}
+	}
+	for _, name := range ociRegistryFlags {
+		if flagSet(cmd, name) && (!isOCISource(transport) || transport == StdinSource) {
+			return fmt.Errorf("--%s is only supported for docker/OCI sources", name)
+		}
+	}
+	return nil
+}
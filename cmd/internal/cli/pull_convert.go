@@ -0,0 +1,341 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --import-annotations
+var pullImportAnnotationsFlag = cmdline.Flag{
+	ID:           "pullImportAnnotationsFlag",
+	Value:        &pullArgs.importAnnotations,
+	DefaultValue: []string{},
+	Name:         "import-annotations",
+	Usage:        "OCI manifest and config annotation keys to import as labels ('prefix*' and 'all' are accepted)",
+	EnvKeys:      []string{"PULL_IMPORT_ANNOTATIONS"},
+}
+
+// --no-xattrs
+var pullNoXattrsFlag = cmdline.Flag{
+	ID:           "pullNoXattrsFlag",
+	Value:        &pullArgs.noXattrs,
+	DefaultValue: false,
+	Name:         "no-xattrs",
+	Usage:        "remove extended attributes from files extracted from docker/OCI layers",
+	EnvKeys:      []string{"PULL_NO_XATTRS"},
+}
+
+// --normalize-perms
+var pullNormalizePermsFlag = cmdline.Flag{
+	ID:           "pullNormalizePermsFlag",
+	Value:        &pullArgs.normalizePerms,
+	DefaultValue: false,
+	Name:         "normalize-perms",
+	Usage:        "set files extracted from docker/OCI layers to 0755 (directories, executables) or 0644",
+	EnvKeys:      []string{"PULL_NORMALIZE_PERMS"},
+}
+
+// --post-extract-script
+var pullPostExtractScriptFlag = cmdline.Flag{
+	ID:           "pullPostExtractScriptFlag",
+	Value:        &pullArgs.postExtractScript,
+	DefaultValue: "",
+	Name:         "post-extract-script",
+	Usage:        "run the script at this path in the root filesystem of a docker/OCI image before creating the SIF",
+	EnvKeys:      []string{"PULL_POST_EXTRACT_SCRIPT"},
+}
+
+// --verify-reproducible
+var pullVerifyReproducibleFlag = cmdline.Flag{
+	ID:           "pullVerifyReproducibleFlag",
+	Value:        &pullArgs.verifyReproducible,
+	DefaultValue: false,
+	Name:         "verify-reproducible",
+	Usage:        "convert a docker/OCI image to SIF twice, and fail if the results differ",
+	EnvKeys:      []string{"PULL_VERIFY_REPRODUCIBLE"},
+}
+
+// --max-layers
+var pullMaxLayersFlag = cmdline.Flag{
+	ID:           "pullMaxLayersFlag",
+	Value:        &pullArgs.maxLayers,
+	DefaultValue: 0,
+	Name:         "max-layers",
+	Usage:        "fail before converting a docker/OCI image with more layers than this (0 for unlimited)",
+	EnvKeys:      []string{"PULL_MAX_LAYERS"},
+}
+
+// --detect-os
+var pullDetectOSFlag = cmdline.Flag{
+	ID:           "pullDetectOSFlag",
+	Value:        &pullArgs.detectOS,
+	DefaultValue: false,
+	Name:         "detect-os",
+	Usage:        "report the OS distribution of the image from its os-release file, and record it as labels of docker/OCI images",
+	EnvKeys:      []string{"PULL_DETECT_OS"},
+}
+
+// --exclude-path
+var pullExcludePathFlag = cmdline.Flag{
+	ID:           "pullExcludePathFlag",
+	Value:        &pullArgs.excludePaths,
+	DefaultValue: []string{},
+	Name:         "exclude-path",
+	Usage:        "remove the files and directories matching an absolute path glob from a docker/OCI image on conversion (can be repeated)",
+	EnvKeys:      []string{"PULL_EXCLUDE_PATH"},
+}
+
+// --emit-layers
+var pullEmitLayersFlag = cmdline.Flag{
+	ID:           "pullEmitLayersFlag",
+	Value:        &pullArgs.emitLayers,
+	DefaultValue: "",
+	Name:         "emit-layers",
+	Usage:        "write the digests and media types of the layers of a docker/OCI image to a file, as JSON with --json",
+}
+
+// --tmpfs-work
+var pullTmpfsWorkFlag = cmdline.Flag{
+	ID:           "pullTmpfsWorkFlag",
+	Value:        &pullArgs.tmpfsWork,
+	DefaultValue: false,
+	Name:         "tmpfs-work",
+	Usage:        "extract and convert a docker/OCI image in a tmpfs-backed work directory, falling back to disk if there is not enough memory",
+	EnvKeys:      []string{"PULL_TMPFS_WORK"},
+}
+
+// --tmpfs-size
+var pullTmpfsSizeFlag = cmdline.Flag{
+	ID:           "pullTmpfsSizeFlag",
+	Value:        &pullArgs.tmpfsSize,
+	DefaultValue: "",
+	Name:         "tmpfs-size",
+	Usage:        "size limit of the --tmpfs-work directory, e.g. 8G (default half of the available memory)",
+	EnvKeys:      []string{"PULL_TMPFS_SIZE"},
+}
+
+// --dedup
+var pullDedupFlag = cmdline.Flag{
+	ID:           "pullDedupFlag",
+	Value:        &pullArgs.dedup,
+	DefaultValue: false,
+	Name:         "dedup",
+	Usage:        "store files of a docker/OCI image with identical content and metadata as hardlinks to a single file",
+	EnvKeys:      []string{"PULL_DEDUP"},
+}
+
+// --export-rootfs
+var pullExportRootfsFlag = cmdline.Flag{
+	ID:           "pullExportRootfsFlag",
+	Value:        &pullArgs.exportRootfs,
+	DefaultValue: "",
+	Name:         "export-rootfs",
+	Usage:        "also write the root filesystem of a docker/OCI image to a tar archive at the given path",
+	EnvKeys:      []string{"PULL_EXPORT_ROOTFS"},
+}
+
+// --gzip
+var pullGzipFlag = cmdline.Flag{
+	ID:           "pullGzipFlag",
+	Value:        &pullArgs.gzip,
+	DefaultValue: false,
+	Name:         "gzip",
+	Usage:        "gzip compress the --export-rootfs archive",
+	EnvKeys:      []string{"PULL_GZIP"},
+}
+
+// --set-arch
+var pullSetArchFlag = cmdline.Flag{
+	ID:           "pullSetArchFlag",
+	Value:        &pullArgs.setArch,
+	DefaultValue: "",
+	Name:         "set-arch",
+	Usage:        "architecture to record in the SIF of a docker/OCI image, checked against its executables",
+	EnvKeys:      []string{"PULL_SET_ARCH"},
+}
+
+// --safe-symlinks
+var pullSafeSymlinksFlag = cmdline.Flag{
+	ID:           "pullSafeSymlinksFlag",
+	Value:        &pullArgs.safeSymlinks,
+	DefaultValue: true,
+	Name:         "safe-symlinks",
+	Usage:        "rewrite symlinks of a docker/OCI image resolving outside of its root filesystem when followed from the host",
+	EnvKeys:      []string{"PULL_SAFE_SYMLINKS"},
+}
+
+// --raw-symlinks
+var pullRawSymlinksFlag = cmdline.Flag{
+	ID:           "pullRawSymlinksFlag",
+	Value:        &pullArgs.rawSymlinks,
+	DefaultValue: false,
+	Name:         "raw-symlinks",
+	Usage:        "keep the symlinks of a docker/OCI image as they are in the image (disables --safe-symlinks)",
+	EnvKeys:      []string{"PULL_RAW_SYMLINKS"},
+}
+
+// --verify-blobs
+var pullVerifyBlobsFlag = cmdline.Flag{
+	ID:           "pullVerifyBlobsFlag",
+	Value:        &pullArgs.verifyBlobs,
+	DefaultValue: false,
+	Name:         "verify-blobs",
+	Usage:        "read back the blobs of a docker/OCI image downloaded to the cache to verify their digests again",
+	EnvKeys:      []string{"PULL_VERIFY_BLOBS"},
+}
+
+// --verify-jobs
+var pullVerifyJobsFlag = cmdline.Flag{
+	ID:           "pullVerifyJobsFlag",
+	Value:        &pullArgs.verifyJobs,
+	DefaultValue: 0,
+	Name:         "verify-jobs",
+	Usage:        "number of blobs verified in parallel by --verify-blobs (0 for one per CPU)",
+	EnvKeys:      []string{"PULL_VERIFY_JOBS"},
+}
+
+// ociPullOptions returns the options to pull a docker/OCI image from a
+// registry, set by the flags of cmd.
+func ociPullOptions(cmd *cobra.Command) (oci.PullOptions, error) {
+	ociAuth, err := makeDockerCredentials(cmd)
+	if err != nil {
+		return oci.PullOptions{}, err
+	}
+
+	return oci.PullOptions{
+		TmpDir:     tmpDir,
+		OciAuth:    ociAuth,
+		DockerHost: dockerHost,
+		NoHTTPS:    noHTTPS,
+		NoCleanUp:  buildArgs.noCleanUp,
+
+		PreferCached:      pullArgs.preferCached,
+		ImportAnnotations: pullArgs.importAnnotations,
+		NoXattrs:          pullArgs.noXattrs,
+		NormalizePerms:    pullArgs.normalizePerms,
+		MaxLayers:         pullArgs.maxLayers,
+		DetectOS:          pullArgs.detectOS,
+		RecordCreated:     recordCreated(),
+		ExcludePaths:      pullArgs.excludePaths,
+		Dedup:             pullArgs.dedup,
+		ExportRootfs:      pullArgs.exportRootfs,
+		ExportGzip:        pullArgs.gzip,
+		TmpfsWork:         pullArgs.tmpfsWork,
+		TmpfsSize:         tmpfsSize(),
+		SetArch:           pullArgs.setArch,
+		RawSymlinks:       pullArgs.rawSymlinks,
+		AllowedRegistries: pullArgs.allowedRegistries,
+	}, nil
+}
+
+// tmpfsSize returns the size limit set by --tmpfs-size, validated in pullRun,
+// or 0 if unset.
+func tmpfsSize() int64 {
+	if pullArgs.tmpfsSize == "" {
+		return 0
+	}
+	n, _ := units.RAMInBytes(pullArgs.tmpfsSize)
+	return n
+}
+
+// validateExcludePath checks that p is a valid pattern of absolute paths, as
+// for path.Match, that doesn't match the root directory.
+func validateExcludePath(p string) error {
+	if !strings.HasPrefix(p, "/") {
+		return fmt.Errorf("%q must be an absolute path", p)
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("%q: %v", p, err)
+	}
+	if ok, _ := path.Match(p, "/"); ok {
+		return fmt.Errorf("%q must not match the root directory", p)
+	}
+	return nil
+}
+
+// detectOS returns the OS distribution of the SIF at path, which is reported,
+// or UnknownOS if it can't be read.
+func detectOS(path string) client.OSRelease {
+	osr, err := client.DetectOS(path, tmpDir)
+	if err != nil {
+		sylog.Warningf("Could not detect OS of %s: %v", path, err)
+		osr = client.OSRelease{ID: client.UnknownOS}
+	}
+	sylog.Infof("Image OS: %s", osr)
+	return osr
+}
+
+// emitLayers writes the layers of the docker/OCI image pullFrom to the path
+// set by --emit-layers, as JSON with --json.
+func emitLayers(ctx context.Context, cmd *cobra.Command, pullFrom string) error {
+	pullOpts, err := ociPullOptions(cmd)
+	if err != nil {
+		return fmt.Errorf("while creating Docker credentials: %v", err)
+	}
+	l, err := oci.PullLayers(ctx, pullFrom, pullOpts)
+	if err != nil {
+		return fmt.Errorf("while getting layers: %v", err)
+	}
+	if err := l.WriteFile(pullArgs.emitLayers, pullArgs.isJSON); err != nil {
+		return fmt.Errorf("while writing layers: %v", err)
+	}
+	sylog.Infof("Digests of the %d layers of %s written to %s", len(l.Layers), pullFrom, pullArgs.emitLayers)
+	return nil
+}
+
+// verifyReproducible converts the docker/OCI image pullFrom to SIF twice,
+// placing the result at pullTo if both conversions are identical, and returns
+// an error reporting their differences otherwise.
+func verifyReproducible(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom string, opts oci.PullOptions) error {
+	// mksquashfs 4.4 and later use SOURCE_DATE_EPOCH for all timestamps in
+	// the squashfs image. It also sets the build date of both SIFs.
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		epoch = strconv.FormatInt(time.Now().Unix(), 10)
+		os.Setenv("SOURCE_DATE_EPOCH", epoch)
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %v", epoch, err)
+	}
+	opts.BuildTime = time.Unix(sec, 0)
+
+	diffs, err := oci.PullReproducible(ctx, imgCache, pullTo, pullFrom, opts)
+	if err != nil {
+		return fmt.Errorf("while verifying reproducibility of %s: %v", pullFrom, err)
+	}
+	if len(diffs) == 0 {
+		sylog.Infof("PASS: two conversions of %s are identical", pullFrom)
+		return nil
+	}
+
+	first := int64(-1)
+	for _, d := range diffs {
+		sylog.Errorf("%s", d)
+		if d.Offset >= 0 && (first < 0 || d.Offset < first) {
+			first = d.Offset
+		}
+	}
+	if first >= 0 {
+		return fmt.Errorf("FAIL: two conversions of %s differ, first at offset %d", pullFrom, first)
+	}
+	return fmt.Errorf("FAIL: two conversions of %s differ", pullFrom)
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/client/library"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/internal/pkg/client/oras"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --metrics-remote-write
+var pullMetricsRemoteWriteFlag = cmdline.Flag{
+	ID:           "pullMetricsRemoteWriteFlag",
+	Value:        &pullArgs.metricsRemoteWrite,
+	DefaultValue: "",
+	Name:         "metrics-remote-write",
+	Usage:        "push the metrics of the pull to the given Prometheus remote-write URL",
+	EnvKeys:      []string{"PULL_METRICS_REMOTE_WRITE"},
+}
+
+// pullMetricsRecorder collects the metrics of the pulls made by a run of the
+// pull command, to push them to the --metrics-remote-write endpoint when it
+// ends. Its methods do nothing on a nil recorder, so it can be used whether
+// or not --metrics-remote-write is set.
+type pullMetricsRecorder struct {
+	imgCache *cache.Handle
+	metrics  []client.PullMetrics
+	// current holds the metrics of the pull in progress, if any, started at
+	// start to dest, when the cache had the given hits and misses.
+	current      *client.PullMetrics
+	start        time.Time
+	dest         string
+	hits, misses int64
+}
+
+// newPullMetricsRecorder returns a recorder of the pulls made with imgCache,
+// or nil if --metrics-remote-write is not set.
+func newPullMetricsRecorder(imgCache *cache.Handle) *pullMetricsRecorder {
+	if pullArgs.metricsRemoteWrite == "" {
+		return nil
+	}
+	return &pullMetricsRecorder{imgCache: imgCache}
+}
+
+// begin records the start of the pull of source, of transport, to the file
+// dest, if known.
+func (r *pullMetricsRecorder) begin(source, transport, dest string) {
+	if r == nil {
+		return
+	}
+	if transport == "" {
+		transport = LibraryProtocol
+	}
+	r.current = &client.PullMetrics{Transport: transport, Registry: pullRegistry(transport, source)}
+	r.start = time.Now()
+	r.dest = dest
+	r.hits, r.misses = r.imgCache.Stats()
+}
+
+// setDest sets the file the pull in progress writes to.
+func (r *pullMetricsRecorder) setDest(dest string) {
+	if r == nil {
+		return
+	}
+	r.dest = dest
+}
+
+// end records the end of the pull in progress, if any, and its outcome.
+func (r *pullMetricsRecorder) end(success bool) {
+	if r == nil || r.current == nil {
+		return
+	}
+	m := r.current
+	r.current = nil
+
+	m.Time = time.Now()
+	m.Duration = m.Time.Sub(r.start)
+	m.Success = success
+	hits, misses := r.imgCache.Stats()
+	m.CacheHits, m.CacheMisses = hits-r.hits, misses-r.misses
+	if fi, err := os.Stat(r.dest); success && r.dest != "" && err == nil && fi.Mode().IsRegular() {
+		m.Bytes = fi.Size()
+	}
+	r.metrics = append(r.metrics, *m)
+}
+
+// pullMetricsPushTimeout bounds the time spent pushing the metrics, so that
+// an unreachable endpoint delays the end of the pull, and the report of its
+// failure, by no more than a few seconds.
+const pullMetricsPushTimeout = 5 * time.Second
+
+// push ends the pull in progress, if any, with the outcome success, and
+// pushes the metrics recorded to the --metrics-remote-write endpoint. The
+// pull is not failed if the metrics can't be pushed.
+func (r *pullMetricsRecorder) push(ctx context.Context, success bool) {
+	if r == nil {
+		return
+	}
+	r.end(success)
+	if len(r.metrics) == 0 {
+		return
+	}
+	metrics := r.metrics
+	r.metrics = nil
+
+	instance, err := os.Hostname()
+	if err != nil {
+		sylog.Debugf("Could not get hostname for metrics: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, pullMetricsPushTimeout)
+	defer cancel()
+	// Credentials in the URL are not logged.
+	endpoint := pullArgs.metricsRemoteWrite
+	if u, err := url.Parse(endpoint); err == nil {
+		endpoint = u.Redacted()
+	}
+	err = client.PushRemoteWrite(ctx, pullArgs.metricsRemoteWrite, os.Getenv(pullMetricsTokenEnv), "singularity", instance, metrics)
+	if err != nil {
+		sylog.Warningf("Could not push pull metrics to %s: %v", endpoint, err)
+		return
+	}
+	sylog.Debugf("Pushed the metrics of %d pulls to %s", len(metrics), endpoint)
+}
+
+// pullRegistry returns the host of the registry, or library, the image source
+// of transport is pulled from, to label its metrics with, or "" if it has none,
+// or is pulled from the library of the current remote endpoint.
+func pullRegistry(transport, source string) string {
+	var host string
+	switch transport {
+	case "docker":
+		host, _ = oci.RegistryHost(source)
+	case OrasProtocol:
+		host, _ = oras.RegistryHost(source)
+	case LibraryProtocol:
+		if pullArgs.libraryURI != "" {
+			if u, err := url.Parse(pullArgs.libraryURI); err == nil {
+				host = u.Host
+			}
+		} else if ref, err := library.NormalizeLibraryRef(source); err == nil {
+			host = ref.Host
+		}
+	case HTTPProtocol, HTTPSProtocol:
+		if u, err := url.Parse(source); err == nil {
+			host = u.Host
+		}
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+	"golang.org/x/term"
+)
+
+// --progress-socket
+var pullProgressSocketFlag = cmdline.Flag{
+	ID:           "pullProgressSocketFlag",
+	Value:        &pullArgs.progressSocket,
+	DefaultValue: "",
+	Name:         "progress-socket",
+	Usage:        "send progress of the pull as JSON lines to the Unix socket at this path",
+	EnvKeys:      []string{"PULL_PROGRESS_SOCKET"},
+}
+
+// --registry-timeout
+var pullRegistryTimeoutFlag = cmdline.Flag{
+	ID:           "pullRegistryTimeoutFlag",
+	Value:        &pullArgs.registryTimeouts,
+	DefaultValue: []string{},
+	Name:         "registry-timeout",
+	Usage:        "connect and read timeout for a docker or oras registry as HOST=DURATION, or DURATION for all registries (can be repeated)",
+	EnvKeys:      []string{"PULL_REGISTRY_TIMEOUT"},
+}
+
+// --socks5
+var pullSOCKS5Flag = cmdline.Flag{
+	ID:           "pullSOCKS5Flag",
+	Value:        &pullArgs.socks5,
+	DefaultValue: "",
+	Name:         "socks5",
+	Usage:        "route connections of library, http(s), oras and shub sources through the SOCKS5 proxy at [user[:password]@]host:port (defaults to a socks5:// ALL_PROXY)",
+	EnvKeys:      []string{"SOCKS5"},
+}
+
+// --max-redirects
+var pullMaxRedirectsFlag = cmdline.Flag{
+	ID:           "pullMaxRedirectsFlag",
+	Value:        &pullArgs.maxRedirects,
+	DefaultValue: client.DefaultMaxRedirects,
+	Name:         "max-redirects",
+	Usage:        "maximum number of redirects followed by a request of the library, http(s) and oras sources",
+	EnvKeys:      []string{"PULL_MAX_REDIRECTS"},
+}
+
+// --dns-cache-ttl
+var pullDNSCacheTTLFlag = cmdline.Flag{
+	ID:           "pullDNSCacheTTLFlag",
+	Value:        &pullArgs.dnsCacheTTL,
+	DefaultValue: "0",
+	Name:         "dns-cache-ttl",
+	Usage:        "cache the addresses of the hosts of library, http(s), oras and shub sources for this duration, e.g. 1m, and reuse more connections (disabled by default)",
+	EnvKeys:      []string{"PULL_DNS_CACHE_TTL"},
+}
+
+// --trace
+var pullTraceFlag = cmdline.Flag{
+	ID:           "pullTraceFlag",
+	Value:        &pullArgs.trace,
+	DefaultValue: false,
+	Name:         "trace",
+	Usage:        "log the headers of HTTP requests and responses, with credentials redacted, at trace level (requests to docker/OCI registries are logged without headers)",
+	EnvKeys:      []string{"PULL_TRACE"},
+}
+
+// setupTrace sets the trace message level, and logs the headers of the HTTP
+// requests and responses of the transports built by singularity at that
+// level. The requests to docker/OCI registries are logged by containers/image
+// itself, at the debug level of logrus, which is set too.
+func setupTrace() {
+	sylog.SetLevel(int(sylog.TraceLevel), !nocolor && term.IsTerminal(2))
+	logrus.SetLevel(logrus.DebugLevel)
+	client.UseTrace()
+	sylog.Tracef("Tracing HTTP requests, with credentials redacted")
+}
+
+// setupDNSCache caches the addresses of the hosts connected to for the
+// duration set by --dns-cache-ttl, if any.
+func setupDNSCache() {
+	ttl, err := time.ParseDuration(pullArgs.dnsCacheTTL)
+	if err != nil || ttl < 0 {
+		sylog.Fatalf("Invalid --dns-cache-ttl %q: must be a non-negative duration, e.g. 1m", pullArgs.dnsCacheTTL)
+	}
+	if ttl == 0 {
+		return
+	}
+	client.UseDNSCache(ttl)
+	sylog.Debugf("Caching the addresses of hosts for %v", ttl)
+}
+
+// setupSOCKS5 routes the connections of the transports built by singularity
+// through the SOCKS5 proxy set by --socks5 or, unless an HTTP(S) proxy is set,
+// by ALL_PROXY, after checking it is reachable.
+func setupSOCKS5(ctx context.Context) {
+	proxy := pullArgs.socks5
+	httpProxy := os.Getenv("HTTPS_PROXY") + os.Getenv("https_proxy") + os.Getenv("HTTP_PROXY") + os.Getenv("http_proxy")
+	if proxy == "" && httpProxy == "" {
+		proxy = client.SOCKS5FromEnvironment()
+	}
+	if proxy == "" {
+		return
+	}
+	if pullArgs.socks5 != "" && httpProxy != "" {
+		sylog.Warningf("--socks5 overrides the HTTP(S) proxy set in the environment")
+	}
+
+	u, err := client.ParseSOCKS5(proxy)
+	if err != nil {
+		sylog.Fatalf("%v", err)
+	}
+	if err := client.CheckSOCKS5(ctx, u); err != nil {
+		sylog.Fatalf("%v", err)
+	}
+	client.UseSOCKS5(u)
+	sylog.Verbosef("Routing connections through SOCKS5 proxy %s", u.Host)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/client/library"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --only-metadata
+var pullOnlyMetadataFlag = cmdline.Flag{
+	ID:           "pullOnlyMetadataFlag",
+	Value:        &pullArgs.onlyMetadata,
+	DefaultValue: false,
+	Name:         "only-metadata",
+	Usage:        "write a SIF holding only the reference, digest, architecture and size of the image, which can't be run",
+	EnvKeys:      []string{"PULL_ONLY_METADATA"},
+}
+
+// --existing
+var pullExistingFlag = cmdline.Flag{
+	ID:           "pullExistingFlag",
+	Value:        &pullArgs.existing,
+	DefaultValue: existingError,
+	Name:         "existing",
+	Usage:        "action when the output file exists: error, skip if it holds the current image, or overwrite",
+	EnvKeys:      []string{"PULL_EXISTING"},
+}
+
+// --split
+var pullSplitFlag = cmdline.Flag{
+	ID:           "pullSplitFlag",
+	Value:        &pullArgs.split,
+	DefaultValue: "",
+	Name:         "split",
+	Usage:        "split the SIF into numbered chunks of at most this size, with a manifest, if it is larger (e.g. 4000M for FAT32)",
+	EnvKeys:      []string{"PULL_SPLIT"},
+}
+
+// --split-always
+var pullSplitAlwaysFlag = cmdline.Flag{
+	ID:           "pullSplitAlwaysFlag",
+	Value:        &pullArgs.splitAlways,
+	DefaultValue: false,
+	Name:         "split-always",
+	Usage:        "split the SIF into chunks with --split even if it is not larger than the chunk size",
+	EnvKeys:      []string{"PULL_SPLIT_ALWAYS"},
+}
+
+// --with-overlay
+var pullWithOverlayFlag = cmdline.Flag{
+	ID:           "pullWithOverlayFlag",
+	Value:        &pullArgs.withOverlays,
+	DefaultValue: []string{},
+	Name:         "with-overlay",
+	Usage:        "URI of a library or docker/OCI image embedded as an overlay of the pulled image, stacked in the given order (can be repeated)",
+	EnvKeys:      []string{"PULL_WITH_OVERLAY"},
+}
+
+const (
+	// existingError, existingSkip and existingOverwrite are the values of
+	// --existing. An existing output file is an error, is kept if it holds
+	// the current image, or is overwritten.
+	existingError     = "error"
+	existingSkip      = "skip"
+	existingOverwrite = "overwrite"
+)
+
+// isCurrentImage reports whether the existing file pullTo holds the current
+// image of pullFrom, which must be a library or docker/OCI source as the
+// option flag requires. The full URI of the source, and the digest of its
+// current image, are also returned.
+func isCurrentImage(ctx context.Context, cmd *cobra.Command, transport, pullFrom, pullTo, flag string) (current bool, source, digest string, err error) {
+	source = pullFrom
+	switch transport {
+	case LibraryProtocol, "":
+		source = "library://" + strings.TrimPrefix(pullFrom, "library://")
+		ref, pullOpts, err := libraryPullOptions(ctx, pullFrom, pullArgs.arch)
+		if err != nil {
+			return false, "", "", err
+		}
+		current, digest, err = library.IsCurrent(ctx, pullTo, ref, pullOpts)
+		if err != nil {
+			return false, "", "", fmt.Errorf("while checking if %s is current: %v", pullTo, err)
+		}
+	case StdinSource:
+		return false, "", "", fmt.Errorf("%s is only supported for library and docker/OCI sources", flag)
+	case oci.IsSupported(transport):
+		pullOpts, err := ociPullOptions(cmd)
+		if err != nil {
+			return false, "", "", fmt.Errorf("while creating Docker credentials: %v", err)
+		}
+		current, digest, err = oci.IsCurrent(ctx, pullTo, pullFrom, pullOpts)
+		if err != nil {
+			return false, "", "", fmt.Errorf("while checking if %s is current: %v", pullTo, err)
+		}
+	default:
+		return false, "", "", fmt.Errorf("%s is only supported for library and docker/OCI sources", flag)
+	}
+	return current, source, digest, nil
+}
+
+// writeMetadataSIF writes a metadata only SIF for md to pullTo.
+func writeMetadataSIF(pullTo string, md client.Metadata) error {
+	if err := client.WriteMetadataSIF(pullTo, md); err != nil {
+		return fmt.Errorf("while writing metadata SIF: %v", err)
+	}
+	sylog.Infof("Wrote metadata of %s (%s) to %s, the image content was not downloaded", md.Source, md.Digest, pullTo)
+	return nil
+}
+
+// splitPulledImage splits the SIF at path into chunks of --split size, with
+// a manifest, if it is larger than that size or --split-always is set. The
+// SIF is replaced by its chunks, which singularity join reassembles.
+func splitPulledImage(path string, overwrite bool) error {
+	size, _ := units.RAMInBytes(pullArgs.split)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("while splitting %s: %v", path, err)
+	}
+	if fi.Size() <= size && !pullArgs.splitAlways {
+		sylog.Debugf("%s is not larger than %s, not splitting", path, pullArgs.split)
+		return nil
+	}
+
+	manifest, err := client.SplitFile(path, size, overwrite)
+	if err != nil {
+		return fmt.Errorf("while splitting %s: %v", path, err)
+	}
+	sylog.Infof("Split %s into chunks of %s, reassemble with: singularity join %s", path, pullArgs.split, manifest)
+	return nil
+}
+
+// embedOverlays pulls the images set with --with-overlay, and embeds them in
+// order into the SIF at pullTo as overlays of its root filesystem, reporting
+// the resulting layer stack.
+func embedOverlays(ctx context.Context, p *pullOptions, pullTo string) error {
+	dir, err := os.MkdirTemp(tmpDir, "pull-overlay-")
+	if err != nil {
+		return fmt.Errorf("while creating temporary directory: %v", err)
+	}
+
+	overlays := make([]client.Overlay, 0, len(p.overlays))
+	for i, source := range p.overlays {
+		dest := filepath.Join(dir, fmt.Sprintf("overlay-%d.sif", i))
+		sylog.Infof("Pulling overlay %s", source)
+		err = pullService(ctx, p, client.Service{Name: source, Image: source}, dest)
+		if err != nil {
+			break
+		}
+		overlays = append(overlays, client.Overlay{Name: source, Path: dest})
+	}
+
+	var stack []client.OverlayLayer
+	if err == nil {
+		stack, err = client.EmbedOverlays(pullTo, overlays)
+	}
+	if rmErr := os.RemoveAll(dir); rmErr != nil {
+		sylog.Warningf("Could not remove %s: %v", dir, rmErr)
+	}
+	if err != nil {
+		return fmt.Errorf("while embedding overlays: %v", err)
+	}
+
+	sylog.Infof("Embedded %d overlays into %s, with layers from the bottom:", len(overlays), pullTo)
+	for i, l := range stack {
+		sylog.Infof("  %d: %s (%s, %s)", i, l.Name, l.FS, units.BytesSize(float64(l.Size)))
+	}
+	return nil
+}
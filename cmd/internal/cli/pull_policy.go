@@ -0,0 +1,357 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/internal/pkg/buildcfg"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/internal/pkg/client/oras"
+	"github.com/sylabs/singularity/internal/pkg/security/seccomp"
+	"github.com/sylabs/singularity/internal/pkg/util/user"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/util/capabilities"
+)
+
+// --allowed-registry
+var pullAllowedRegistryFlag = cmdline.Flag{
+	ID:           "pullAllowedRegistryFlag",
+	Value:        &pullArgs.allowedRegistries,
+	DefaultValue: []string{},
+	Name:         "allowed-registry",
+	Usage:        "only pull docker/OCI and oras images from the registry with the given host, e.g. docker.io (can be repeated)",
+	EnvKeys:      []string{"PULL_ALLOWED_REGISTRY"},
+}
+
+// --require-nonroot
+var pullRequireNonrootFlag = cmdline.Flag{
+	ID:           "pullRequireNonrootFlag",
+	Value:        &pullArgs.requireNonroot,
+	DefaultValue: false,
+	Name:         "require-nonroot",
+	Usage:        "fail if the default user of a docker/OCI image is root",
+	EnvKeys:      []string{"PULL_REQUIRE_NONROOT"},
+}
+
+// --keep-on-policy-fail
+var pullKeepOnPolicyFailFlag = cmdline.Flag{
+	ID:           "pullKeepOnPolicyFailFlag",
+	Value:        &pullArgs.keepOnPolicyFail,
+	DefaultValue: false,
+	Name:         "keep-on-policy-fail",
+	Usage:        "keep the pulled image if it fails a policy check, instead of removing it",
+}
+
+// --policy-url
+var pullPolicyURLFlag = cmdline.Flag{
+	ID:           "pullPolicyURLFlag",
+	Value:        &pullArgs.policyURL,
+	DefaultValue: "",
+	Name:         "policy-url",
+	Usage:        "URL of an Open Policy Agent data API rule deciding whether the pulled image is admitted",
+	EnvKeys:      []string{"PULL_POLICY_URL"},
+}
+
+// --policy-fail-open
+var pullPolicyFailOpenFlag = cmdline.Flag{
+	ID:           "pullPolicyFailOpenFlag",
+	Value:        &pullArgs.policyFailOpen,
+	DefaultValue: false,
+	Name:         "policy-fail-open",
+	Usage:        "admit the pulled image, with a warning, if the --policy-url endpoint can't be queried",
+	EnvKeys:      []string{"PULL_POLICY_FAIL_OPEN"},
+}
+
+// --check-policy
+var pullCheckPolicyFlag = cmdline.Flag{
+	ID:           "pullCheckPolicyFlag",
+	Value:        &pullArgs.checkPolicy,
+	DefaultValue: false,
+	Name:         "check-policy",
+	Usage:        "fail if the image declares capabilities, or a seccomp profile, the host doesn't allow",
+	EnvKeys:      []string{"PULL_CHECK_POLICY"},
+}
+
+// --max-age
+var pullMaxAgeFlag = cmdline.Flag{
+	ID:           "pullMaxAgeFlag",
+	Value:        &pullArgs.maxAge,
+	DefaultValue: "",
+	Name:         "max-age",
+	Usage:        "fail if the image was created longer ago than this, in days (e.g. 90d) or as a duration (e.g. 36h)",
+	EnvKeys:      []string{"PULL_MAX_AGE"},
+}
+
+// --require-provenance
+var pullRequireProvenanceFlag = cmdline.Flag{
+	ID:           "pullRequireProvenanceFlag",
+	Value:        &pullArgs.requireProvenance,
+	DefaultValue: false,
+	Name:         "require-provenance",
+	Usage:        "fail unless a docker image has a SLSA provenance attestation signed with --provenance-key, by an allowed builder",
+	EnvKeys:      []string{"PULL_REQUIRE_PROVENANCE"},
+}
+
+// --provenance-key
+var pullProvenanceKeyFlag = cmdline.Flag{
+	ID:           "pullProvenanceKeyFlag",
+	Value:        &pullArgs.provenanceKey,
+	DefaultValue: "",
+	Name:         "provenance-key",
+	Usage:        "path to the PEM public key SLSA provenance must be signed with, for --require-provenance",
+	EnvKeys:      []string{"PULL_PROVENANCE_KEY"},
+}
+
+// --provenance-builder
+var pullProvenanceBuilderFlag = cmdline.Flag{
+	ID:           "pullProvenanceBuilderFlag",
+	Value:        &pullArgs.provenanceBuilders,
+	DefaultValue: []string{},
+	Name:         "provenance-builder",
+	Usage:        "builder ID accepted in SLSA provenance, a trailing * matching any ID with that prefix (can be repeated, any builder if unset)",
+	EnvKeys:      []string{"PULL_PROVENANCE_BUILDER"},
+}
+
+// checkAllowedRegistry returns an error if the image pullFrom, of transport,
+// is in a registry not allowed by singularity.conf or --allowed-registry, to
+// be checked before any request is made to the registry.
+func checkAllowedRegistry(transport, pullFrom string) error {
+	var host string
+	var err error
+	switch transport {
+	case "docker":
+		host, err = oci.RegistryHost(pullFrom)
+	case OrasProtocol:
+		host, err = oras.RegistryHost(pullFrom)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return client.CheckRegistry(host, pullArgs.allowedRegistries)
+}
+
+// checkNonroot reports the default user of the image at pullTo, from its OCI
+// image config, and fails if it is root, removing the image unless
+// --keep-on-policy-fail is set.
+func checkNonroot(pullTo string) error {
+	user, ok, err := client.ImageUser(pullTo)
+	if err != nil {
+		return policyFail(pullTo, "could not check the default user of the image: %v", err)
+	}
+	if !ok {
+		return policyFail(pullTo, "the image has no OCI image config to check its default user")
+	}
+	if user == "" {
+		sylog.Infof("Image default user: root (no USER set)")
+	} else {
+		sylog.Infof("Image default user: %s", user)
+	}
+	if client.IsRootUser(user) {
+		return policyFail(pullTo, "the image runs as root by default, and --require-nonroot is set")
+	}
+	return nil
+}
+
+// checkHostPolicy checks the capabilities and seccomp profile declared by
+// the OCI image config of the image at pullTo against what the host allows
+// the current user, so that an image that can't run as expected is reported
+// at pull time rather than at run time.
+func checkHostPolicy(pullTo string) error {
+	needs, ok, err := client.ImageNeeds(pullTo)
+	if err != nil {
+		return policyFail(pullTo, "could not check the needs of the image: %v", err)
+	}
+	if !ok {
+		sylog.Infof("The image has no OCI image config declaring capabilities or a seccomp profile to check")
+		return nil
+	}
+	if len(needs.Capabilities) == 0 && len(needs.Unknown) == 0 && needs.Seccomp == "" {
+		sylog.Verbosef("The image declares no capabilities or seccomp profile")
+		return nil
+	}
+
+	policy, err := hostPolicy()
+	if err != nil {
+		return policyFail(pullTo, "could not get the capabilities allowed by the host: %v", err)
+	}
+	if conflicts := needs.Conflicts(policy); len(conflicts) > 0 {
+		return policyFail(pullTo, "the image needs what the host doesn't allow: %s", strings.Join(conflicts, "; "))
+	}
+	sylog.Infof("The capabilities and seccomp profile declared by the image are allowed by the host")
+	return nil
+}
+
+// hostPolicy returns the capabilities the current user can add to a
+// container, from the capability config of the user and their groups, or
+// all capabilities for root, and whether seccomp is supported.
+func hostPolicy() (client.HostPolicy, error) {
+	p := client.HostPolicy{Seccomp: seccomp.Enabled()}
+	if os.Geteuid() == 0 {
+		p.AllCapabilities = true
+		return p, nil
+	}
+
+	f, err := os.Open(buildcfg.CAPABILITY_FILE)
+	if err != nil {
+		return p, fmt.Errorf("while opening capability config file: %v", err)
+	}
+	defer f.Close()
+	capConfig, err := capabilities.ReadFrom(f)
+	if err != nil {
+		return p, fmt.Errorf("while parsing capability config data: %v", err)
+	}
+
+	pw, err := user.Current()
+	if err != nil {
+		return p, err
+	}
+	p.Capabilities = append(p.Capabilities, capConfig.ListUserCaps(pw.Name)...)
+
+	groups, err := os.Getgroups()
+	if err != nil {
+		return p, err
+	}
+	for _, g := range groups {
+		gr, err := user.GetGrGID(uint32(g))
+		if err != nil {
+			sylog.Debugf("Ignoring group %d: %v", g, err)
+			continue
+		}
+		p.Capabilities = append(p.Capabilities, capConfig.ListGroupCaps(gr.Name)...)
+	}
+	return p, nil
+}
+
+// recordCreated reports whether the creation time of the image is needed, to
+// check it against --max-age, or to report it in the --json status of
+// --warm-then-exit.
+func recordCreated() bool {
+	return pullArgs.maxAge != "" || (pullArgs.warmThenExit && pullArgs.isJSON)
+}
+
+// checkCreated reports when the image at pullTo was created, and how long
+// ago, from its created label, and fails if that is longer ago than
+// --max-age. The time is returned, or nil if the image records none, as for
+// images built without a created field, whose age is not checked.
+func checkCreated(pullTo string) (*time.Time, error) {
+	created, ok, err := client.ImageCreated(pullTo)
+	if err != nil {
+		sylog.Warningf("Could not read the creation time of the image: %v", err)
+	}
+	if !ok {
+		if pullArgs.maxAge != "" {
+			sylog.Warningf("The image records no creation time, its age is not checked against --max-age")
+		} else {
+			sylog.Verbosef("The image records no creation time")
+		}
+		return nil, nil
+	}
+
+	age := time.Since(created)
+	sylog.Infof("Image created %s, %s ago", created.UTC().Format(time.RFC3339), client.FormatAge(age))
+	if pullArgs.maxAge != "" {
+		maxAge, _ := client.ParseAge(pullArgs.maxAge)
+		if age > maxAge {
+			return nil, policyFail(pullTo, "the image was created %s ago, over the maximum age of %s", client.FormatAge(age), pullArgs.maxAge)
+		}
+	}
+	return &created, nil
+}
+
+// checkProvenance fails unless the docker image pinned, of digest dgst, has
+// a SLSA provenance attestation signed with --provenance-key, by one of the
+// --provenance-builder builders, before it is pulled, and reports the builder
+// and source of the image.
+func checkProvenance(ctx context.Context, cmd *cobra.Command, pinned, dgst string) error {
+	v, err := signature.LoadVerifierFromPEMFile(pullArgs.provenanceKey, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to load provenance key material: %v", err)
+	}
+	pullOpts, err := ociPullOptions(cmd)
+	if err != nil {
+		return fmt.Errorf("while creating Docker credentials: %v", err)
+	}
+	_, envelopes, err := oci.PullAttestations(ctx, pinned, pullOpts)
+	if err != nil {
+		return fmt.Errorf("while fetching provenance: %v", err)
+	}
+
+	prov, err := client.VerifyProvenance(envelopes, dgst, v, pullArgs.provenanceBuilders)
+	if err != nil {
+		return fmt.Errorf("provenance check of %s failed: %v", dgst, err)
+	}
+	sylog.Infof("Verified SLSA provenance of %s, built by %s", dgst, prov.BuilderID)
+	if prov.SourceRepo != "" {
+		sylog.Infof("Image built from source %s", prov.SourceRepo)
+	}
+	return nil
+}
+
+// checkPolicy queries the --policy-url endpoint with the reference, digest,
+// labels and signers of the final image at pullTo, with its overlays and
+// signatures, and reports its decision. If the image is denied, or the
+// endpoint can't be queried unless --policy-fail-open is set, it fails,
+// removing the image unless --keep-on-policy-fail is set.
+func checkPolicy(ctx context.Context, pullTo, source, digest string) error {
+	in := client.PolicyInput{
+		Reference: source,
+		Digest:    digest,
+	}
+	var err error
+	if in.Labels, err = client.ImageLabels(pullTo); err != nil {
+		sylog.Warningf("Could not read labels for policy check: %v", err)
+	}
+	if in.Signers, err = client.ImageSigners(pullTo); err != nil {
+		sylog.Warningf("Could not read signers for policy check: %v", err)
+	}
+
+	d, err := client.QueryPolicy(ctx, pullArgs.policyURL, in)
+	if err != nil {
+		if pullArgs.policyFailOpen {
+			sylog.Warningf("Could not query policy endpoint, admitting image as --policy-fail-open is set: %v", err)
+			return nil
+		}
+		return policyFail(pullTo, "could not query policy endpoint %s: %v", pullArgs.policyURL, err)
+	}
+
+	decision := "denied"
+	if d.Allow {
+		decision = "allowed"
+	}
+	sylog.Infof("Policy %s %s", decision, source)
+	for _, r := range d.Reasons {
+		sylog.Infof("  %s", r)
+	}
+	if !d.Allow {
+		return policyFail(pullTo, "image denied by policy %s", pullArgs.policyURL)
+	}
+	return nil
+}
+
+// policyFail removes the image at pullTo, unless --keep-on-policy-fail is
+// set, and returns the error given by format and args.
+func policyFail(pullTo, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if pullArgs.keepOnPolicyFail {
+		return fmt.Errorf("policy check failed, keeping %s: %s", pullTo, msg)
+	}
+	if err := os.Remove(pullTo); err != nil {
+		sylog.Errorf("Could not remove %s: %v", pullTo, err)
+	}
+	return fmt.Errorf("policy check failed, removed %s: %s", pullTo, msg)
+}
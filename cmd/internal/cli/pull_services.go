@@ -0,0 +1,124 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/client/library"
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/internal/pkg/util/uri"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --services
+var pullServicesFlag = cmdline.Flag{
+	ID:           "pullServicesFlag",
+	Value:        &pullArgs.services,
+	DefaultValue: "",
+	Name:         "services",
+	Usage:        "pull the image of each service of a Compose-like services file to a SIF named after the service",
+}
+
+// pullServicesFile pulls the image of each service of the services file set
+// by --services to a SIF named after the service, reports the result of each
+// pull, and returns an error if any of them failed.
+func pullServicesFile(ctx context.Context, p *pullOptions, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("conflicting arguments; --services cannot be used with an image URI")
+	}
+	if err := checkConflicts(p.cmd, "--services", batchConflicts, "from-stdin", "sync", "benchmark"); err != nil {
+		return err
+	}
+
+	f, err := os.Open(pullArgs.services)
+	if err != nil {
+		return fmt.Errorf("while opening services file: %v", err)
+	}
+	services, err := client.ReadServices(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("invalid services file %s: %v", pullArgs.services, err)
+	}
+
+	// Check every destination before pulling anything.
+	dests := make([]string, len(services))
+	for i, svc := range services {
+		dest, err := joinPullDir(pullArgs.dir, svc.Name+".sif", false)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) && !p.overwrite {
+			return fmt.Errorf("image file already exists: %q - will not overwrite", dest)
+		}
+		dests[i] = dest
+	}
+
+	failed := 0
+	for i, svc := range services {
+		sylog.Infof("Pulling %s for service %s", svc.Image, svc.Name)
+		transport, _ := uri.Split(svc.Image)
+		p.metrics.begin(svc.Image, transport, dests[i])
+		err := pullService(ctx, p, svc, dests[i])
+		if err == nil && pullArgs.signKey != "" {
+			err = signPulledImage(ctx, dests[i], pullArgs.signKey)
+		}
+		p.metrics.end(err == nil)
+		if err != nil {
+			sylog.Errorf("Service %s: FAILED: %v", svc.Name, err)
+			failed++
+			continue
+		}
+		sylog.Infof("Service %s: pulled %s to %s", svc.Name, svc.Image, dests[i])
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d services failed to pull", failed, len(services))
+	}
+	sylog.Infof("Pulled the images of %d services", len(services))
+	return nil
+}
+
+// pullService pulls the library or docker/OCI image of svc to pullTo, for
+// the platform of svc if set.
+func pullService(ctx context.Context, p *pullOptions, svc client.Service, pullTo string) error {
+	transport, _ := uri.Split(svc.Image)
+	switch transport {
+	case LibraryProtocol:
+		arch := pullArgs.arch
+		if svc.Platform != nil {
+			arch = svc.Platform.Architecture
+		}
+		ref, pullOpts, err := libraryPullOptions(ctx, svc.Image, arch)
+		if err != nil {
+			return err
+		}
+		_, err = library.PullToFile(ctx, p.imgCache, pullTo, ref, pullOpts)
+		if err == library.ErrLibraryPullUnsigned {
+			sylog.Warningf("Skipping container verification")
+			return nil
+		}
+		return err
+	case oci.IsSupported(transport):
+		pullOpts, err := ociPullOptions(p.cmd)
+		if err != nil {
+			return fmt.Errorf("while creating Docker credentials: %v", err)
+		}
+		if svc.Platform != nil {
+			pullOpts.Architecture = svc.Platform.Architecture
+			pullOpts.Variant = svc.Platform.Variant
+		}
+
+		_, err = oci.PullToFile(ctx, p.imgCache, pullTo, svc.Image, pullOpts)
+		return err
+	default:
+		return fmt.Errorf("unsupported transport type %q, only library and docker/OCI images can be pulled with --services, --from-stdin or --benchmark", transport)
+	}
+}
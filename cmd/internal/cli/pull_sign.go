@@ -0,0 +1,103 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sylabs/singularity/internal/app/singularity"
+	"github.com/sylabs/singularity/internal/pkg/buildcfg"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/remote/endpoint"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/sypgp"
+)
+
+// --sign-key
+var pullSignKeyFlag = cmdline.Flag{
+	ID:           "pullSignKeyFlag",
+	Value:        &pullArgs.signKey,
+	DefaultValue: "",
+	Name:         "sign-key",
+	Usage:        "sign the pulled image with the PGP private key with the given fingerprint",
+	EnvKeys:      []string{"PULL_SIGN_KEY"},
+}
+
+// --attest
+var pullAttestFlag = cmdline.Flag{
+	ID:           "pullAttestFlag",
+	Value:        &pullArgs.attest,
+	DefaultValue: "",
+	Name:         "attest",
+	Usage:        "write a signed in-toto attestation of the pull to this path",
+}
+
+// --attest-key
+var pullAttestKeyFlag = cmdline.Flag{
+	ID:           "pullAttestKeyFlag",
+	Value:        &pullArgs.attestKey,
+	DefaultValue: "",
+	Name:         "attest-key",
+	Usage:        "fingerprint of the PGP key signing the --attest attestation (defaults to --sign-key)",
+	EnvKeys:      []string{"PULL_ATTEST_KEY"},
+}
+
+// signPulledImage signs the SIF image at path with the PGP private key with
+// fingerprint fp, prompting for its passphrase if needed, and verifies the
+// resulting signature.
+func signPulledImage(ctx context.Context, path, fp string) error {
+	sylog.Infof("Signing pulled image with key %s", fp)
+
+	f := decryptSelectedEntityInteractive(selectEntityByFingerprint(fp))
+	if err := singularity.Sign(path, singularity.OptSignEntitySelector(f)); err != nil {
+		return fmt.Errorf("failed to sign image: %v", err)
+	}
+
+	co, err := getKeyserverClientOpts("", endpoint.KeyserverVerifyOp)
+	if err != nil {
+		return fmt.Errorf("unable to get keyserver client configuration: %v", err)
+	}
+	if err := singularity.VerifyFingerprints(ctx, path, []string{strings.Join(strings.Fields(fp), "")}, singularity.OptVerifyWithPGP(co...)); err != nil {
+		return fmt.Errorf("failed to verify signature: %v", err)
+	}
+
+	sylog.Infof("Signature created and applied to image '%v'", path)
+	return nil
+}
+
+// attestPull writes a DSSE envelope, holding an in-toto statement that the
+// image at path was pulled from source, signed with the PGP private key with
+// fingerprint fp, to the path set by --attest.
+func attestPull(path, source, digest, fp string) error {
+	st, err := client.NewPullStatement(path, source, digest, buildcfg.PACKAGE_VERSION, time.Now())
+	if err != nil {
+		return err
+	}
+
+	el, err := sypgp.NewHandle("").LoadPrivKeyring()
+	if err != nil {
+		return fmt.Errorf("could not load private keyring: %v", err)
+	}
+	e, err := decryptSelectedEntityInteractive(selectEntityByFingerprint(fp))(el)
+	if err != nil {
+		return err
+	}
+
+	env, err := client.SignStatement(st, e)
+	if err != nil {
+		return err
+	}
+	if err := client.WriteEnvelope(pullArgs.attest, env); err != nil {
+		return err
+	}
+
+	sylog.Infof("Attestation of %s signed with key %s written to %s", path, fp, pullArgs.attest)
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/util/uri"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --from-stdin
+var pullFromStdinFlag = cmdline.Flag{
+	ID:           "pullFromStdinFlag",
+	Value:        &pullArgs.fromStdin,
+	DefaultValue: false,
+	Name:         "from-stdin",
+	Usage:        "pull the library and docker/OCI images whose references are read from standard input, one per line, to --dir",
+	EnvKeys:      []string{"PULL_FROM_STDIN"},
+}
+
+// pullStdinRefs pulls the library and docker/OCI images whose references
+// are read from standard input, one per line, to --dir or the current
+// directory, as each reference is read. The outcome of each pull is reported
+// as it ends, and an error is returned if any of them failed.
+func pullStdinRefs(ctx context.Context, p *pullOptions, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("conflicting arguments; --from-stdin cannot be used with an image URI")
+	}
+	if err := checkConflicts(p.cmd, "--from-stdin", batchConflicts, "sync", "benchmark"); err != nil {
+		return err
+	}
+
+	total, failed := 0, 0
+	err := client.ReadRefs(os.Stdin, func(line int, ref string) error {
+		total++
+		if err := pullStdinRef(ctx, p, ref); err != nil {
+			sylog.Errorf("Line %d: %s: FAILED: %v", line, ref, err)
+			failed++
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("while reading references from standard input, after %d references: %v", total, err)
+	}
+
+	switch {
+	case total == 0:
+		sylog.Warningf("No image references read from standard input")
+	case failed > 0:
+		return fmt.Errorf("%d of %d images failed to pull", failed, total)
+	default:
+		sylog.Infof("Pulled %d images", total)
+	}
+	return nil
+}
+
+// pullStdinRef pulls the library or docker/OCI image ref, read by
+// --from-stdin, to the file named after it in --dir or the current directory.
+// A reference without transport is a library reference, as for a single pull.
+func pullStdinRef(ctx context.Context, p *pullOptions, ref string) error {
+	source := ref
+	transport, _ := uri.Split(source)
+	if transport == "" {
+		transport = LibraryProtocol
+		source = "library://" + source
+	}
+
+	dest, err := joinPullDir(pullArgs.dir, uri.GetName(source), false)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) && !p.overwrite {
+		return fmt.Errorf("image file already exists: %q - will not overwrite", dest)
+	}
+
+	sylog.Infof("Pulling %s", source)
+	p.metrics.begin(source, transport, dest)
+	err = pullService(ctx, p, client.Service{Name: ref, Image: source}, dest)
+	if err == nil && pullArgs.signKey != "" {
+		err = signPulledImage(ctx, dest, pullArgs.signKey)
+	}
+	p.metrics.end(err == nil)
+	if err != nil {
+		return err
+	}
+	sylog.Infof("%s: pulled to %s", source, dest)
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sylabs/singularity/internal/pkg/client/oci"
+	"github.com/sylabs/singularity/internal/pkg/util/uri"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --sync
+var pullSyncFlag = cmdline.Flag{
+	ID:           "pullSyncFlag",
+	Value:        &pullArgs.sync,
+	DefaultValue: false,
+	Name:         "sync",
+	Usage:        "pull the tags of a docker repository that are new or changed since the last sync to --dir",
+}
+
+// --prune
+var pullPruneFlag = cmdline.Flag{
+	ID:           "pullPruneFlag",
+	Value:        &pullArgs.prune,
+	DefaultValue: false,
+	Name:         "prune",
+	Usage:        "with --sync, remove the SIFs of tags no longer in the repository",
+}
+
+// pullSyncRepo pulls the tags of the docker repository given as argument,
+// which are new or changed since the last sync, to --dir or the current
+// directory, reports the outcome for each tag, and returns an error if any of
+// them failed.
+func pullSyncRepo(ctx context.Context, p *pullOptions, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("--sync requires a single docker repository URI")
+	}
+	if err := checkConflicts(p.cmd, "--sync", batchConflicts); err != nil {
+		return err
+	}
+
+	repo := args[0]
+	transport, ref := uri.Split(repo)
+	if transport != "docker" || strings.TrimPrefix(ref, "//") == "" {
+		return fmt.Errorf("--sync requires a docker repository URI, e.g. docker://alpine, got %s", repo)
+	}
+	if strings.Contains(ref, "@") || strings.Contains(filepath.Base(ref), ":") {
+		return fmt.Errorf("--sync requires a repository URI without tag or digest, got %s", repo)
+	}
+	if err := checkAllowedRegistry(transport, repo); err != nil {
+		return err
+	}
+
+	dir := pullArgs.dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("while creating %s: %v", dir, err)
+	}
+
+	pullOpts, err := ociPullOptions(p.cmd)
+	if err != nil {
+		return fmt.Errorf("while creating Docker credentials: %v", err)
+	}
+
+	p.metrics.begin(repo, transport, "")
+	res, err := oci.Sync(ctx, p.imgCache, repo, dir, pullOpts, pullArgs.prune)
+	if err != nil {
+		return fmt.Errorf("while syncing %s: %v", repo, err)
+	}
+
+	report := func(what string, tags []string) {
+		if len(tags) > 0 {
+			sylog.Infof("%s (%d): %s", what, len(tags), strings.Join(tags, ", "))
+		}
+	}
+	report("Added", res.Added)
+	report("Updated", res.Updated)
+	report("Unchanged", res.Unchanged)
+	if pullArgs.prune {
+		report("Removed", res.Removed)
+	} else {
+		report("Removed from repository, kept (use --prune to remove)", res.Removed)
+	}
+
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("%d tags of %s failed to sync: %s", len(res.Failed), repo, strings.Join(res.Failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func Test_joinPullDir(t *testing.T) {
+	tests := []struct {
+		name         string
+		dir          string
+		dest         string
+		explicitDest bool
+		want         string
+		wantError    bool
+	}{
+		{
+			name: "NoDirRelative",
+			dest: "alpine.sif",
+			want: "alpine.sif",
+		},
+		{
+			name:         "NoDirAbsolute",
+			dest:         "/images/alpine.sif",
+			explicitDest: true,
+			want:         "/images/alpine.sif",
+		},
+		{
+			name:         "DirRelative",
+			dir:          "/images",
+			dest:         "alpine.sif",
+			explicitDest: true,
+			want:         "/images/alpine.sif",
+		},
+		{
+			name:         "DirRelativeSubdir",
+			dir:          "images",
+			dest:         "os/alpine.sif",
+			explicitDest: true,
+			want:         "images/os/alpine.sif",
+		},
+		{
+			name:         "DirAbsolute",
+			dir:          "/images",
+			dest:         "/tmp/alpine.sif",
+			explicitDest: true,
+			wantError:    true,
+		},
+		{
+			name:         "RelativeDirAbsolute",
+			dir:          "images",
+			dest:         "/tmp/alpine.sif",
+			explicitDest: true,
+			wantError:    true,
+		},
+		{
+			// A computed destination is joined as before.
+			name: "DirComputedAbsolute",
+			dir:  "/images",
+			dest: "/alpine_latest.sif",
+			want: "/images/alpine_latest.sif",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinPullDir(tt.dir, tt.dest, tt.explicitDest)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("got error %v, want error %v", err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isOCISource(t *testing.T) {
+	tests := []struct {
+		transport string
+		want      bool
+	}{
+		{transport: "", want: false},
+		{transport: LibraryProtocol, want: false},
+		{transport: HTTPSProtocol, want: false},
+		{transport: OrasProtocol, want: false},
+		{transport: StdinSource, want: true},
+		{transport: "docker", want: true},
+		{transport: "docker-archive", want: true},
+		{transport: "oci", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := isOCISource(tt.transport); got != tt.want {
+			t.Errorf("isOCISource(%q) = %v, want %v", tt.transport, got, tt.want)
+		}
+	}
+}
+
+func Test_flagSet(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("name", "", "")
+	cmd.Flags().Bool("dedup", false, "")
+	cmd.Flags().Bool("force", false, "")
+	if err := cmd.Flags().Parse([]string{"--name", "alpine.sif", "--dedup=false"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]bool{"name": true, "dedup": false, "force": false, "unknown": false} {
+		if got := flagSet(cmd, name); got != want {
+			t.Errorf("flagSet(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func Test_templateArch(t *testing.T) {
+	defer func(arch, setArch string) {
+		pullArgs.arch, pullArgs.setArch = arch, setArch
+	}(pullArgs.arch, pullArgs.setArch)
+	pullArgs.arch = "arm64"
+
+	tests := []struct {
+		name      string
+		template  string
+		transport string
+		setArch   string
+		want      string
+	}{
+		{
+			name:      "no placeholder",
+			template:  "{name}.sif",
+			transport: "docker",
+			want:      "",
+		},
+		{
+			name:      "library",
+			template:  "{arch}/{name}.sif",
+			transport: LibraryProtocol,
+			want:      "arm64",
+		},
+		{
+			name:      "set arch",
+			template:  "{arch}/{name}.sif",
+			transport: "docker",
+			setArch:   "ppc64le",
+			want:      "ppc64le",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pullArgs.setArch = tt.setArch
+			p := &pullOptions{destTemplate: tt.template}
+			got, err := templateArch(context.Background(), p, tt.transport, tt.transport+"://alpine")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
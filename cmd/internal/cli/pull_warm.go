@@ -0,0 +1,85 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/cmdline"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// --warm-then-exit
+var pullWarmThenExitFlag = cmdline.Flag{
+	ID:           "pullWarmThenExitFlag",
+	Value:        &pullArgs.warmThenExit,
+	DefaultValue: false,
+	Name:         "warm-then-exit",
+	Usage:        "exit without pulling if the output file already holds the current image, reporting already-present or pulled",
+}
+
+const (
+	// warmAlreadyPresent is the --warm-then-exit status when the output
+	// file already holds the current image.
+	warmAlreadyPresent = "already-present"
+	// warmPulled is the --warm-then-exit status when the image was pulled.
+	warmPulled = "pulled"
+)
+
+// warmStatus is the machine-readable status reported by --warm-then-exit.
+type warmStatus struct {
+	Status string `json:"status"`
+	Source string `json:"source"`
+	Path   string `json:"path"`
+	Digest string `json:"digest,omitempty"`
+	// OS is the OS distribution of the image, with --detect-os.
+	OS *client.OSRelease `json:"os,omitempty"`
+	// Created is when the image was created, if it records it.
+	Created *time.Time `json:"created,omitempty"`
+}
+
+// print writes s to stdout, as JSON with --json, or as "STATUS PATH".
+func (s *warmStatus) print() error {
+	if !pullArgs.isJSON {
+		fmt.Printf("%s %s\n", s.Status, s.Path)
+		return nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("while encoding status: %v", err)
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// checkWarm checks whether the library or docker/OCI image at pullTo is the
+// current image for pullFrom, for --warm-then-exit.
+func checkWarm(ctx context.Context, cmd *cobra.Command, transport, pullFrom, pullTo string) (*warmStatus, error) {
+	s := &warmStatus{Source: pullFrom, Path: pullTo}
+	if _, err := os.Stat(pullTo); os.IsNotExist(err) {
+		sylog.Debugf("%s does not exist, pulling", pullTo)
+		return s, nil
+	}
+
+	current, source, digest, err := isCurrentImage(ctx, cmd, transport, pullFrom, pullTo, "--warm-then-exit")
+	if err != nil {
+		return nil, err
+	}
+	s.Source, s.Digest = source, digest
+	if current {
+		sylog.Infof("%s already holds the current image of %s", pullTo, s.Source)
+		s.Status = warmAlreadyPresent
+	} else {
+		sylog.Infof("%s does not hold the current image of %s, pulling", pullTo, s.Source)
+	}
+	return s, nil
+}
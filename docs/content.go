@@ -45,6 +45,13 @@ Enterprise Performance Computing (EPC)`
   container, and then build it as a default Singularity image for production 
   use. The default format is immutable.
 
+  In a sandbox, absolute symlinks, and symlinks climbing above the root with
+  "..", may point outside of the sandbox when followed from the host. By
+  default (--safe-symlinks), absolute symlinks are rewritten to the equivalent
+  relative symlinks, and symlinks climbing above the root are rewritten to the
+  path they resolve to in a container, where ".." stops at the root, with a
+  warning. Use --raw-symlinks to keep the symlinks as they are in the image.
+
   BUILD SPEC:
 
   The build spec target is a definition (def) file, local image, or URI that can 
@@ -207,6 +214,89 @@ Enterprise Performance Computing (EPC)`
   $ singularity help cache list --type=library,oci
   $ singularity cache list --help`
 
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Cache GC
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	CacheGCUse   string = `gc [gc options...]`
+	CacheGCShort string = `Deduplicate your local Singularity cache`
+	CacheGCLong  string = `
+  This will find files with identical content stored under different types in
+  your local cache (e.g. the same SIF pulled through library and oras, or the
+  same blob), and replace the duplicates with hardlinks to a single copy. The
+  space reclaimed is reported, counting only files of which no other hardlink
+  remains. No cache entry is removed, use 'cache clean' to remove entries. It
+  waits for the pulls writing to the cache to complete, and pulls started
+  while it runs wait for it before writing to the cache.`
+	CacheGCExample string = `
+  $ singularity cache gc
+  $ singularity cache gc --dry-run`
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Alias
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	AliasUse   string = `alias`
+	AliasShort string = `Manage image aliases`
+	AliasLong  string = `
+  Manage the image aliases recorded with 'pull --alias' (stored at
+  $HOME/.singularity/aliases.json). An alias names the digest a tag referred
+  to when it was recorded, and 'singularity pull alias://NAME' pulls that
+  image again.`
+	AliasExample string = `
+  All group commands have their own help output:
+
+  $ singularity help alias list
+  $ singularity alias remove --help`
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Alias list
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	AliasListUse   string = `list`
+	AliasListShort string = `List image aliases`
+	AliasListLong  string = `
+  This will list the image aliases recorded with 'pull --alias', with the
+  source each was pulled from and the digest it is pinned to.`
+	AliasListExample string = `
+  $ singularity alias list`
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// Alias remove
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	AliasRemoveUse   string = `remove <name> [<name>...]`
+	AliasRemoveShort string = `Remove image aliases`
+	AliasRemoveLong  string = `
+  This will remove the given image aliases. The images pulled for them, in
+  files or in the cache, are kept. To point an alias at the current digest of
+  its tag, pull it again with --alias instead.`
+	AliasRemoveExample string = `
+  $ singularity alias remove alpine-prod`
+
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	// join
+	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	JoinUse   string = `join [join options...] <manifest> [<output>]`
+	JoinShort string = `Reassemble a SIF split into chunks by 'pull --split'`
+	JoinLong  string = `
+  The join command reassembles a SIF split into chunks by 'pull --split', from
+  the manifest written next to the chunks, e.g. alpine.sif.manifest.json. The
+  output defaults to the name of the SIF, in the directory of the manifest.
+
+  Each chunk is checked against the size and sha256 digest recorded for it in
+  the manifest, and the reassembled SIF against the size and digest of the
+  original. The output is only written if they all match. The chunks and the
+  manifest are kept.
+
+  The manifest is a JSON object with the name ("file"), size ("size") and
+  sha256 digest ("digest") of the original SIF, the chunk size ("chunkSize"),
+  and the list of chunks ("chunks"), in order, each with its file name
+  ("name"), size and digest. Chunks are named after the SIF with a 3 digit
+  sequence number starting at 001, and every chunk but the last is exactly
+  the chunk size, so that, without singularity, the SIF can also be
+  reassembled by concatenating them in order and checked with sha256sum.`
+	JoinExample string = `
+  $ singularity join alpine.sif.manifest.json
+
+  $ singularity join /media/usb/alpine.sif.manifest.json alpine.sif`
+
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// key
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
@@ -618,7 +708,18 @@ Enterprise Performance Computing (EPC)`
       oras://registry/namespace/image:tag
 
   http, https: Pull an image using the http(s?) protocol
-      https://library.sylabs.io/v1/imagefile/library/default/alpine:latest`
+      https://library.sylabs.io/v1/imagefile/library/default/alpine:latest
+
+  alias: Pull the image an alias recorded with --alias is pinned to.
+      alias://name
+
+  -: Read an OCI or docker save archive, optionally gzip compressed, from
+     standard input. An output file must be given.
+
+  When no output file is given, the name of the image is derived from the URI,
+  or from the template set by SINGULARITY_PULL_DEST, e.g. {arch}/{name}_{tag}.sif
+
+  The options are described in detail in docs/pull.md of the source tree.`
 	PullExample string = `
   From Sylabs cloud library
   $ singularity pull alpine.sif library://alpine:latest
@@ -630,7 +731,91 @@ Enterprise Performance Computing (EPC)`
   $ singularity pull singularity-images.sif shub://vsoch/singularity-images
 
   From supporting OCI registry (e.g. Azure Container Registry)
-  $ singularity pull image.sif oras://<username>.azurecr.io/namespace/image:tag`
+  $ singularity pull image.sif oras://<username>.azurecr.io/namespace/image:tag
+
+  Verify an image against a detached signature
+  $ singularity pull --signature https://example.com/image.sif.sig https://example.com/image.sif
+
+  Add a CA certificate to an image while pulling it
+  $ singularity pull --post-extract-script ./add-ca.sh ubuntu.sif docker://ubuntu
+
+  Pull the images of the services of a compose file to web.sif, worker.sif, ...
+  $ singularity pull --dir images --services compose.yaml
+
+  Pull the images listed by another command
+  $ kubectl get pods -o jsonpath='{..image}' | tr ' ' '\n' | sed 's|^|docker://|' | singularity pull --from-stdin --dir images
+
+  Pull and write a signed attestation of the pull
+  $ singularity pull --attest alpine.att.json --attest-key 8883491F4268F173C6E5DC49EDECE4F3F38D871E alpine.sif docker://alpine
+
+  Mirror the tags of a repository, pulling only those that changed
+  $ singularity pull --dir mirror --sync --prune docker://alpine
+
+  Trim documentation and locales from an image
+  $ singularity pull --exclude-path /usr/share/doc --exclude-path '/usr/share/locale/*' ubuntu.sif docker://ubuntu
+
+  Convert an image in memory, on a host with a slow disk
+  $ singularity pull --tmpfs-work --tmpfs-size 16G tensorflow.sif docker://tensorflow/tensorflow
+
+  Admit an image with an organization policy
+  $ singularity pull --policy-url http://opa:8181/v1/data/singularity/pull/decision alpine.sif docker://alpine
+
+  Pre-stage an image in the cache, to run it later by URI
+  $ singularity pull --to-cache docker://alpine
+  $ singularity exec docker://alpine cat /etc/alpine-release
+
+  Pull an image only if it changed, in an idempotent script
+  $ singularity pull --existing skip alpine.sif docker://alpine
+
+  Trace the HTTP requests of a pull, to diagnose a 401 error
+  $ singularity pull --trace alpine.sif library://alpine
+
+  Pull an image, and export its root filesystem as a compressed tarball
+  $ singularity pull --export-rootfs alpine-rootfs.tar.gz --gzip alpine.sif docker://alpine
+
+  Pull an arm64 image, and check the architecture recorded in the SIF
+  $ singularity pull --arch arm64 alpine-arm64.sif docker://alpine
+  $ singularity inspect --arch alpine-arm64.sif
+
+  Only pull from Docker Hub and GitHub Container Registry
+  $ singularity pull --allowed-registry docker.io --allowed-registry ghcr.io docker://alpine
+
+  Push the metrics of a pull to Prometheus
+  $ singularity pull --metrics-remote-write http://prometheus:9090/api/v1/write docker://alpine
+
+  Pin the current alpine image under a name, and pull it again later
+  $ singularity pull --alias alpine-prod docker://alpine:3
+  $ singularity pull alias://alpine-prod
+
+  Check that the capabilities an image needs are allowed on this host
+  $ singularity pull --check-policy docker://example/netdiag
+
+  Embed a tools overlay, then a configuration overlay above it, into an image
+  $ singularity pull --with-overlay docker://example/tools:1.0 --with-overlay docker://example/site-config app.sif docker://example/app:2.1
+
+  Compare the download times of a mirror over 5 runs, bypassing the cache
+  $ singularity pull --disable-cache --benchmark --benchmark-runs 5 docker://mirror.example.com/library/alpine:3.17
+
+  Only pull an image built by the SLSA GitHub generator, with signed provenance
+  $ singularity pull --require-provenance --provenance-key cosign.pub --provenance-builder 'https://github.com/slsa-framework/slsa-github-generator/*' docker://ghcr.io/example/app:1.0
+
+  Refuse base images built more than 90 days ago
+  $ singularity pull --max-age 90d docker://ubuntu:22.04
+
+  Split a large image into chunks that fit on FAT32 media
+  $ singularity pull --split 4000M tensorflow.sif docker://tensorflow/tensorflow:latest-gpu
+
+  Record the digest of an image, without downloading it
+  $ singularity pull --only-metadata alpine.sif docker://alpine
+
+  From an archive on standard input
+  $ docker save alpine | singularity pull alpine.sif -
+
+  Keep OCI provenance annotations as labels
+  $ singularity pull --import-annotations 'org.opencontainers.image.*' docker://alpine
+
+  Pull and sign with your own key
+  $ singularity pull --sign-key 8883491F4268F173C6E5DC49EDECE4F3F38D871E alpine.sif docker://alpine`
 
 	// ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 	// push
@@ -810,6 +995,9 @@ Enterprise Performance Computing (EPC)`
   Inspect will show you labels, environment variables, apps and scripts associated 
   with the image determined by the flags you pass. By default, they will be shown in 
   plain text. If you would like to list them in json format, you should use the --json flag.
+
+  With --arch, the architecture recorded in a SIF image is shown. It can be
+  shown for an image of an architecture that can't run on the host.
   `
 	InspectExample string = `
   $ singularity inspect ubuntu.sif
@@ -0,0 +1,34 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package singularity
+
+import (
+	"fmt"
+
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/util/fs"
+)
+
+// GarbageCollectSingularityCache deduplicates identical content held under the
+// different cache types, and reports the amount of space reclaimed. If dryRun
+// is true, only report what would be reclaimed.
+func GarbageCollectSingularityCache(imgCache *cache.Handle, dryRun bool) error {
+	if imgCache == nil {
+		return errInvalidCacheHandle
+	}
+
+	res, err := imgCache.GarbageCollect(dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Reclaimed"
+	if dryRun {
+		verb = "Would reclaim"
+	}
+	fmt.Printf("%s %s from %d duplicate cache entries\n", verb, fs.FindSize(res.Reclaimed), res.Duplicates)
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// sifArchs are the architectures a SIF header can record.
+var sifArchs = map[string]bool{
+	"386":      true,
+	"amd64":    true,
+	"arm":      true,
+	"arm64":    true,
+	"ppc64":    true,
+	"ppc64le":  true,
+	"mips":     true,
+	"mipsle":   true,
+	"mips64":   true,
+	"mips64le": true,
+	"s390x":    true,
+	"riscv64":  true,
+}
+
+// sifArch returns the architecture to record in the SIF of a root filesystem
+// whose executables are built for detected, if recognized, and which comes
+// from an image for platform, if known. set, if not empty, overrides the
+// architecture, and must match the executables when they are recognized.
+// When neither the executables nor the platform tell the architecture, the
+// host architecture is used.
+func sifArch(detected, platform, set string) (string, error) {
+	if set != "" {
+		if !sifArchs[set] {
+			return "", fmt.Errorf("architecture %s is not supported by SIF", set)
+		}
+		if detected != "" && detected != set {
+			return "", fmt.Errorf("architecture %s does not match the %s executables of the container", set, detected)
+		}
+		if platform != "" && platform != set {
+			sylog.Warningf("Image platform architecture %s overridden by %s", platform, set)
+		}
+		return set, nil
+	}
+
+	if detected != "" {
+		if platform != "" && platform != detected {
+			sylog.Warningf("Image platform architecture %s does not match the %s executables of the container, use %s", platform, detected, detected)
+		}
+		return detected, nil
+	}
+
+	if sifArchs[platform] {
+		sylog.Infof("Architecture not recognized, use image platform %s", platform)
+		return platform, nil
+	}
+	sylog.Infof("Architecture not recognized, use native")
+	return runtime.GOARCH, nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestSIFArch(t *testing.T) {
+	// A foreign architecture, to check the host architecture is not used
+	// for images of another platform.
+	foreign := "arm64"
+	if runtime.GOARCH == foreign {
+		foreign = "amd64"
+	}
+
+	tests := []struct {
+		name     string
+		detected string
+		platform string
+		set      string
+		want     string
+		wantErr  bool
+	}{
+		{name: "Detected", detected: foreign, want: foreign},
+		{name: "DetectedPlatform", detected: foreign, platform: foreign, want: foreign},
+		{name: "DetectedOverPlatform", detected: foreign, platform: runtime.GOARCH, want: foreign},
+		{name: "Platform", platform: foreign, want: foreign},
+		{name: "UnknownPlatform", platform: "wasm", want: runtime.GOARCH},
+		{name: "Native", want: runtime.GOARCH},
+		{name: "Set", set: foreign, want: foreign},
+		{name: "SetDetected", detected: foreign, set: foreign, want: foreign},
+		{name: "SetOverPlatform", platform: runtime.GOARCH, set: foreign, want: foreign},
+		{name: "SetMismatch", detected: runtime.GOARCH, set: foreign, wantErr: true},
+		{name: "SetUnsupported", set: "wasm", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sifArch(tt.detected, tt.platform, tt.set)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
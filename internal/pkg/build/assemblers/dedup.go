@@ -0,0 +1,195 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+	"golang.org/x/sys/unix"
+)
+
+// dedupKey holds the metadata that files must share to be merged, so that
+// merging them doesn't change what is seen through any of their paths.
+type dedupKey struct {
+	size  int64
+	mode  fs.FileMode
+	uid   uint32
+	gid   uint32
+	mtime int64
+}
+
+// dedupFiles replaces the regular files of rootfs that have the same content,
+// extended attributes, permissions, owner and modification time as another
+// file by hardlinks to it, so that they are stored as a single inode. Empty
+// files, and files that are already hardlinked, are left as they are, so that
+// existing hardlinks are not split. The number of files replaced is returned.
+func dedupFiles(rootfs string) (files int, err error) {
+	groups := make(map[dedupKey][]string)
+	err = filepath.WalkDir(rootfs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok || fi.Size() == 0 || st.Nlink > 1 {
+			return nil
+		}
+		k := dedupKey{
+			size:  fi.Size(),
+			mode:  fi.Mode(),
+			uid:   st.Uid,
+			gid:   st.Gid,
+			mtime: fi.ModTime().UnixNano(),
+		}
+		groups[k] = append(groups[k], p)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("while looking for duplicate files: %v", err)
+	}
+
+	// The links are made in a directory of their own, so that their names
+	// can't clash with files of the image, before replacing the files.
+	tmpDir, err := os.MkdirTemp(rootfs, ".dedup-")
+	if err != nil {
+		return 0, fmt.Errorf("while creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for _, paths := range groups {
+		if len(paths) < 2 {
+			continue
+		}
+		// The first file with each content is kept, in walk order.
+		first := make(map[string]string)
+		for _, p := range paths {
+			sum, err := fileSum(p)
+			if err != nil {
+				return files, err
+			}
+			target, ok := first[sum]
+			if !ok {
+				first[sum] = p
+				continue
+			}
+			tmp := filepath.Join(tmpDir, strconv.Itoa(files))
+			if err := replaceWithLink(target, p, tmp); err != nil {
+				return files, err
+			}
+			sylog.Debugf("Deduplicated %s as a hardlink to %s", p, target)
+			files++
+		}
+	}
+
+	// The squashfs of a SIF already stores the data of identical files once,
+	// so only the inodes and metadata of the duplicates are saved.
+	sylog.Infof("Deduplicated %d files of the image as hardlinks, saving their inodes and metadata", files)
+	return files, nil
+}
+
+// fileSum returns the SHA-256 digest of the content and extended attributes
+// of the file at path.
+func fileSum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("while reading %s: %v", path, err)
+	}
+	xattrs, err := xattrData(path)
+	if err != nil {
+		return "", fmt.Errorf("while reading extended attributes of %s: %v", path, err)
+	}
+	h.Write(xattrs)
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// xattrData returns the extended attributes of path, without following
+// symlinks, as their sorted names and values.
+func xattrData(path string) ([]byte, error) {
+	xattrs, err := readXattrs(path)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(xattrs))
+	for name := range xattrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var data bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&data, "%s=%d:%s", name, len(xattrs[name]), xattrs[name])
+	}
+	return data.Bytes(), nil
+}
+
+// readXattrs returns the extended attributes of path, without following
+// symlinks.
+func readXattrs(path string) (map[string]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err == unix.ENOTSUP {
+		return nil, nil
+	}
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	xattrs := make(map[string]string)
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		size, err := unix.Lgetxattr(path, string(name), nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size)
+		if _, err := unix.Lgetxattr(path, string(name), value); err != nil {
+			return nil, err
+		}
+		xattrs[string(name)] = string(value)
+	}
+	return xattrs, nil
+}
+
+// replaceWithLink atomically replaces the file at path by a hardlink to
+// target, made first at tmp, a path that must not exist.
+func replaceWithLink(target, path, tmp string) error {
+	if err := os.Link(target, tmp); err != nil {
+		return fmt.Errorf("while linking %s to %s: %v", path, target, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("while replacing %s: %v", path, err)
+	}
+	return nil
+}
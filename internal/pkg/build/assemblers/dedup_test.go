@@ -0,0 +1,97 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDedupFiles(t *testing.T) {
+	rootfs := t.TempDir()
+	mtime := time.Unix(1600000000, 0)
+
+	write := func(name, content string, mode os.FileMode) string {
+		p := filepath.Join(rootfs, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(p, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	a := write("vendor/a/lib.js", "same content", 0o644)
+	b := write("vendor/b/lib.js", "same content", 0o644)
+	c := write("vendor/c/lib.js", "same content", 0o644)
+	// A file whose name could clash with a temporary link.
+	clash := write("vendor/b/lib.js.dedup", "keep this", 0o644)
+	// Same content, but different permissions or modification time.
+	exe := write("bin/lib.js", "same content", 0o755)
+	newer := write("vendor/d/lib.js", "same content", 0o644)
+	if err := os.Chtimes(newer, mtime.Add(time.Hour), mtime.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// Same size, different content.
+	other := write("vendor/e/lib.js", "diff content", 0o644)
+	// Empty files.
+	empty1 := write("empty1", "", 0o644)
+	empty2 := write("empty2", "", 0o644)
+	// An existing hardlink is not split.
+	linked := write("linked/lib.js", "same content", 0o644)
+	if err := os.Link(linked, filepath.Join(rootfs, "linked/lib2.js")); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := dedupFiles(rootfs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if files != 2 {
+		t.Errorf("got %d files, want 2", files)
+	}
+
+	ino := func(p string) uint64 {
+		fi, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi.Sys().(*syscall.Stat_t).Ino
+	}
+	if ino(a) != ino(b) || ino(a) != ino(c) {
+		t.Errorf("identical files not merged")
+	}
+	for _, p := range []string{exe, newer, other, linked} {
+		if ino(p) == ino(a) {
+			t.Errorf("%s unexpectedly merged", p)
+		}
+	}
+	if ino(empty1) == ino(empty2) {
+		t.Errorf("empty files unexpectedly merged")
+	}
+	if ino(linked) != ino(filepath.Join(rootfs, "linked/lib2.js")) {
+		t.Errorf("existing hardlink split")
+	}
+	if content, err := os.ReadFile(c); err != nil || string(content) != "same content" {
+		t.Errorf("got content %q %v", content, err)
+	}
+	if content, err := os.ReadFile(clash); err != nil || string(content) != "keep this" {
+		t.Errorf("got content %q %v", content, err)
+	}
+	if tmp, _ := filepath.Glob(filepath.Join(rootfs, ".dedup-*")); len(tmp) > 0 {
+		t.Errorf("temporary directory %v left in rootfs", tmp)
+	}
+}
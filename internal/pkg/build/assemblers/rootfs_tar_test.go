@@ -0,0 +1,105 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package assemblers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteRootfsTar(t *testing.T) {
+	tarPath, err := exec.LookPath("tar")
+	if err != nil {
+		t.Skipf("tar not found: %v", err)
+	}
+
+	rootfs := t.TempDir()
+	for _, d := range []string{"bin", "etc", "usr/lib"} {
+		if err := os.MkdirAll(filepath.Join(rootfs, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(rootfs, "bin/tool"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootfs, "etc/secret"), []byte("0600"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootfs, "usr/lib/libx.so.1"), []byte("library"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("libx.so.1", filepath.Join(rootfs, "usr/lib/libx.so")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/bin/tool", filepath.Join(rootfs, "usr/tool")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(filepath.Join(rootfs, "bin/tool"), filepath.Join(rootfs, "bin/tool-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, gz := range []bool{false, true} {
+		archive := filepath.Join(t.TempDir(), "rootfs.tar")
+		flags := "-xf"
+		if gz {
+			archive += ".gz"
+			flags = "-xzf"
+		}
+		if err := writeRootfsTar(rootfs, archive, gz); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		dst := t.TempDir()
+		if out, err := exec.Command(tarPath, flags, archive, "-C", dst).CombinedOutput(); err != nil {
+			t.Fatalf("could not extract %s: %v: %s", archive, err, out)
+		}
+
+		perms := map[string]os.FileMode{
+			"bin/tool":          0o755,
+			"etc/secret":        0o600,
+			"usr/lib/libx.so.1": 0o644,
+			"usr/lib":           0o755 | os.ModeDir,
+		}
+		for name, want := range perms {
+			fi, err := os.Lstat(filepath.Join(dst, name))
+			if err != nil {
+				t.Errorf("%s not extracted: %v", name, err)
+				continue
+			}
+			if got := fi.Mode() & (os.ModePerm | os.ModeDir); got != want {
+				t.Errorf("%s has mode %v, want %v", name, got, want)
+			}
+		}
+
+		links := map[string]string{
+			"usr/lib/libx.so": "libx.so.1",
+			"usr/tool":        "/bin/tool",
+		}
+		for name, want := range links {
+			if got, err := os.Readlink(filepath.Join(dst, name)); err != nil || got != want {
+				t.Errorf("%s links to %q %v, want %q", name, got, err, want)
+			}
+		}
+
+		a, err := os.Stat(filepath.Join(dst, "bin/tool"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.Stat(filepath.Join(dst, "bin/tool-link"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Sys().(*syscall.Stat_t).Ino != b.Sys().(*syscall.Stat_t).Ino {
+			t.Errorf("hardlink not preserved")
+		}
+		if content, err := os.ReadFile(filepath.Join(dst, "bin/tool-link")); err != nil || string(content) != "#!/bin/sh\n" {
+			t.Errorf("got hardlink content %q %v", content, err)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/pkg/build/types"
 	"github.com/sylabs/singularity/pkg/sylog"
 	"github.com/sylabs/singularity/pkg/util/archive"
@@ -27,6 +28,12 @@ func (a *SandboxAssembler) Assemble(b *types.Bundle, path string) (err error) {
 		os.RemoveAll(path)
 	}
 
+	if !b.Opts.RawSymlinks {
+		if err := confineSymlinks(b.RootfsPath); err != nil {
+			return err
+		}
+	}
+
 	if a.Copy {
 		sylog.Debugf("Copying sandbox from %v to %v", b.RootfsPath, path)
 
@@ -46,3 +53,27 @@ func (a *SandboxAssembler) Assemble(b *types.Bundle, path string) (err error) {
 
 	return nil
 }
+
+// confineSymlinks rewrites the symlinks of rootfs that resolve outside of it
+// when followed from the host, reporting them.
+func confineSymlinks(rootfs string) error {
+	sylog.Debugf("Confining symlinks to the root filesystem")
+	rewritten, err := fs.ConfineSymlinks(rootfs)
+	if err != nil {
+		return fmt.Errorf("while confining symlinks to the root filesystem: %v", err)
+	}
+
+	absolute := 0
+	for _, l := range rewritten {
+		if l.Clamped {
+			sylog.Warningf("Rewrote symlink /%s -> %s, climbing above the root filesystem, to %s", l.Path, l.Target, l.NewTarget)
+			continue
+		}
+		sylog.Verbosef("Rewrote absolute symlink /%s -> %s to %s", l.Path, l.Target, l.NewTarget)
+		absolute++
+	}
+	if absolute > 0 {
+		sylog.Infof("Rewrote %d absolute symlinks to relative symlinks", absolute)
+	}
+	return nil
+}
@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"os"
 	"regexp"
-	"runtime"
 	"sort"
 	"strconv"
 	"syscall"
@@ -117,10 +116,15 @@ func createSIF(path string, b *types.Bundle, squashfile string, encOpts *encrypt
 	// remove anything that may exist at the build destination at last moment
 	os.RemoveAll(path)
 
-	f, err := sif.CreateContainerAtPath(path,
+	opts := []sif.CreateOpt{
 		sif.OptCreateWithLaunchScript("#!/usr/bin/env run-singularity\n"),
 		sif.OptCreateWithDescriptors(dis...),
-	)
+	}
+	if !b.Opts.BuildTime.IsZero() {
+		opts = append(opts, sif.OptCreateWithTime(b.Opts.BuildTime))
+	}
+
+	f, err := sif.CreateContainerAtPath(path, opts...)
 	if err != nil {
 		return fmt.Errorf("while creating container: %w", err)
 	}
@@ -170,10 +174,26 @@ func (a *SIFAssembler) Assemble(b *types.Bundle, path string) error {
 	if a.MksquashfsProcs != 0 {
 		flags = append(flags, "-processors", fmt.Sprint(a.MksquashfsProcs))
 	}
-	arch := machine.ArchFromContainer(b.RootfsPath)
-	if arch == "" {
-		sylog.Infof("Architecture not recognized, use native")
-		arch = runtime.GOARCH
+	if b.Opts.ConfineSymlinks {
+		if err := confineSymlinks(b.RootfsPath); err != nil {
+			return err
+		}
+	}
+	if b.Opts.Dedup {
+		if _, err := dedupFiles(b.RootfsPath); err != nil {
+			return fmt.Errorf("while deduplicating files: %v", err)
+		}
+	}
+
+	if b.Opts.ExportRootfs != "" {
+		if err := writeRootfsTar(b.RootfsPath, b.Opts.ExportRootfs, b.Opts.ExportRootfsGzip); err != nil {
+			return fmt.Errorf("while exporting root filesystem: %v", err)
+		}
+	}
+
+	arch, err := sifArch(machine.ArchFromContainer(b.RootfsPath), b.Platform, b.Opts.SetArch)
+	if err != nil {
+		return err
 	}
 	sylog.Verbosef("Set SIF container architecture to %s", arch)
 
@@ -9,9 +9,11 @@ package oci
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/docker"
@@ -19,7 +21,10 @@ import (
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/transports"
 	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/pkg/sylog"
 )
 
@@ -27,6 +32,9 @@ import (
 type ImageReference struct {
 	source types.ImageReference
 	types.ImageReference
+	// cacheDir is the OCI layout of the cache holding the blobs of the image.
+	cacheDir string
+	imgCache *cache.Handle
 }
 
 // ConvertReference converts a source reference into a cache.ImageReference to cache its blobs
@@ -38,7 +46,9 @@ func ConvertReference(ctx context.Context, imgCache *cache.Handle, src types.Ima
 	// Our cache dir is an OCI directory. We are using this as a 'blob pool'
 	// storing all incoming containers under unique tags, which are a hash of
 	// their source URI.
-	cacheTag, err := getRefDigest(ctx, src, sys)
+	tctx, cancel := withRegistryTimeout(ctx, src)
+	defer cancel()
+	cacheTag, err := getRefDigest(tctx, src, sys)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +65,8 @@ func ConvertReference(ctx context.Context, imgCache *cache.Handle, src types.Ima
 	return &ImageReference{
 		source:         src,
 		ImageReference: c,
+		cacheDir:       cacheDir,
+		imgCache:       imgCache,
 	}, nil
 }
 
@@ -75,14 +87,20 @@ func (t *ImageReference) newImageSource(ctx context.Context, sys *types.SystemCo
 		return t.ImageReference.NewImageSource(ctx, sys)
 	}
 
-	// Otherwise, we are copying into the cache layout first
-	_, err = copy.Image(ctx, policyCtx, t.ImageReference, t.source, &copy.Options{
+	// Otherwise, we are copying into the cache layout first, holding a
+	// shared lock so that a cache gc doesn't run while blobs are written.
+	release := t.imgCache.LockShared()
+	defer release()
+	manifest, err := CopyImage(ctx, policyCtx, t.ImageReference, t.source, &copy.Options{
 		ReportWriter: w,
 		SourceCtx:    sys,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := t.verifyBlobs(ctx, manifest); err != nil {
+		return nil, err
+	}
 	return t.ImageReference.NewImageSource(ctx, sys)
 }
 
@@ -103,17 +121,51 @@ func (t *ImageReference) newImage(ctx context.Context, sys *types.SystemContext,
 		return t.ImageReference.NewImage(ctx, sys)
 	}
 
-	// Otherwise, we are copying into the cache layout first
-	_, err = copy.Image(ctx, policyCtx, t.ImageReference, t.source, &copy.Options{
+	// Otherwise, we are copying into the cache layout first, holding a
+	// shared lock so that a cache gc doesn't run while blobs are written.
+	release := t.imgCache.LockShared()
+	defer release()
+	manifest, err := CopyImage(ctx, policyCtx, t.ImageReference, t.source, &copy.Options{
 		ReportWriter: w,
 		SourceCtx:    sys,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := t.verifyBlobs(ctx, manifest); err != nil {
+		return nil, err
+	}
 	return t.ImageReference.NewImage(ctx, sys)
 }
 
+// verifyBlobs checks that the config and layers of manifest, copied to the
+// cache layout, match their digests, if ctx carries the number of blobs
+// verified in parallel. containers/image already verifies the digests of the
+// blobs as they are copied, so reading them back is only done on request.
+func (t *ImageReference) verifyBlobs(ctx context.Context, manifest []byte) error {
+	jobs, ok := client.VerifyJobsFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var m imgspecv1.Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return fmt.Errorf("while decoding manifest: %v", err)
+	}
+	digests := []digest.Digest{m.Config.Digest}
+	for _, l := range m.Layers {
+		digests = append(digests, l.Digest)
+	}
+
+	start := time.Now()
+	err := client.VerifyBlobs(ctx, t.cacheDir, digests, jobs)
+	if err != nil {
+		return fmt.Errorf("while verifying cached blobs: %w (remove them with 'singularity cache clean --type blob')", err)
+	}
+	sylog.Debugf("Verified %d cached blobs with %d jobs in %v", len(digests), jobs, time.Since(start))
+	return nil
+}
+
 // ParseImageName parses a uri (e.g. docker://ubuntu) into it's transport:reference
 // combination and then returns the proper reference
 func ParseImageName(ctx context.Context, imgCache *cache.Handle, uri string, sys *types.SystemContext) (types.ImageReference, error) {
@@ -147,10 +199,118 @@ func ImageDigest(ctx context.Context, uri string, sys *types.SystemContext) (str
 	if err != nil {
 		return "", fmt.Errorf("unable to parse image name %v: %v", uri, err)
 	}
+	ctx, cancel := withRegistryTimeout(ctx, ref)
+	defer cancel()
 
 	return getRefDigest(ctx, ref, sys)
 }
 
+// ImageInfo obtains the digest of a uri's manifest, together with the
+// architecture and total compressed layer size of its image, without
+// fetching the layers.
+func ImageInfo(ctx context.Context, uri string, sys *types.SystemContext) (digest, arch string, size int64, err error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("unable to parse image name %v: %v", uri, err)
+	}
+	ctx, cancel := withRegistryTimeout(ctx, ref)
+	defer cancel()
+
+	digest, err = getRefDigest(ctx, ref, sys)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	img, err := ref.NewImage(ctx, sys)
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer img.Close()
+
+	imgSpec, err := img.OCIConfig(ctx)
+	if err != nil {
+		return "", "", 0, err
+	}
+	for _, l := range img.LayerInfos() {
+		size += l.Size
+	}
+
+	return digest, imgSpec.Architecture, size, nil
+}
+
+// LayerCount obtains the number of layers of the image at uri, from its
+// manifest, without fetching the layers.
+func LayerCount(ctx context.Context, uri string, sys *types.SystemContext) (int, error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse image name %v: %v", uri, err)
+	}
+	ctx, cancel := withRegistryTimeout(ctx, ref)
+	defer cancel()
+
+	img, err := ref.NewImage(ctx, sys)
+	if err != nil {
+		return 0, err
+	}
+	defer img.Close()
+
+	return len(img.LayerInfos()), nil
+}
+
+// Layer describes a layer of an image, from its manifest.
+type Layer struct {
+	Digest    string
+	MediaType string
+	Size      int64
+}
+
+// ImageLayers obtains the digest of a uri's manifest, together with the
+// layers of its image, in order from the base layer, without fetching them.
+func ImageLayers(ctx context.Context, uri string, sys *types.SystemContext) (digest string, layers []Layer, err error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to parse image name %v: %v", uri, err)
+	}
+	ctx, cancel := withRegistryTimeout(ctx, ref)
+	defer cancel()
+
+	digest, err = getRefDigest(ctx, ref, sys)
+	if err != nil {
+		return "", nil, err
+	}
+
+	img, err := ref.NewImage(ctx, sys)
+	if err != nil {
+		return "", nil, err
+	}
+	defer img.Close()
+
+	for _, l := range img.LayerInfos() {
+		layers = append(layers, Layer{
+			Digest:    l.Digest.String(),
+			MediaType: l.MediaType,
+			Size:      l.Size,
+		})
+	}
+	return digest, layers, nil
+}
+
+// ListTags lists the tags of the repository of a docker uri, e.g.
+// docker://alpine.
+func ListTags(ctx context.Context, uri string, sys *types.SystemContext) ([]string, error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse image name %v: %v", uri, err)
+	}
+	if ref.Transport().Name() != "docker" {
+		return nil, fmt.Errorf("listing tags is only supported for docker repositories")
+	}
+	ctx, cancel := withRegistryTimeout(ctx, ref)
+	defer cancel()
+
+	return docker.GetRepositoryTags(ctx, sys, ref)
+}
+
 // getRefDigest obtains the manifest digest for a ref.
 func getRefDigest(ctx context.Context, ref types.ImageReference, sys *types.SystemContext) (digest string, err error) {
 	// Handle docker references specially, using a HEAD request to ensure we don't hit API limits
@@ -0,0 +1,65 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"time"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/types"
+	"github.com/sylabs/singularity/internal/pkg/client"
+)
+
+// progressInterval is the interval between two progress reports of a blob
+// sent to a client.ProgressReporter.
+const progressInterval = 100 * time.Millisecond
+
+// withProgress returns a copy of opts sending the progress of each blob to
+// the client.ProgressReporter carried by ctx, and a function to call once
+// the copy is done. opts is returned as is if ctx carries no reporter, or
+// opts already has a progress channel.
+func withProgress(ctx context.Context, opts *copy.Options) (*copy.Options, func()) {
+	r := client.ProgressReporterFromContext(ctx)
+	if r == nil || opts.Progress != nil {
+		return opts, func() {}
+	}
+
+	progress := make(chan types.ProgressProperties)
+	done := make(chan struct{})
+	go func() {
+		forwardProgress(r, progress)
+		close(done)
+	}()
+
+	o := *opts
+	o.Progress = progress
+	o.ProgressInterval = progressInterval
+	return &o, func() {
+		close(progress)
+		<-done
+	}
+}
+
+// forwardProgress sends the blob progress read from progress to r, until
+// progress is closed.
+func forwardProgress(r *client.ProgressReporter, progress <-chan types.ProgressProperties) {
+	for p := range progress {
+		ev := client.ProgressEvent{
+			Phase: client.PhaseDownload,
+			Blob:  p.Artifact.Digest.String(),
+			Bytes: int64(p.Offset),
+			Total: p.Artifact.Size,
+		}
+		switch p.Event {
+		case types.ProgressEventDone, types.ProgressEventSkipped:
+			ev.Bytes = p.Artifact.Size
+		case types.ProgressEventNewArtifact:
+			ev.Bytes = 0
+		}
+		r.Report(ev)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"errors"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/transports"
+	"github.com/containers/image/v5/types"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// maxReauthAttempts is the number of times a copy is restarted, with a fresh
+// registry token, after the registry rejected the token in use.
+const maxReauthAttempts = 2
+
+// CopyImage copies src to dest like copy.Image. A pull that outlives the
+// lifetime of its registry bearer token fails with a 401 on the next request.
+// In this case the copy is restarted, which runs the token exchange again,
+// and blobs that were already stored in dest are not fetched again.
+//
+// The progress of each blob is sent to the client.ProgressReporter carried by
+// ctx, if any. The manifests of src are checked as they are fetched, and a
+// *client.UnsupportedSchemaError is returned for one in a newer format. The
+// registry timeout carried by ctx for the registry of src, if any, applies to
+// the manifests and blobs fetched.
+func CopyImage(ctx context.Context, policyCtx *signature.PolicyContext, dest, src types.ImageReference, opts *copy.Options) ([]byte, error) {
+	opts, stop := withProgress(ctx, opts)
+	defer stop()
+
+	// Fail with a clear error before any layer is fetched, rather than a
+	// decoding error of the conversion, for an image in a newer format.
+	src = schemaCheckedReference{withTimeout(ctx, src)}
+
+	for attempt := 0; ; attempt++ {
+		manifest, err := copy.Image(ctx, policyCtx, dest, src, opts)
+		if err == nil || attempt >= maxReauthAttempts || !isTokenRejected(err) {
+			return manifest, err
+		}
+		sylog.Debugf("Registry token rejected during pull of %s, re-authenticating (attempt %d/%d): %v",
+			transports.ImageName(src), attempt+1, maxReauthAttempts, err)
+	}
+}
+
+// isTokenRejected returns true if err is a 401 returned by a registry for a
+// request made with a bearer token. Credentials rejected during the token
+// exchange itself are reported as docker.ErrUnauthorizedForCredentials and
+// are not retried, as re-authenticating would fail the same way.
+func isTokenRejected(err error) bool {
+	var e errcode.Error
+	if errors.As(err, &e) {
+		return e.Code == errcode.ErrorCodeUnauthorized
+	}
+	var errs errcode.Errors
+	if errors.As(err, &errs) {
+		for _, err := range errs {
+			if isTokenRejected(err) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/oci/layout"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/types"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/opencontainers/go-digest"
+	imgspecs "github.com/opencontainers/image-spec/specs-go"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// tokenRegistry is a minimal registry serving a single image, test:latest,
+// which hands out bearer tokens that expire before the layer is fetched.
+type tokenRegistry struct {
+	sync.Mutex
+	// expired returns true if the token should be rejected for the layer.
+	expired  func(token string) bool
+	issued   int
+	manifest []byte
+	blobs    map[digest.Digest][]byte
+	layer    digest.Digest
+}
+
+func newTokenRegistry(t *testing.T, expired func(token string) bool) *httptest.Server {
+	srv := httptest.NewServer(newTokenRegistryHandler(t, expired))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTokenRegistryHandler(t *testing.T, expired func(token string) bool) *tokenRegistry {
+	var tarBuf, layerBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	content := []byte("hello")
+	if err := tw.WriteHeader(&tar.Header{Name: "hello", Mode: 0o644, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gz := gzip.NewWriter(&layerBuf)
+	if _, err := gz.Write(tarBuf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	layer := layerBuf.Bytes()
+
+	config, err := json.Marshal(imgspecv1.Image{
+		Architecture: "amd64",
+		OS:           "linux",
+		RootFS:       imgspecv1.RootFS{Type: "layers", DiffIDs: []digest.Digest{digest.FromBytes(tarBuf.Bytes())}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := json.Marshal(imgspecv1.Manifest{
+		Versioned: imgspecs.Versioned{SchemaVersion: 2},
+		MediaType: imgspecv1.MediaTypeImageManifest,
+		Config: imgspecv1.Descriptor{
+			MediaType: imgspecv1.MediaTypeImageConfig,
+			Digest:    digest.FromBytes(config),
+			Size:      int64(len(config)),
+		},
+		Layers: []imgspecv1.Descriptor{{
+			MediaType: imgspecv1.MediaTypeImageLayerGzip,
+			Digest:    digest.FromBytes(layer),
+			Size:      int64(len(layer)),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tokenRegistry{
+		expired:  expired,
+		manifest: manifest,
+		blobs: map[digest.Digest][]byte{
+			digest.FromBytes(config): config,
+			digest.FromBytes(layer):  layer,
+		},
+		layer: digest.FromBytes(layer),
+	}
+}
+
+func (r *tokenRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	if req.URL.Path == "/token" {
+		r.issued++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token": "token-%d", "expires_in": 60}`, r.issued)
+		return
+	}
+
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case req.URL.Path == "/v2/":
+		w.WriteHeader(http.StatusOK)
+	case req.URL.Path == "/v2/test/manifests/latest":
+		w.Header().Set("Content-Type", imgspecv1.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(r.manifest).String())
+		w.Write(r.manifest)
+	case strings.HasPrefix(req.URL.Path, "/v2/test/blobs/"):
+		d := digest.Digest(strings.TrimPrefix(req.URL.Path, "/v2/test/blobs/"))
+		if d == r.layer && r.expired(token) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors": [{"code": "UNAUTHORIZED", "message": "token expired"}]}`)
+			return
+		}
+		b, ok := r.blobs[d]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *tokenRegistry) tokens() int {
+	r.Lock()
+	defer r.Unlock()
+	return r.issued
+}
+
+func TestCopyImageReauth(t *testing.T) {
+	tests := []struct {
+		name       string
+		expired    func(token string) bool
+		wantErr    bool
+		wantTokens int
+	}{
+		{
+			name:       "FirstTokenExpires",
+			expired:    func(token string) bool { return token == "token-1" },
+			wantTokens: 2,
+		},
+		{
+			name:       "AlwaysExpired",
+			expired:    func(string) bool { return true },
+			wantErr:    true,
+			wantTokens: maxReauthAttempts + 1,
+		},
+	}
+
+	policy := &signature.Policy{Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()}}
+	policyCtx, err := signature.NewPolicyContext(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTokenRegistry(t, tt.expired)
+
+			src, err := docker.ParseReference("//" + strings.TrimPrefix(srv.URL, "http://") + "/test:latest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dest, err := layout.ParseReference(t.TempDir() + ":test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = CopyImage(context.Background(), policyCtx, dest, src, &copy.Options{
+				SourceCtx: &types.SystemContext{
+					DockerInsecureSkipTLSVerify: types.NewOptionalBool(true),
+					OSChoice:                    "linux",
+				},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := srv.Config.Handler.(*tokenRegistry).tokens(); got != tt.wantTokens {
+				t.Errorf("got %d token exchanges, want %d", got, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestIsTokenRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Unauthorized", fmt.Errorf("fetching blob: %w", errcode.ErrorCodeUnauthorized.WithMessage("expired")), true},
+		{"UnauthorizedList", errcode.Errors{errcode.ErrorCodeUnauthorized.WithMessage("expired")}, true},
+		{"Denied", errcode.ErrorCodeDenied.WithMessage("denied"), false},
+		{"BadCredentials", docker.ErrUnauthorizedForCredentials{Err: errcode.ErrorCodeUnauthorized}, false},
+		{"Other", fmt.Errorf("some error"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTokenRejected(tt.err); got != tt.want {
+				t.Errorf("isTokenRejected(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/pkg/blobinfocache/none"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// maxEnvelopeSize is the maximum size of a DSSE envelope read from a
+// registry.
+const maxEnvelopeSize = 16 << 20
+
+// attachmentManifest is the part of an image or artifact manifest holding
+// the blobs attached to an image.
+type attachmentManifest struct {
+	Layers []imgspecv1.Descriptor `json:"layers"`
+	Blobs  []imgspecv1.Descriptor `json:"blobs"`
+}
+
+// Attestations obtains the digest of a docker uri's manifest, together with
+// the DSSE envelopes attached to it. They are found through the referrers tag
+// schema of the OCI distribution spec, an index tagged sha256-<digest> of the
+// artifacts referring to the image, and the tag cosign attaches attestations
+// to, sha256-<digest>.att. A missing tag is not an error.
+func Attestations(ctx context.Context, uri string, sys *types.SystemContext) (dgst string, envelopes [][]byte, err error) {
+	ref, err := parseURI(uri)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to parse image name %v: %v", uri, err)
+	}
+	if ref.Transport().Name() != "docker" {
+		return "", nil, fmt.Errorf("attestations are only supported for docker images")
+	}
+	ctx, cancel := withRegistryTimeout(ctx, ref)
+	defer cancel()
+
+	dgst, err = getRefDigest(ctx, ref, sys)
+	if err != nil {
+		return "", nil, err
+	}
+	d := digest.Digest(strings.Replace(dgst, ".", ":", 1))
+
+	repo := reference.TrimNamed(ref.DockerReference())
+	for _, tag := range []string{d.Algorithm().String() + "-" + d.Encoded(), d.Algorithm().String() + "-" + d.Encoded() + ".att"} {
+		tagged, err := reference.WithTag(repo, tag)
+		if err != nil {
+			return "", nil, err
+		}
+		tref, err := docker.NewReference(tagged)
+		if err != nil {
+			return "", nil, err
+		}
+		envs, err := attachedEnvelopes(ctx, tref, sys)
+		if err != nil {
+			sylog.Debugf("No attestations at %s: %v", tagged, err)
+			continue
+		}
+		sylog.Debugf("Found %d attestations at %s", len(envs), tagged)
+		envelopes = append(envelopes, envs...)
+	}
+	return dgst, envelopes, nil
+}
+
+// attachedEnvelopes returns the DSSE envelopes held by the manifest ref
+// refers to, or by the manifests of the index it refers to.
+func attachedEnvelopes(ctx context.Context, ref types.ImageReference, sys *types.SystemContext) (envelopes [][]byte, err error) {
+	src, err := ref.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := src.Close(); closeErr != nil {
+			err = fmt.Errorf("%w (src: %v)", err, closeErr)
+		}
+	}()
+
+	man, mediaType, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := [][]byte{man}
+	if manifest.MIMETypeIsMultiImage(mediaType) {
+		var index imgspecv1.Index
+		if err := json.Unmarshal(man, &index); err != nil {
+			return nil, fmt.Errorf("invalid index: %v", err)
+		}
+		manifests = manifests[:0]
+		for _, m := range index.Manifests {
+			m := m
+			b, _, err := src.GetManifest(ctx, &m.Digest)
+			if err != nil {
+				return nil, err
+			}
+			manifests = append(manifests, b)
+		}
+	}
+
+	for _, b := range manifests {
+		var m attachmentManifest
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("invalid manifest: %v", err)
+		}
+		for _, l := range append(m.Layers, m.Blobs...) {
+			if l.MediaType != client.DSSEMediaType {
+				continue
+			}
+			env, err := readBlob(ctx, src, l)
+			if err != nil {
+				return nil, err
+			}
+			envelopes = append(envelopes, env)
+		}
+	}
+	return envelopes, nil
+}
+
+// readBlob reads the blob described by desc from src, checking its digest.
+func readBlob(ctx context.Context, src types.ImageSource, desc imgspecv1.Descriptor) ([]byte, error) {
+	if desc.Size > maxEnvelopeSize {
+		return nil, fmt.Errorf("blob %s is %d bytes, over the maximum of %d", desc.Digest, desc.Size, maxEnvelopeSize)
+	}
+	rc, _, err := src.GetBlob(ctx, types.BlobInfo{Digest: desc.Digest, Size: desc.Size}, none.NoCache)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching blob %s: %v", desc.Digest, err)
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(io.LimitReader(rc, maxEnvelopeSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("while fetching blob %s: %v", desc.Digest, err)
+	}
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, err
+	}
+	if desc.Digest.Algorithm().FromBytes(b) != desc.Digest {
+		return nil, fmt.Errorf("content of blob %s does not match digest", desc.Digest)
+	}
+	return b, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"errors"
+
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// schemaCheckedReference wraps the source reference of a copy, so that the
+// manifests the copy fetches, including the one it selects from an index or
+// manifest list, are checked to use schemas the conversion supports before
+// any config or layer is fetched, without any request of its own.
+type schemaCheckedReference struct {
+	types.ImageReference
+}
+
+// NewImageSource returns an image source whose manifests are checked.
+func (r schemaCheckedReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	return schemaCheckedSource{src}, nil
+}
+
+// schemaCheckedSource is an image source whose manifests are checked.
+type schemaCheckedSource struct {
+	types.ImageSource
+}
+
+// GetManifest returns the manifest of the image, or of instanceDigest, and
+// its media type, or a *client.UnsupportedSchemaError if it uses a schema
+// newer than supported. A manifest which can't be checked for any other
+// reason is returned as is, so the copy reports the issue.
+func (s schemaCheckedSource) GetManifest(ctx context.Context, instanceDigest *digest.Digest) ([]byte, string, error) {
+	man, mediaType, err := s.ImageSource.GetManifest(ctx, instanceDigest)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := client.CheckManifestSchema(man, mediaType); err != nil {
+		var schemaErr *client.UnsupportedSchemaError
+		if errors.As(err, &schemaErr) {
+			return nil, "", err
+		}
+		sylog.Debugf("Could not check schema of manifest: %v", err)
+	}
+	return man, mediaType, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/internal/pkg/client"
+)
+
+// manifestSource is an image source serving a single manifest, and counting
+// the requests for it.
+type manifestSource struct {
+	types.ImageSource
+	manifest  []byte
+	mediaType string
+	requests  int
+}
+
+func (s *manifestSource) GetManifest(context.Context, *digest.Digest) ([]byte, string, error) {
+	s.requests++
+	return s.manifest, s.mediaType, nil
+}
+
+func TestSchemaCheckedSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifest  string
+		mediaType string
+		wantErr   bool
+	}{
+		{
+			name:      "Supported",
+			manifest:  `{"schemaVersion":2,"config":{"mediaType":"application/vnd.oci.image.config.v1+json"}}`,
+			mediaType: imgspecv1.MediaTypeImageManifest,
+		},
+		{
+			name:      "NewerSchemaVersion",
+			manifest:  `{"schemaVersion":3}`,
+			mediaType: imgspecv1.MediaTypeImageManifest,
+			wantErr:   true,
+		},
+		{
+			name:      "NewerConfig",
+			manifest:  `{"schemaVersion":2,"config":{"mediaType":"application/vnd.oci.image.config.v2+json"}}`,
+			mediaType: imgspecv1.MediaTypeImageManifest,
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid",
+			manifest:  `not json`,
+			mediaType: imgspecv1.MediaTypeImageManifest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &manifestSource{manifest: []byte(tt.manifest), mediaType: tt.mediaType}
+			man, _, err := schemaCheckedSource{src}.GetManifest(context.Background(), nil)
+
+			var schemaErr *client.UnsupportedSchemaError
+			if got := errors.As(err, &schemaErr); got != tt.wantErr {
+				t.Fatalf("got error %v, want UnsupportedSchemaError %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(man) != tt.manifest {
+				t.Errorf("got manifest %q, want %q", man, tt.manifest)
+			}
+			if src.requests != 1 {
+				t.Errorf("got %d manifest requests, want 1", src.requests)
+			}
+		})
+	}
+}
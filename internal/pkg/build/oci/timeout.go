@@ -0,0 +1,187 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/image"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// registryHost returns the host of the registry of a docker reference, or an
+// empty string for another transport.
+func registryHost(ref types.ImageReference) string {
+	if ref.Transport().Name() != "docker" || ref.DockerReference() == nil {
+		return ""
+	}
+	return reference.Domain(ref.DockerReference())
+}
+
+// withRegistryTimeout returns a copy of ctx which expires after the registry
+// timeout carried by ctx for the registry of ref, if any. It bounds requests
+// fetching metadata only (digests, manifests, configs and tags), which are
+// small enough to be bounded as a whole, while copies use a timeoutReference.
+func withRegistryTimeout(ctx context.Context, ref types.ImageReference) (context.Context, context.CancelFunc) {
+	host := registryHost(ref)
+	if host == "" {
+		return context.WithCancel(ctx)
+	}
+	d := client.RegistryTimeoutsFromContext(ctx).For(host)
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// timeoutReference wraps the source reference of a copy from a registry, so
+// that a manifest not received within timeout, or a blob not sending any data
+// for timeout, fails the copy. containers/image builds the transport of its
+// registry client itself, so the timeout is applied to the requests of the
+// image source, through their context, rather than to the connections.
+type timeoutReference struct {
+	types.ImageReference
+	host    string
+	timeout time.Duration
+}
+
+// withTimeout returns ref wrapped in a timeoutReference, if the registry
+// timeouts carried by ctx set a timeout for the registry of ref.
+func withTimeout(ctx context.Context, ref types.ImageReference) types.ImageReference {
+	host := registryHost(ref)
+	if host == "" {
+		return ref
+	}
+	d := client.RegistryTimeoutsFromContext(ctx).For(host)
+	if d <= 0 {
+		return ref
+	}
+	sylog.Verbosef("Using connect and read timeout of %v for %s", d, host)
+	return timeoutReference{ImageReference: ref, host: host, timeout: d}
+}
+
+// NewImage returns an image whose manifest and blobs are fetched with the
+// timeout of the reference.
+func (r timeoutReference) NewImage(ctx context.Context, sys *types.SystemContext) (types.ImageCloser, error) {
+	src, err := r.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	return image.FromSource(ctx, sys, src)
+}
+
+// NewImageSource returns an image source whose manifests and blobs are
+// fetched with the timeout of the reference.
+func (r timeoutReference) NewImageSource(ctx context.Context, sys *types.SystemContext) (types.ImageSource, error) {
+	src, err := r.ImageReference.NewImageSource(ctx, sys)
+	if err != nil {
+		return nil, err
+	}
+	return timeoutSource{ImageSource: src, host: r.host, timeout: r.timeout}, nil
+}
+
+// timeoutSource is an image source whose manifests and blobs are fetched with
+// a timeout.
+type timeoutSource struct {
+	types.ImageSource
+	host    string
+	timeout time.Duration
+}
+
+// GetManifest returns the manifest of the image, or of instanceDigest, and
+// its media type, failing if it is not received within the timeout.
+func (s timeoutSource) GetManifest(ctx context.Context, instanceDigest *digest.Digest) ([]byte, string, error) {
+	ctx, w := newWatchdog(ctx, s.host, s.timeout)
+	defer w.stop()
+	man, mediaType, err := s.ImageSource.GetManifest(ctx, instanceDigest)
+	return man, mediaType, w.err(err)
+}
+
+// GetBlob returns a stream for the blob described by info, and its size,
+// failing if the registry doesn't respond, or stops sending data, for the
+// timeout.
+func (s timeoutSource) GetBlob(ctx context.Context, info types.BlobInfo, cache types.BlobInfoCache) (io.ReadCloser, int64, error) {
+	ctx, w := newWatchdog(ctx, s.host, s.timeout)
+	rc, size, err := s.ImageSource.GetBlob(ctx, info, cache)
+	if err != nil {
+		w.stop()
+		return nil, 0, w.err(err)
+	}
+	w.pause()
+	return &timeoutReader{ReadCloser: rc, w: w}, size, nil
+}
+
+// timeoutReader is the stream of a blob, failing a read which doesn't
+// complete within the timeout of its watchdog.
+type timeoutReader struct {
+	io.ReadCloser
+	w *watchdog
+}
+
+func (r *timeoutReader) Read(p []byte) (int, error) {
+	r.w.resume()
+	n, err := r.ReadCloser.Read(p)
+	r.w.pause()
+	return n, r.w.err(err)
+}
+
+func (r *timeoutReader) Close() error {
+	r.w.stop()
+	return r.ReadCloser.Close()
+}
+
+// watchdog cancels the context of a request once its timer expires. The
+// timer runs only while waiting for the registry, so that the time taken by
+// the consumer of a blob is not counted.
+type watchdog struct {
+	host    string
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	expired atomic.Bool
+}
+
+// newWatchdog returns a copy of ctx, cancelled by the returned watchdog after
+// timeout unless paused.
+func newWatchdog(ctx context.Context, host string, timeout time.Duration) (context.Context, *watchdog) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watchdog{host: host, timeout: timeout, cancel: cancel}
+	w.timer = time.AfterFunc(timeout, func() {
+		w.expired.Store(true)
+		cancel()
+	})
+	return ctx, w
+}
+
+func (w *watchdog) pause() {
+	w.timer.Stop()
+}
+
+func (w *watchdog) resume() {
+	w.timer.Reset(w.timeout)
+}
+
+func (w *watchdog) stop() {
+	w.timer.Stop()
+	w.cancel()
+}
+
+// err returns a timeout error in place of err if the watchdog expired, as err
+// then only reports the cancellation of the request.
+func (w *watchdog) err(err error) error {
+	if err == nil || err == io.EOF || !w.expired.Load() {
+		return err
+	}
+	return fmt.Errorf("registry %s did not respond within %v: %w", w.host, w.timeout, err)
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/oci/layout"
+	"github.com/containers/image/v5/signature"
+	"github.com/containers/image/v5/types"
+	"github.com/sylabs/singularity/internal/pkg/client"
+)
+
+// newStallingRegistry returns a registry serving test:latest like
+// newTokenRegistry, whose layer is sent in chunks separated by pause, and
+// which stops sending it after the first chunk if stall is true. If
+// stallManifest is true, no manifest is sent.
+func newStallingRegistry(t *testing.T, pause time.Duration, stall, stallManifest bool) string {
+	r := newTokenRegistryHandler(t, func(string) bool { return false })
+	layer := r.blobs[r.layer]
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authorized := req.Header.Get("Authorization") != ""
+		switch {
+		case authorized && stallManifest && strings.HasPrefix(req.URL.Path, "/v2/test/manifests/"):
+			wait(req, 30*time.Second)
+		case authorized && req.URL.Path == "/v2/test/blobs/"+r.layer.String():
+			w.Header().Set("Content-Length", strconv.Itoa(len(layer)))
+			w.WriteHeader(http.StatusOK)
+			for i := 0; i < len(layer); i += len(layer)/4 + 1 {
+				if i > 0 && !wait(req, pause) {
+					return
+				}
+				end := i + len(layer)/4 + 1
+				if end > len(layer) {
+					end = len(layer)
+				}
+				w.Write(layer[i:end])
+				w.(http.Flusher).Flush()
+				if stall {
+					wait(req, 30*time.Second)
+					return
+				}
+			}
+		default:
+			r.ServeHTTP(w, req)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// wait waits for d, and returns false if the request was cancelled first.
+func wait(req *http.Request, d time.Duration) bool {
+	select {
+	case <-req.Context().Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func TestCopyImageRegistryTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		pause   time.Duration
+		stall   bool
+		wantErr bool
+	}{
+		{
+			name:    "StalledLayer",
+			stall:   true,
+			wantErr: true,
+		},
+		{
+			// Each chunk arrives within the timeout, though the layer takes
+			// longer as a whole.
+			name:  "SlowLayer",
+			pause: 300 * time.Millisecond,
+		},
+	}
+
+	policy := &signature.Policy{Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()}}
+	policyCtx, err := signature.NewPolicyContext(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := newStallingRegistry(t, tt.pause, tt.stall, false)
+
+			src, err := docker.ParseReference("//" + host + "/test:latest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			dest, err := layout.ParseReference(t.TempDir() + ":test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := client.WithRegistryTimeouts(context.Background(), client.RegistryTimeouts{
+				Hosts: map[string]time.Duration{host: 500 * time.Millisecond},
+			})
+			start := time.Now()
+			_, err = CopyImage(ctx, policyCtx, dest, src, &copy.Options{
+				SourceCtx: &types.SystemContext{
+					DockerInsecureSkipTLSVerify: types.NewOptionalBool(true),
+					OSChoice:                    "linux",
+				},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "did not respond within 500ms") {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("copy took %v, expected the timeout to apply", elapsed)
+			}
+		})
+	}
+}
+
+func TestImageDigestRegistryTimeout(t *testing.T) {
+	host := newStallingRegistry(t, 0, false, true)
+
+	ctx := client.WithRegistryTimeouts(context.Background(), client.RegistryTimeouts{
+		Default: 500 * time.Millisecond,
+	})
+	start := time.Now()
+	_, err := ImageDigest(ctx, "docker://"+host+"/test:latest", &types.SystemContext{
+		DockerInsecureSkipTLSVerify: types.NewOptionalBool(true),
+	})
+	if err == nil {
+		t.Fatalf("unexpected success")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("digest took %v, expected the timeout to apply", elapsed)
+	}
+}
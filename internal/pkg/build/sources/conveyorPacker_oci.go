@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/docker"
@@ -31,6 +32,7 @@ import (
 	"github.com/containers/image/v5/types"
 	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sylabs/singularity/internal/pkg/build/oci"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/util/shell"
 	sytypes "github.com/sylabs/singularity/pkg/build/types"
 	"github.com/sylabs/singularity/pkg/image"
@@ -120,12 +122,13 @@ exec "$@"
 
 // OCIConveyorPacker holds stuff that needs to be packed into the bundle
 type OCIConveyorPacker struct {
-	srcRef    types.ImageReference
-	b         *sytypes.Bundle
-	tmpfsRef  types.ImageReference
-	policyCtx *signature.PolicyContext
-	imgConfig imgspecv1.ImageConfig
-	sysCtx    *types.SystemContext
+	srcRef      types.ImageReference
+	b           *sytypes.Bundle
+	tmpfsRef    types.ImageReference
+	policyCtx   *signature.PolicyContext
+	imgConfig   imgspecv1.ImageConfig
+	annotations map[string]string
+	sysCtx      *types.SystemContext
 }
 
 // Get downloads container information from the specified source
@@ -148,6 +151,8 @@ func (cp *OCIConveyorPacker) Get(ctx context.Context, b *sytypes.Bundle) (err er
 		DockerAuthConfig:         cp.b.Opts.DockerAuthConfig,
 		DockerDaemonHost:         cp.b.Opts.DockerDaemonHost,
 		OSChoice:                 "linux",
+		ArchitectureChoice:       cp.b.Opts.Architecture,
+		VariantChoice:            cp.b.Opts.Variant,
 		AuthFilePath:             syfs.DockerConf(),
 		DockerRegistryUserAgent:  useragent.Value(),
 		BigFilesTemporaryDir:     b.TmpDir,
@@ -236,6 +241,14 @@ func (cp *OCIConveyorPacker) Get(ctx context.Context, b *sytypes.Bundle) (err er
 		return err
 	}
 
+	if len(cp.b.Opts.ImportAnnotations) > 0 {
+		annotations, err := cp.getAnnotations(ctx)
+		if err != nil {
+			return fmt.Errorf("while reading annotations: %v", err)
+		}
+		cp.annotations = selectAnnotations(annotations, cp.b.Opts.ImportAnnotations)
+	}
+
 	return nil
 }
 
@@ -276,7 +289,7 @@ func (cp *OCIConveyorPacker) Pack(ctx context.Context) (*sytypes.Bundle, error)
 
 func (cp *OCIConveyorPacker) fetch(ctx context.Context) error {
 	// cp.srcRef contains the cache source reference
-	_, err := copy.Image(ctx, cp.policyCtx, cp.tmpfsRef, cp.srcRef, &copy.Options{
+	_, err := oci.CopyImage(ctx, cp.policyCtx, cp.tmpfsRef, cp.srcRef, &copy.Options{
 		ReportWriter: io.Discard,
 		SourceCtx:    cp.sysCtx,
 	})
@@ -294,9 +307,80 @@ func (cp *OCIConveyorPacker) getConfig(ctx context.Context) (imgspecv1.ImageConf
 	if err != nil {
 		return imgspecv1.ImageConfig{}, err
 	}
+	// Record the platform of the image, used as the architecture of the SIF
+	// when it can't be told from the executables of the root filesystem.
+	cp.b.Platform = imgSpec.Architecture
+	// Record when the image was built, if requested, unless a label of the
+	// image already does, as images without a created field are not dated.
+	if cp.b.Opts.RecordCreated && imgSpec.Created != nil && !imgSpec.Created.IsZero() {
+		if _, ok := imgSpec.Config.Labels[client.CreatedLabel]; !ok {
+			if imgSpec.Config.Labels == nil {
+				imgSpec.Config.Labels = make(map[string]string)
+			}
+			imgSpec.Config.Labels[client.CreatedLabel] = imgSpec.Created.UTC().Format(time.RFC3339)
+		}
+	}
 	return imgSpec.Config, nil
 }
 
+// getAnnotations returns the annotations of the image manifest and of its
+// config descriptor, if any, as merged by manifestAnnotations.
+func (cp *OCIConveyorPacker) getAnnotations(ctx context.Context) (map[string]string, error) {
+	img, err := cp.srcRef.NewImage(ctx, cp.sysCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer img.Close()
+
+	raw, mediaType, err := img.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if mediaType != imgspecv1.MediaTypeImageManifest {
+		sylog.Debugf("Manifest of type %s has no annotations", mediaType)
+		return nil, nil
+	}
+
+	var manifest imgspecv1.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, err
+	}
+	return manifestAnnotations(manifest), nil
+}
+
+// manifestAnnotations returns the annotations of manifest, and those of the
+// descriptor of its config. Annotations of the manifest take precedence over
+// config annotations with the same key.
+func manifestAnnotations(manifest imgspecv1.Manifest) map[string]string {
+	annotations := make(map[string]string)
+	for k, v := range manifest.Config.Annotations {
+		annotations[k] = v
+	}
+	for k, v := range manifest.Annotations {
+		if c, ok := annotations[k]; ok && c != v {
+			sylog.Debugf("Annotation %s of the manifest overrides config annotation %s=%s", k, k, c)
+		}
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// selectAnnotations returns the annotations matching keys. A key ending in
+// '*' matches any annotation with that prefix, and "all" matches every
+// annotation.
+func selectAnnotations(annotations map[string]string, keys []string) map[string]string {
+	selected := make(map[string]string)
+	for k, v := range annotations {
+		for _, key := range keys {
+			if key == "all" || key == k || (strings.HasSuffix(key, "*") && strings.HasPrefix(k, strings.TrimSuffix(key, "*"))) {
+				selected[k] = v
+				break
+			}
+		}
+	}
+	return selected
+}
+
 func (cp *OCIConveyorPacker) insertOCIConfig() error {
 	conf, err := json.Marshal(cp.imgConfig)
 	if err != nil {
@@ -523,6 +607,40 @@ func (cp *OCIConveyorPacker) insertOCILabels() (err error) {
 	labels := cp.imgConfig.Labels
 	var text []byte
 
+	if len(cp.annotations) > 0 {
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, v := range cp.annotations {
+			// Labels from the image config take precedence.
+			if _, ok := labels[k]; ok {
+				sylog.Debugf("Not importing annotation %s, a label with the same key exists", k)
+				continue
+			}
+			sylog.Verbosef("Importing annotation %s=%s", k, v)
+			labels[k] = v
+		}
+	}
+
+	if cp.b.Opts.DetectOS {
+		osr, err := client.ReadOSRelease(cp.b.RootfsPath)
+		if err != nil {
+			sylog.Warningf("Could not read os-release of image: %v", err)
+			osr = client.OSRelease{ID: client.UnknownOS}
+		}
+		sylog.Verbosef("Detected OS %s", osr)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, v := range osr.Labels() {
+			if _, ok := labels[k]; ok {
+				sylog.Debugf("Not recording OS label %s, a label with the same key exists", k)
+				continue
+			}
+			labels[k] = v
+		}
+	}
+
 	// make new map into json
 	text, err = json.MarshalIndent(labels, "", "\t")
 	if err != nil {
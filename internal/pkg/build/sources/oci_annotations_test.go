@@ -0,0 +1,177 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	sytypes "github.com/sylabs/singularity/pkg/build/types"
+)
+
+var testAnnotations = map[string]string{
+	imgspecv1.AnnotationCreated:  "2023-01-01T00:00:00Z",
+	imgspecv1.AnnotationRevision: "abc123",
+	imgspecv1.AnnotationSource:   "https://github.com/sylabs/singularity",
+	"com.example.other":          "value",
+}
+
+func TestSelectAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		expected map[string]string
+	}{
+		{
+			name:     "none",
+			keys:     []string{"com.example.missing"},
+			expected: map[string]string{},
+		},
+		{
+			name: "exact",
+			keys: []string{imgspecv1.AnnotationRevision, imgspecv1.AnnotationSource},
+			expected: map[string]string{
+				imgspecv1.AnnotationRevision: "abc123",
+				imgspecv1.AnnotationSource:   "https://github.com/sylabs/singularity",
+			},
+		},
+		{
+			name: "prefix",
+			keys: []string{"org.opencontainers.image.*"},
+			expected: map[string]string{
+				imgspecv1.AnnotationCreated:  "2023-01-01T00:00:00Z",
+				imgspecv1.AnnotationRevision: "abc123",
+				imgspecv1.AnnotationSource:   "https://github.com/sylabs/singularity",
+			},
+		},
+		{
+			name:     "all",
+			keys:     []string{"all"},
+			expected: testAnnotations,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectAnnotations(testAnnotations, tt.keys)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManifestAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest imgspecv1.Manifest
+		expected map[string]string
+	}{
+		{
+			name:     "none",
+			expected: map[string]string{},
+		},
+		{
+			name: "manifest",
+			manifest: imgspecv1.Manifest{
+				Annotations: map[string]string{imgspecv1.AnnotationSource: "manifest"},
+			},
+			expected: map[string]string{imgspecv1.AnnotationSource: "manifest"},
+		},
+		{
+			name: "config",
+			manifest: imgspecv1.Manifest{
+				Config: imgspecv1.Descriptor{
+					Annotations: map[string]string{imgspecv1.AnnotationSource: "config"},
+				},
+			},
+			expected: map[string]string{imgspecv1.AnnotationSource: "config"},
+		},
+		{
+			name: "manifest over config",
+			manifest: imgspecv1.Manifest{
+				Config: imgspecv1.Descriptor{
+					Annotations: map[string]string{
+						imgspecv1.AnnotationSource:  "config",
+						imgspecv1.AnnotationVersion: "config",
+					},
+				},
+				Annotations: map[string]string{
+					imgspecv1.AnnotationSource:   "manifest",
+					imgspecv1.AnnotationRevision: "manifest",
+				},
+			},
+			expected: map[string]string{
+				imgspecv1.AnnotationSource:   "manifest",
+				imgspecv1.AnnotationRevision: "manifest",
+				imgspecv1.AnnotationVersion:  "config",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := manifestAnnotations(tt.manifest)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("got %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestInsertOCILabelsAnnotations checks that annotations imported from the
+// manifest and its config descriptor round-trip into the labels of the
+// container, without overriding image labels.
+func TestInsertOCILabelsAnnotations(t *testing.T) {
+	rootfs := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rootfs, ".singularity.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cp := &OCIConveyorPacker{
+		b: &sytypes.Bundle{RootfsPath: rootfs},
+		imgConfig: imgspecv1.ImageConfig{
+			Labels: map[string]string{imgspecv1.AnnotationRevision: "from-label"},
+		},
+	}
+	manifest := imgspecv1.Manifest{
+		Config: imgspecv1.Descriptor{
+			Annotations: map[string]string{
+				imgspecv1.AnnotationSource:  "from-config",
+				imgspecv1.AnnotationVersion: "from-config",
+			},
+		},
+		Annotations: testAnnotations,
+	}
+	cp.annotations = selectAnnotations(manifestAnnotations(manifest), []string{"org.opencontainers.image.*"})
+
+	if err := cp.insertOCILabels(); err != nil {
+		t.Fatalf("while inserting labels: %v", err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(rootfs, ".singularity.d", "labels.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := make(map[string]string)
+	if err := json.Unmarshal(b, &labels); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		imgspecv1.AnnotationCreated:  "2023-01-01T00:00:00Z",
+		imgspecv1.AnnotationRevision: "from-label",
+		imgspecv1.AnnotationSource:   "https://github.com/sylabs/singularity",
+		imgspecv1.AnnotationVersion:  "from-config",
+	}
+	if !reflect.DeepEqual(labels, expected) {
+		t.Errorf("got labels %v, expected %v", labels, expected)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	units "github.com/docker/go-units"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// excludePaths removes the files and directories of rootfs whose absolute
+// path in rootfs matches one of patterns, as with path.Match. A matching
+// directory is removed with its content. A match that a remaining symlink
+// points to, or into, is kept, so that the symlink isn't broken. A warning is
+// logged for each pattern matching nothing. The number of bytes of the
+// regular files removed is returned.
+func excludePaths(rootfs string, patterns []string) (int64, error) {
+	hits := make(map[string]int, len(patterns))
+	var matches []string
+	// links maps the path of each symlink to the path it points to.
+	links := make(map[string]string)
+
+	err := filepath.WalkDir(rootfs, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(rootfs, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		abs := "/" + filepath.ToSlash(rel)
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if !path.IsAbs(target) {
+				target = path.Join(path.Dir(abs), target)
+			}
+			links[abs] = path.Clean(target)
+		}
+
+		// The content of a matching directory is walked for its symlinks
+		// only, as it is removed with the directory.
+		if n := len(matches); n > 0 && within(abs, matches[n-1]) {
+			return nil
+		}
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, abs); ok {
+				hits[pattern]++
+				matches = append(matches, abs)
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("while matching excluded paths: %v", err)
+	}
+
+	for _, pattern := range patterns {
+		if hits[pattern] == 0 {
+			sylog.Warningf("Excluded path %s matches nothing in the image", pattern)
+		}
+	}
+
+	// A match is kept if a symlink outside of the matches removed points to,
+	// or into, it. Keeping a match keeps the symlinks it holds, so this is
+	// repeated until no more matches are kept.
+	kept := make(map[string]string)
+	for changed := true; changed; {
+		changed = false
+		for _, m := range matches {
+			if _, ok := kept[m]; ok {
+				continue
+			}
+			for link, target := range links {
+				if within(target, m) && !removedWith(link, matches, kept) {
+					kept[m] = link
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var saved int64
+	removed := 0
+	errors := 0
+	for _, m := range matches {
+		if link, ok := kept[m]; ok {
+			sylog.Warningf("Not excluding %s, the target of symlink %s", m, link)
+			continue
+		}
+
+		p := filepath.Join(rootfs, filepath.FromSlash(m))
+		size, err := regularSize(p)
+		if err != nil {
+			sylog.Errorf("Unable to access excluded path %s: %s", m, err)
+			errors++
+			continue
+		}
+		if err := os.RemoveAll(p); err != nil {
+			sylog.Errorf("Error removing excluded path %s: %s", m, err)
+			errors++
+			continue
+		}
+		sylog.Debugf("Excluded %s (%d bytes)", m, size)
+		saved += size
+		removed++
+	}
+
+	if errors > 0 {
+		return saved, fmt.Errorf("%d errors were encountered when removing excluded paths", errors)
+	}
+	sylog.Infof("Excluded %d paths from the image, saving %s", removed, units.BytesSize(float64(saved)))
+	return saved, nil
+}
+
+// within reports whether p is the path dir, or a path under dir.
+func within(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// removedWith reports whether p is removed with one of matches, not kept.
+func removedWith(p string, matches []string, kept map[string]string) bool {
+	for _, m := range matches {
+		if _, ok := kept[m]; !ok && within(p, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// regularSize returns the total size of the regular files at, or under, p.
+func regularSize(p string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += fi.Size()
+		}
+		return nil
+	})
+	return size, err
+}
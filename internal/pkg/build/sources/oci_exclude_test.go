@@ -0,0 +1,64 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcludePaths(t *testing.T) {
+	rootfs := t.TempDir()
+
+	files := map[string]string{
+		"usr/share/doc/a/README":       "0123456789",
+		"usr/share/doc/b/README":       "01234",
+		"usr/share/locale/en/messages": "0123",
+		"usr/share/locale/fr/messages": "012",
+		"var/cache/apt/pkgcache.bin":   "01234567",
+		"bin/tool":                     "0",
+	}
+	for p, content := range files {
+		p = filepath.Join(rootfs, p)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A symlink kept in the image needs the English locale.
+	if err := os.Symlink("../share/locale/en", filepath.Join(rootfs, "usr/share/doc/a/locale")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(rootfs, "etc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/usr/share/locale/en/messages", filepath.Join(rootfs, "etc/messages")); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns := []string{"/usr/share/doc", "/usr/share/locale/*", "/var/cache/*/*.bin", "/opt/*"}
+	saved, err := excludePaths(rootfs, patterns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := int64(10 + 5 + 3 + 8); saved != want {
+		t.Errorf("got %d bytes saved, want %d", saved, want)
+	}
+
+	for _, p := range []string{"usr/share/doc", "usr/share/locale/fr", "var/cache/apt/pkgcache.bin"} {
+		if _, err := os.Lstat(filepath.Join(rootfs, p)); !os.IsNotExist(err) {
+			t.Errorf("%s not excluded", p)
+		}
+	}
+	for _, p := range []string{"usr/share/locale/en/messages", "var/cache/apt", "bin/tool", "etc/messages"} {
+		if _, err := os.Lstat(filepath.Join(rootfs, p)); err != nil {
+			t.Errorf("%s unexpectedly excluded: %v", p, err)
+		}
+	}
+}
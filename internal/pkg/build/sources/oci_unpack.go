@@ -10,6 +10,7 @@
 package sources
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -25,6 +26,7 @@ import (
 	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	sytypes "github.com/sylabs/singularity/pkg/build/types"
 	"github.com/sylabs/singularity/pkg/sylog"
+	"golang.org/x/sys/unix"
 )
 
 // unpackRootfs extracts all of the layers of the given image reference into the rootfs of the provided bundle
@@ -95,6 +97,27 @@ func unpackRootfs(ctx context.Context, b *sytypes.Bundle, tmpfsRef types.ImageRe
 		return fmt.Errorf("error unpacking rootfs: %s", err)
 	}
 
+	if len(b.Opts.ExcludePaths) > 0 {
+		sylog.Debugf("Removing excluded paths from rootfs")
+		if _, err := excludePaths(b.RootfsPath, b.Opts.ExcludePaths); err != nil {
+			return err
+		}
+	}
+
+	if b.Opts.NoXattrs {
+		sylog.Debugf("Removing extended attributes from rootfs")
+		if err := stripXattrs(b.RootfsPath); err != nil {
+			return err
+		}
+	}
+
+	if b.Opts.NormalizePerms {
+		sylog.Debugf("Normalizing permissions of rootfs")
+		if err := normalizePerms(b.RootfsPath); err != nil {
+			return err
+		}
+	}
+
 	// If the `--fix-perms` flag was used, then modify the permissions so that
 	// content has owner rwX and we're done
 	if b.Opts.FixPerms {
@@ -153,6 +176,102 @@ func fixPerms(rootfs string) (err error) {
 	return err
 }
 
+// stripXattrs will work through the rootfs of this bundle, removing all
+// extended attributes from files, directories and symlinks.
+func stripXattrs(rootfs string) (err error) {
+	errors := 0
+	err = fs.PermWalk(rootfs, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			sylog.Errorf("Unable to access rootfs path %s: %s", path, err)
+			errors++
+			return nil
+		}
+
+		names, err := listXattrs(path)
+		if err != nil {
+			sylog.Errorf("Error listing extended attributes of %s: %s", path, err)
+			errors++
+			return nil
+		}
+		for _, name := range names {
+			if err := unix.Lremovexattr(path, name); err != nil {
+				sylog.Errorf("Error removing extended attribute %s of %s: %s", name, path, err)
+				errors++
+			}
+		}
+		return nil
+	})
+
+	if errors > 0 {
+		err = fmt.Errorf("%d errors were encountered when removing extended attributes", errors)
+	}
+	return err
+}
+
+// listXattrs returns the names of the extended attributes of path, without
+// following symlinks.
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err == unix.ENOTSUP {
+		return nil, nil
+	}
+	if err != nil || size == 0 {
+		return nil, err
+	}
+
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+// normalizePerms will work through the rootfs of this bundle, setting
+// directories and files with any execute bit to 0755, and other files to
+// 0644. This removes setuid, setgid and sticky bits, as well as group and
+// other write permission. Symlinks and special files are left untouched.
+func normalizePerms(rootfs string) (err error) {
+	errors := 0
+	err = fs.PermWalk(rootfs, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			sylog.Errorf("Unable to access rootfs path %s: %s", path, err)
+			errors++
+			return nil
+		}
+
+		var perm os.FileMode
+		switch mode := f.Mode(); {
+		case mode.IsDir():
+			perm = 0o755
+		case mode.IsRegular() && mode.Perm()&0o111 != 0:
+			perm = 0o755
+		case mode.IsRegular():
+			perm = 0o644
+		default:
+			return nil
+		}
+
+		if err := os.Chmod(path, perm); err != nil {
+			sylog.Errorf("Error setting permission for %s: %s", path, err)
+			errors++
+		}
+		return nil
+	})
+
+	if errors > 0 {
+		err = fmt.Errorf("%d errors were encountered when normalizing permissions", errors)
+	}
+	return err
+}
+
 // checkPerms will work through the rootfs of this bundle, and find if any
 // directory does not have owner rwX - which may cause unexpected issues for a
 // user trying to look through, or delete a sandbox
@@ -0,0 +1,110 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// makeFixtureRootfs creates a small rootfs, with quirky permissions and
+// extended attributes as found in some images built on Windows.
+func makeFixtureRootfs(t *testing.T) string {
+	rootfs := t.TempDir()
+
+	files := []struct {
+		path string
+		mode os.FileMode
+		dir  bool
+	}{
+		{path: "bin", mode: 0o700, dir: true},
+		{path: "bin/tool", mode: 0o777},
+		{path: "bin/suid", mode: 0o755 | os.ModeSetuid},
+		{path: "etc", mode: 0o777 | os.ModeSticky, dir: true},
+		{path: "etc/config", mode: 0o666},
+		{path: "etc/secret", mode: 0o600},
+	}
+	for _, f := range files {
+		p := filepath.Join(rootfs, f.path)
+		if f.dir {
+			if err := os.Mkdir(p, 0o700); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.WriteFile(p, []byte("content"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(p, f.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("tool", filepath.Join(rootfs, "bin", "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	return rootfs
+}
+
+func TestStripXattrs(t *testing.T) {
+	rootfs := makeFixtureRootfs(t)
+
+	paths := []string{
+		filepath.Join(rootfs, "bin"),
+		filepath.Join(rootfs, "etc", "config"),
+	}
+	for _, p := range paths {
+		err := unix.Lsetxattr(p, "user.test", []byte("value"), 0)
+		if err == unix.ENOTSUP {
+			t.Skipf("user extended attributes not supported in %s", rootfs)
+		}
+		if err != nil {
+			t.Fatalf("while setting extended attribute on %s: %v", p, err)
+		}
+	}
+
+	if err := stripXattrs(rootfs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, p := range paths {
+		names, err := listXattrs(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(names) > 0 {
+			t.Errorf("%s still has extended attributes %v", p, names)
+		}
+	}
+}
+
+func TestNormalizePerms(t *testing.T) {
+	rootfs := makeFixtureRootfs(t)
+
+	if err := normalizePerms(rootfs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]os.FileMode{
+		"bin":        os.ModeDir | 0o755,
+		"bin/tool":   0o755,
+		"bin/suid":   0o755,
+		"etc":        os.ModeDir | 0o755,
+		"etc/config": 0o644,
+		"etc/secret": 0o644,
+		"bin/link":   os.ModeSymlink | 0o777,
+	}
+	for p, mode := range want {
+		fi, err := os.Lstat(filepath.Join(rootfs, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode() != mode {
+			t.Errorf("%s: got mode %v, want %v", p, fi.Mode(), mode)
+		}
+	}
+}
@@ -13,11 +13,14 @@ import (
 	"path"
 	"path/filepath"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/pkg/syfs"
 	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/util/fs/lock"
 )
 
 var errInvalidCacheType = errors.New("invalid cache type")
@@ -46,6 +49,9 @@ const (
 	OrasCacheType = "oras"
 	// NetCacheType specifies the cache holds images pulled from http(s) internet sources
 	NetCacheType = "net"
+	// CompletionCacheType specifies the cache holds data cached briefly for
+	// shell completion, e.g. the tags of repositories
+	CompletionCacheType = "completion"
 )
 
 var (
@@ -84,6 +90,9 @@ type Handle struct {
 	rootDir string
 	// If the cache is disabled
 	disabled bool
+	// hits and misses count the entries found, and not found, by lookups.
+	hits   atomic.Int64
+	misses atomic.Int64
 }
 
 func (h *Handle) GetFileCacheDir(cacheType string) (cacheDir string, err error) {
@@ -102,6 +111,18 @@ func (h *Handle) GetOciCacheDir(cacheType string) (cacheDir string, err error) {
 
 // GetEntry returns a cache Entry for a specified file cache type and hash
 func (h *Handle) GetEntry(cacheType string, hash string) (e *Entry, err error) {
+	e, err = h.getEntry(cacheType, hash)
+	if e != nil {
+		if e.Exists {
+			h.hits.Add(1)
+		} else {
+			h.misses.Add(1)
+		}
+	}
+	return e, err
+}
+
+func (h *Handle) getEntry(cacheType string, hash string) (e *Entry, err error) {
 	if h.disabled {
 		return nil, nil
 	}
@@ -135,12 +156,18 @@ func (h *Handle) GetEntry(cacheType string, hash string) (e *Entry, err error) {
 
 	if !pathExists {
 		e.Exists = false
+		// The entry holds a shared lock on the cache until it is finalized or
+		// cleaned, so that a garbage collection doesn't run while it is
+		// written.
+		e.unlock = h.LockShared()
 		f, err := fs.MakeTmpFile(cacheDir, "tmp_", 0o700)
 		if err != nil {
+			e.release()
 			return nil, err
 		}
 		err = f.Close()
 		if err != nil {
+			e.release()
 			return nil, err
 		}
 		e.TmpPath = f.Name()
@@ -157,6 +184,38 @@ func (h *Handle) GetEntry(cacheType string, hash string) (e *Entry, err error) {
 	return e, nil
 }
 
+// LockShared takes a shared lock on the cache, excluding a concurrent garbage
+// collection, and returns the function releasing it. New entries hold it
+// until finalized, other writes to the cache (e.g. the OCI blobs copied by
+// containers/image) must hold it while they write. Writes still proceed,
+// with a warning, where locks are not supported.
+func (h *Handle) LockShared() (release func()) {
+	if h.disabled {
+		return func() {}
+	}
+	fd, err := lock.Shared(h.rootDir)
+	if err != nil {
+		sylog.Warningf("Could not lock cache directory %s, a concurrent cache gc is not excluded: %v", h.rootDir, err)
+		return func() {}
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if err := lock.Release(fd); err != nil {
+				sylog.Debugf("Could not release cache lock: %v", err)
+			}
+		})
+	}
+}
+
+// Stats returns the number of entries found, and not found, by the lookups
+// of the cache made with h so far. A reference to an entry no longer in the
+// cache is not counted as a miss, as the entry is looked up again when the
+// image is pulled.
+func (h *Handle) Stats() (hits, misses int64) {
+	return h.hits.Load(), h.misses.Load()
+}
+
 func (h *Handle) CleanCache(cacheType string, dryRun bool, days int) (err error) {
 	dir := h.getCacheTypeDir(cacheType)
 
@@ -200,6 +259,15 @@ func (h *Handle) CleanCache(cacheType string, dryRun bool, days int) (err error)
 	return err
 }
 
+// GetCompletionCacheDir returns the directory holding data cached briefly for
+// shell completion, or "" if the cache is disabled.
+func (h *Handle) GetCompletionCacheDir() string {
+	if h.disabled {
+		return ""
+	}
+	return h.getCacheTypeDir(CompletionCacheType)
+}
+
 // IsDisabled returns true if the cache is disabled
 func (h *Handle) IsDisabled() bool {
 	return h.disabled
@@ -0,0 +1,53 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	const ref = "docker://alpine:latest"
+	const hash = "sha256.1234"
+
+	check := func(wantHits, wantMisses int64) {
+		t.Helper()
+		if hits, misses := h.Stats(); hits != wantHits || misses != wantMisses {
+			t.Errorf("got %d hits and %d misses, want %d and %d", hits, misses, wantHits, wantMisses)
+		}
+	}
+
+	// A reference to an entry not in the cache is not a miss.
+	if err := h.PutReference(OciTempCacheType, ref, hash); err != nil {
+		t.Fatalf("while recording reference: %v", err)
+	}
+	if _, err := h.GetReferenceEntry(OciTempCacheType, ref); err != nil {
+		t.Fatal(err)
+	}
+	check(0, 0)
+
+	e, err := h.GetEntry(OciTempCacheType, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(0, 1)
+	if err := e.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.GetEntry(OciTempCacheType, hash); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.GetReferenceEntry(OciTempCacheType, ref); err != nil {
+		t.Fatal(err)
+	}
+	check(2, 1)
+}
@@ -27,6 +27,9 @@ type Entry struct {
 	// tmpPath is the temporary location that should be used for a new cache entry as it
 	// is created
 	TmpPath string
+	// unlock releases the shared lock on the cache taken for a new entry,
+	// once it is finalized or cleaned.
+	unlock func()
 }
 
 // Finalize an entry by renaming it to its permanent path atomically
@@ -36,6 +39,7 @@ func (e *Entry) Finalize() error {
 	//   If newpath already exists and is not a directory, Rename replaces it.
 	//   https://golang.org/pkg/os/#Rename
 	err := os.Rename(e.TmpPath, e.Path)
+	e.release()
 	if err != nil {
 		return fmt.Errorf("could not finalize cached file: %v", err)
 	}
@@ -44,6 +48,7 @@ func (e *Entry) Finalize() error {
 
 // CleanTmp should be defer'd when an Entry is created and will remove any temporary file
 func (e *Entry) CleanTmp() {
+	defer e.release()
 	// If there is no TmpPath / file there then there is nothing to clean up
 	if e.TmpPath == "" || !fs.IsFile(e.TmpPath) {
 		return
@@ -53,3 +58,10 @@ func (e *Entry) CleanTmp() {
 		sylog.Errorf("Could not remove cache temporary file '%s': %v", e.TmpPath, err)
 	}
 }
+
+// release releases the shared lock on the cache held by a new entry.
+func (e *Entry) release() {
+	if e.unlock != nil {
+		e.unlock()
+	}
+}
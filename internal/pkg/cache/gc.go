@@ -0,0 +1,234 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/util/fs/lock"
+)
+
+// gcFile is a regular file found in the cache that is a candidate for
+// deduplication.
+type gcFile struct {
+	path  string
+	size  int64
+	dev   uint64
+	ino   uint64
+	nlink uint64
+	mtime int64
+}
+
+// gcInode identifies the inode of a gcFile.
+type gcInode struct {
+	dev uint64
+	ino uint64
+}
+
+// GCResult summarizes a garbage collection of the cache.
+type GCResult struct {
+	// Duplicates is the number of files replaced by a hardlink.
+	Duplicates int
+	// Reclaimed is the number of bytes freed by deduplication, the size of
+	// the files of which no other link remains.
+	Reclaimed int64
+}
+
+// GarbageCollect finds files with identical content across the cache types
+// (library, oci-tmp, shub, oras, net and OCI blobs) and replaces duplicates
+// with hardlinks to a single copy. When dryRun is true, duplicates are only
+// reported. An exclusive lock is held on the cache root, while a pull holds a
+// shared lock on it until its entry is finalized, or its OCI blobs are copied
+// (see LockShared), so that neither runs while the other modifies the cache.
+// Each file is re-checked just before it is replaced, and replaced with an
+// atomic rename, so readers always see a complete entry.
+func (h *Handle) GarbageCollect(dryRun bool) (GCResult, error) {
+	var res GCResult
+
+	if h.disabled {
+		return res, fmt.Errorf("cache is disabled")
+	}
+
+	fd, err := lock.Exclusive(h.rootDir)
+	if err != nil {
+		return res, fmt.Errorf("could not lock cache directory %s: %v", h.rootDir, err)
+	}
+	defer lock.Release(fd)
+
+	files, err := h.gcCandidates()
+	if err != nil {
+		return res, err
+	}
+
+	// Only files sharing a size can have identical content, so we avoid
+	// hashing the others.
+	bySize := make(map[int64][]gcFile)
+	for _, f := range files {
+		bySize[f.size] = append(bySize[f.size], f)
+	}
+
+	// replaced counts the replaced links of each inode, which is freed once
+	// all of its links are replaced.
+	replaced := make(map[gcInode]uint64)
+
+	for size, sized := range bySize {
+		if len(sized) < 2 || size == 0 {
+			continue
+		}
+
+		byDigest := make(map[string][]gcFile)
+		for _, f := range sized {
+			d, err := fileDigest(f.path)
+			if err != nil {
+				sylog.Warningf("Could not compute digest of %s: %v", f.path, err)
+				continue
+			}
+			byDigest[d] = append(byDigest[d], f)
+		}
+
+		for d, same := range byDigest {
+			canonical := same[0]
+			for _, f := range same[1:] {
+				if f.dev == canonical.dev && f.ino == canonical.ino {
+					// already hardlinked
+					continue
+				}
+				if f.dev != canonical.dev {
+					sylog.Debugf("Skipping %s: not on the same filesystem as %s", f.path, canonical.path)
+					continue
+				}
+
+				sylog.Infof("Deduplicating %s (%s)", f.path, d)
+				if !dryRun {
+					if err := replaceWithLink(canonical, f); err != nil {
+						sylog.Warningf("Could not deduplicate %s: %v", f.path, err)
+						continue
+					}
+				}
+				res.Duplicates++
+				i := gcInode{dev: f.dev, ino: f.ino}
+				replaced[i]++
+				if replaced[i] == f.nlink {
+					res.Reclaimed += f.size
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// gcCandidates returns all finalized regular files held in the cache.
+func (h *Handle) gcCandidates() ([]gcFile, error) {
+	var files []gcFile
+
+	dirs := make([]string, 0, len(FileCacheTypes)+1)
+	for _, t := range FileCacheTypes {
+		dirs = append(dirs, h.getCacheTypeDir(t))
+	}
+	// Only the blobs of the OCI layout are content, index.json etc. are
+	// metadata that must not be touched.
+	dirs = append(dirs, filepath.Join(h.getCacheTypeDir(OciBlobCacheType), "blobs"))
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			// Skip temporary files of in-progress pulls.
+			if !d.Type().IsRegular() || strings.HasPrefix(d.Name(), "tmp_") {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			st, ok := fi.Sys().(*syscall.Stat_t)
+			if !ok {
+				return nil
+			}
+			files = append(files, gcFile{
+				path:  path,
+				size:  fi.Size(),
+				dev:   uint64(st.Dev),
+				ino:   st.Ino,
+				nlink: uint64(st.Nlink),
+				mtime: fi.ModTime().UnixNano(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("while walking %s: %v", dir, err)
+		}
+	}
+
+	return files, nil
+}
+
+// replaceWithLink atomically replaces f with a hardlink to canonical, unless
+// either has been modified since it was examined.
+func replaceWithLink(canonical, f gcFile) error {
+	if err := checkUnchanged(canonical); err != nil {
+		return err
+	}
+	if err := checkUnchanged(f); err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(filepath.Dir(f.path), "tmp_gc_"+filepath.Base(f.path))
+	if err := os.Link(canonical.path, tmp); err != nil {
+		return err
+	}
+	// The link must be to the inode that was examined.
+	if err := checkUnchanged(gcFile{path: tmp, size: canonical.size, ino: canonical.ino, mtime: canonical.mtime}); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// checkUnchanged returns an error if the file at the path of f is not the
+// inode examined as f, or was modified since.
+func checkUnchanged(f gcFile) error {
+	fi, err := os.Lstat(f.path)
+	if err != nil {
+		return err
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); !ok || st.Ino != f.ino || fi.Size() != f.size || fi.ModTime().UnixNano() != f.mtime {
+		return fmt.Errorf("%s changed during garbage collection", f.path)
+	}
+	return nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
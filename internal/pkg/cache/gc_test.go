@@ -0,0 +1,289 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func inode(t *testing.T, path string) uint64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("while stating %s: %v", path, err)
+	}
+	return fi.Sys().(*syscall.Stat_t).Ino
+}
+
+func TestGarbageCollect(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	blobDir := filepath.Join(h.getCacheTypeDir(OciBlobCacheType), "blobs", "sha256")
+	if err := os.MkdirAll(blobDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	content := []byte("duplicate content")
+	dups := []string{
+		filepath.Join(h.getCacheTypeDir(LibraryCacheType), "sha256.aaa"),
+		filepath.Join(h.getCacheTypeDir(OrasCacheType), "sha256.bbb"),
+		filepath.Join(blobDir, "ccc"),
+	}
+	for _, p := range dups {
+		if err := os.WriteFile(p, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Same size, different content: must not be linked.
+	other := filepath.Join(h.getCacheTypeDir(NetCacheType), "ddd")
+	if err := os.WriteFile(other, []byte("different content"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// In-progress pull: must be ignored.
+	tmp := filepath.Join(h.getCacheTypeDir(NetCacheType), "tmp_123")
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := h.GarbageCollect(true)
+	if err != nil {
+		t.Fatalf("unexpected error in dry run: %v", err)
+	}
+	if res.Duplicates != 2 || res.Reclaimed != int64(2*len(content)) {
+		t.Errorf("unexpected dry run result: %+v", res)
+	}
+	if inode(t, dups[0]) == inode(t, dups[1]) {
+		t.Errorf("dry run modified the cache")
+	}
+
+	res, err = h.GarbageCollect(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Duplicates != 2 || res.Reclaimed != int64(2*len(content)) {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	ino := inode(t, dups[0])
+	for _, p := range dups[1:] {
+		if inode(t, p) != ino {
+			t.Errorf("%s was not deduplicated", p)
+		}
+		b, err := os.ReadFile(p)
+		if err != nil || string(b) != string(content) {
+			t.Errorf("%s content altered: %q (%v)", p, b, err)
+		}
+	}
+	if inode(t, other) == ino || inode(t, tmp) == ino {
+		t.Errorf("unrelated file was linked")
+	}
+
+	// A second run has nothing left to do.
+	res, err = h.GarbageCollect(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Duplicates != 0 {
+		t.Errorf("unexpected duplicates on second run: %+v", res)
+	}
+}
+
+// TestGarbageCollectReclaimed checks that the size of a replaced file is only
+// reclaimed when no other link to it remains.
+func TestGarbageCollectReclaimed(t *testing.T) {
+	parent := t.TempDir()
+	h, err := New(Config{ParentDir: parent})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	content := []byte("duplicate content")
+	canonical := filepath.Join(h.getCacheTypeDir(LibraryCacheType), "sha256.aaa")
+	single := filepath.Join(h.getCacheTypeDir(OrasCacheType), "sha256.bbb")
+	linked := filepath.Join(h.getCacheTypeDir(NetCacheType), "ccc")
+	for _, p := range []string{canonical, single, linked} {
+		if err := os.WriteFile(p, content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A link outside of the cache keeps the content of linked.
+	if err := os.Link(linked, filepath.Join(parent, "outside")); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := h.GarbageCollect(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Duplicates != 2 || res.Reclaimed != int64(len(content)) {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+// TestGarbageCollectLock checks that a garbage collection waits for the
+// entries being written to be finalized.
+func TestGarbageCollectLock(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	content := []byte("duplicate content")
+	existing := filepath.Join(h.getCacheTypeDir(LibraryCacheType), "sha256.aaa")
+	if err := os.WriteFile(existing, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := h.GetEntry(NetCacheType, "bbb")
+	if err != nil {
+		t.Fatalf("while getting entry: %v", err)
+	}
+	defer e.CleanTmp()
+
+	done := make(chan GCResult, 1)
+	go func() {
+		res, err := h.GarbageCollect(false)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		done <- res
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("garbage collection ran while an entry was written")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if err := os.WriteFile(e.TmpPath, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Finalize(); err != nil {
+		t.Fatalf("while finalizing entry: %v", err)
+	}
+
+	select {
+	case res := <-done:
+		if res.Duplicates != 1 {
+			t.Errorf("finalized entry was not deduplicated: %+v", res)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("garbage collection still waiting once the entry was finalized")
+	}
+}
+
+// TestGarbageCollectConcurrentPulls runs garbage collections while entries
+// with the same content are written, and checks that every entry is
+// complete.
+func TestGarbageCollectConcurrentPulls(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	content := bytes.Repeat([]byte("duplicate content"), 4096)
+	const pulls = 50
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < pulls; i++ {
+			e, err := h.GetEntry(NetCacheType, fmt.Sprintf("entry%d", i))
+			if err != nil {
+				t.Errorf("while getting entry: %v", err)
+				return
+			}
+			// Written in two steps, as a download would be.
+			if err := os.WriteFile(e.TmpPath, content[:len(content)/2], 0o600); err == nil {
+				var f *os.File
+				f, err = os.OpenFile(e.TmpPath, os.O_APPEND|os.O_WRONLY, 0)
+				if err == nil {
+					_, err = f.Write(content[len(content)/2:])
+					f.Close()
+				}
+			}
+			if err == nil {
+				err = e.Finalize()
+			}
+			e.CleanTmp()
+			if err != nil {
+				t.Errorf("while writing entry: %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < pulls; i++ {
+			if _, err := h.GarbageCollect(false); err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if _, err := h.GarbageCollect(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ino := inode(t, filepath.Join(h.getCacheTypeDir(NetCacheType), "entry0"))
+	for i := 0; i < pulls; i++ {
+		p := filepath.Join(h.getCacheTypeDir(NetCacheType), fmt.Sprintf("entry%d", i))
+		b, err := os.ReadFile(p)
+		if err != nil || !bytes.Equal(b, content) {
+			t.Errorf("%s is incomplete (%d bytes): %v", p, len(b), err)
+		}
+		if inode(t, p) != ino {
+			t.Errorf("%s was not deduplicated", p)
+		}
+	}
+}
+
+// TestGarbageCollectWaitsForLock checks that a garbage collection waits for
+// a shared lock taken with LockShared, as when OCI blobs are copied.
+func TestGarbageCollectWaitsForLock(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	release := h.LockShared()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := h.GarbageCollect(false); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("garbage collection ran while the cache was locked")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	release()
+	// Releasing twice is harmless.
+	release()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("garbage collection still waiting once the lock was released")
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sylabs/singularity/internal/pkg/util/fs"
+)
+
+// RefsDirName is the name of the directory, relative to the cache root, that
+// records the hash each reference last resolved to, per cache type.
+const RefsDirName = "refs"
+
+// refPath returns the location of the record for ref under cacheType.
+func (h *Handle) refPath(cacheType, ref string) (string, error) {
+	if !stringInSlice(cacheType, FileCacheTypes) {
+		return "", errInvalidCacheType
+	}
+	sum := sha256.Sum256([]byte(ref))
+	return filepath.Join(h.rootDir, RefsDirName, cacheType, hex.EncodeToString(sum[:])), nil
+}
+
+// PutReference records that ref last resolved to the entry with the given hash
+// in cacheType, so that it can be found later without contacting the remote.
+func (h *Handle) PutReference(cacheType, ref, hash string) error {
+	if h.disabled {
+		return nil
+	}
+
+	p, err := h.refPath(cacheType, ref)
+	if err != nil {
+		return err
+	}
+	if err := initCacheDir(filepath.Dir(p)); err != nil {
+		return err
+	}
+
+	f, err := fs.MakeTmpFile(filepath.Dir(p), "tmp_", 0o600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(hash); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+// GetReferenceEntry returns the existing cache entry that ref last resolved to
+// in cacheType. A nil entry is returned if ref was never recorded, or if the
+// entry is no longer in the cache. The entry may be stale with respect to the
+// remote.
+func (h *Handle) GetReferenceEntry(cacheType, ref string) (*Entry, error) {
+	if h.disabled {
+		return nil, nil
+	}
+
+	p, err := h.refPath(cacheType, ref)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read reference record %s: %v", p, err)
+	}
+
+	hash := strings.TrimSpace(string(b))
+	if hash == "" || strings.ContainsRune(hash, filepath.Separator) {
+		return nil, fmt.Errorf("invalid reference record %s", p)
+	}
+
+	e, err := h.getEntry(cacheType, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !e.Exists {
+		e.CleanTmp()
+		return nil, nil
+	}
+	h.hits.Add(1)
+	return e, nil
+}
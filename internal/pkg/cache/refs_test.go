@@ -0,0 +1,64 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	h, err := New(Config{ParentDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("while creating cache: %v", err)
+	}
+
+	const ref = "docker://alpine:latest"
+	const hash = "sha256.1234"
+
+	e, err := h.GetReferenceEntry(OciTempCacheType, ref)
+	if err != nil || e != nil {
+		t.Fatalf("unexpected entry for unknown reference: %v %v", e, err)
+	}
+
+	if err := h.PutReference(OciTempCacheType, ref, hash); err != nil {
+		t.Fatalf("while recording reference: %v", err)
+	}
+
+	// Recorded, but no entry in the cache yet.
+	e, err = h.GetReferenceEntry(OciTempCacheType, ref)
+	if err != nil || e != nil {
+		t.Fatalf("unexpected entry for uncached hash: %v %v", e, err)
+	}
+	files, _ := os.ReadDir(h.getCacheTypeDir(OciTempCacheType))
+	if len(files) != 0 {
+		t.Errorf("lookup left temporary files in the cache: %v", files)
+	}
+
+	entryPath := filepath.Join(h.getCacheTypeDir(OciTempCacheType), hash)
+	if err := os.WriteFile(entryPath, []byte("sif"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err = h.GetReferenceEntry(OciTempCacheType, ref)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e == nil || !e.Exists || e.Path != entryPath {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	// References are recorded per cache type.
+	e, err = h.GetReferenceEntry(OrasCacheType, ref)
+	if err != nil || e != nil {
+		t.Errorf("unexpected entry for other cache type: %v %v", e, err)
+	}
+
+	if err := h.PutReference("invalid", ref, hash); err == nil {
+		t.Errorf("unexpected success with invalid cache type")
+	}
+}
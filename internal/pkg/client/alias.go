@@ -0,0 +1,141 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Alias is a name given to the image a tag referred to at the time of a pull.
+type Alias struct {
+	// Name is the name of the alias.
+	Name string `json:"name"`
+	// Source is the URI of the image pulled, with its mutable tag.
+	Source string `json:"source"`
+	// Digest is the digest the tag of Source resolved to.
+	Digest string `json:"digest"`
+	// URI is the URI of the image pinned to Digest, which is pulled for the
+	// alias.
+	URI string `json:"uri"`
+	// Created is when the alias was recorded.
+	Created time.Time `json:"created"`
+}
+
+// aliasName is the syntax of alias names. They can't hold ':' or '/', so
+// they are never mistaken for an image URI.
+var aliasName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// CheckAliasName returns an error if name is not a valid alias name.
+func CheckAliasName(name string) error {
+	if !aliasName.MatchString(name) {
+		return fmt.Errorf("invalid alias name %q: must start with a letter or digit, followed by letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+// PinnedURI returns the URI of the image of the library or docker source
+// pinned to digest, in place of its tag.
+func PinnedURI(source, digest string) (string, error) {
+	if digest == "" {
+		return "", fmt.Errorf("no digest for %s", source)
+	}
+	transport, ref, ok := strings.Cut(source, "://")
+	if !ok {
+		return "", fmt.Errorf("%s is not an image URI", source)
+	}
+
+	switch transport {
+	case "library":
+		// A library image is pulled by hash in place of a tag, e.g.
+		// library://alpine:sha256.<hex>.
+		name := ref
+		if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+			name = name[:i]
+		}
+		return "library://" + name + ":" + strings.Replace(digest, ":", ".", 1), nil
+	case "docker":
+		name := ref
+		if i := strings.Index(name, "@"); i >= 0 {
+			name = name[:i]
+		}
+		if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+			name = name[:i]
+		}
+		return "docker://" + name + "@" + strings.Replace(digest, ".", ":", 1), nil
+	}
+	return "", fmt.Errorf("aliases are only supported for library and docker images, not %s", source)
+}
+
+// ReadAliases returns the aliases held by the store at path, by name. A
+// store that doesn't exist holds no aliases.
+func ReadAliases(path string) (map[string]Alias, error) {
+	aliases := make(map[string]Alias)
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Alias
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("invalid alias store %s: %v", path, err)
+	}
+	for _, a := range list {
+		aliases[a.Name] = a
+	}
+	return aliases, nil
+}
+
+// WriteAliases replaces the aliases held by the store at path with aliases,
+// sorted by name. The store is replaced atomically, so a concurrent reader
+// sees either the previous or the new aliases.
+func WriteAliases(path string, aliases map[string]Alias) error {
+	list := make([]Alias, 0, len(aliases))
+	for _, a := range aliases {
+		list = append(list, a)
+	}
+	SortAliases(list)
+
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// SortAliases sorts aliases by name.
+func SortAliases(aliases []Alias) {
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+}
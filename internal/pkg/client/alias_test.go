@@ -0,0 +1,90 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckAliasName(t *testing.T) {
+	for _, name := range []string{"alpine", "alpine-3.17", "my_app.v2", "1"} {
+		if err := CheckAliasName(name); err != nil {
+			t.Errorf("unexpected error for %q: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-alpine", "docker://alpine", "alpine:latest", "library/alpine", "a b"} {
+		if err := CheckAliasName(name); err == nil {
+			t.Errorf("unexpected success for %q", name)
+		}
+	}
+}
+
+func TestPinnedURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		digest  string
+		want    string
+		wantErr bool
+	}{
+		{name: "Docker", source: "docker://alpine", digest: "sha256:abc", want: "docker://alpine@sha256:abc"},
+		{name: "DockerTag", source: "docker://alpine:3.17", digest: "sha256:abc", want: "docker://alpine@sha256:abc"},
+		{name: "DockerDigest", source: "docker://alpine@sha256:def", digest: "sha256:abc", want: "docker://alpine@sha256:abc"},
+		{name: "DockerPort", source: "docker://registry.local:5000/app:1.0", digest: "sha256:abc", want: "docker://registry.local:5000/app@sha256:abc"},
+		{name: "DockerPortNoTag", source: "docker://registry.local:5000/app", digest: "sha256:abc", want: "docker://registry.local:5000/app@sha256:abc"},
+		{name: "Library", source: "library://alpine", digest: "sha256.abc", want: "library://alpine:sha256.abc"},
+		{name: "LibraryTag", source: "library://user/collection/alpine:latest", digest: "sha256.abc", want: "library://user/collection/alpine:sha256.abc"},
+		{name: "NoDigest", source: "docker://alpine", wantErr: true},
+		{name: "Unsupported", source: "oras://ghcr.io/app:1.0", digest: "sha256:abc", wantErr: true},
+		{name: "NotURI", source: "alpine", digest: "sha256:abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PinnedURI(tt.source, tt.digest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAliasStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "aliases.json")
+
+	aliases, err := ReadAliases(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading missing store: %v", err)
+	}
+	if len(aliases) != 0 {
+		t.Fatalf("got %d aliases in missing store, want none", len(aliases))
+	}
+
+	created := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	aliases["web"] = Alias{Name: "web", Source: "docker://nginx:latest", Digest: "sha256:abc", URI: "docker://nginx@sha256:abc", Created: created}
+	aliases["base"] = Alias{Name: "base", Source: "library://alpine", Digest: "sha256.def", URI: "library://alpine:sha256.def", Created: created}
+	if err := WriteAliases(path, aliases); err != nil {
+		t.Fatalf("unexpected error writing store: %v", err)
+	}
+
+	got, err := ReadAliases(path)
+	if err != nil {
+		t.Fatalf("unexpected error reading store: %v", err)
+	}
+	if len(got) != len(aliases) {
+		t.Fatalf("got %d aliases, want %d", len(got), len(aliases))
+	}
+	for name, a := range aliases {
+		if g := got[name]; g.URI != a.URI || g.Digest != a.Digest || !g.Created.Equal(a.Created) {
+			t.Errorf("got alias %+v, want %+v", g, a)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sylabs/singularity/pkg/util/singularityconf"
+)
+
+// dockerHubHosts are the names of Docker Hub, which are all allowed when
+// docker.io is.
+var dockerHubHosts = map[string]bool{
+	"docker.io":               true,
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+}
+
+// NotAllowedError is returned for a pull from a registry, or a library, that
+// is not on an allowlist.
+type NotAllowedError struct {
+	// Host is the registry, or library, host rejected.
+	Host string
+	// Policy tells where the allowlist rejecting Host is set.
+	Policy string
+}
+
+func (e *NotAllowedError) Error() string {
+	return fmt.Sprintf("pulling from %s is not allowed by %s", e.Host, e.Policy)
+}
+
+// CheckRegistry returns a *NotAllowedError if the registry host is not on the
+// "allowed registries" list of singularity.conf, or on allowed, when either is
+// set. A user can restrict the registries allowed by the site further, but
+// can't allow others.
+func CheckRegistry(host string, allowed []string) error {
+	var site []string
+	if conf := singularityconf.GetCurrentConfig(); conf != nil {
+		site = conf.AllowedRegistries
+	}
+	if err := checkHost(host, site, "'allowed registries' in singularity.conf"); err != nil {
+		return err
+	}
+	return checkHost(host, allowed, "--allowed-registry")
+}
+
+// CheckLibraryHost returns a *NotAllowedError if the library host is not on
+// the "allowed library hosts" list of singularity.conf, when set.
+func CheckLibraryHost(host string) error {
+	var site []string
+	if conf := singularityconf.GetCurrentConfig(); conf != nil {
+		site = conf.AllowedLibraryHosts
+	}
+	return checkHost(host, site, "'allowed library hosts' in singularity.conf")
+}
+
+// checkHost returns a *NotAllowedError, for the allowlist set by policy, if
+// allowed is not empty and host is not on it. Hosts are compared without
+// case, with their port if any, and all names of Docker Hub are allowed by
+// docker.io.
+func checkHost(host string, allowed []string, policy string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	h := normalizeHost(host)
+	for _, a := range allowed {
+		if normalizeHost(a) == h {
+			return nil
+		}
+	}
+	return &NotAllowedError{Host: host, Policy: policy}
+}
+
+// normalizeHost returns host in lower case, or docker.io for a name of Docker
+// Hub.
+func normalizeHost(host string) string {
+	h := strings.ToLower(strings.TrimSpace(host))
+	if dockerHubHosts[h] {
+		return "docker.io"
+	}
+	return h
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sylabs/singularity/pkg/util/singularityconf"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		allowed []string
+		wantErr bool
+	}{
+		{name: "NoAllowlist", host: "quay.io"},
+		{name: "Allowed", host: "quay.io", allowed: []string{"ghcr.io", "quay.io"}},
+		{name: "AllowedCase", host: "Quay.IO", allowed: []string{"quay.io"}},
+		{name: "AllowedPort", host: "registry.local:5000", allowed: []string{"registry.local:5000"}},
+		{name: "OtherPort", host: "registry.local:5001", allowed: []string{"registry.local:5000"}, wantErr: true},
+		{name: "DockerHub", host: "docker.io", allowed: []string{"docker.io"}},
+		{name: "DockerHubAlias", host: "index.docker.io", allowed: []string{"docker.io"}},
+		{name: "DockerHubNotAllowed", host: "docker.io", allowed: []string{"quay.io"}, wantErr: true},
+		{name: "NotAllowed", host: "evil.example.com", allowed: []string{"quay.io"}, wantErr: true},
+		{name: "NoSubdomain", host: "mirror.quay.io", allowed: []string{"quay.io"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHost(tt.host, tt.allowed, "test")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			var nae *NotAllowedError
+			if tt.wantErr && (!errors.As(err, &nae) || nae.Host != tt.host) {
+				t.Errorf("got error %v, want a NotAllowedError for %s", err, tt.host)
+			}
+		})
+	}
+}
+
+func TestCheckRegistry(t *testing.T) {
+	conf, err := singularityconf.Parse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.AllowedRegistries = []string{"docker.io", "quay.io"}
+	prev := singularityconf.GetCurrentConfig()
+	singularityconf.SetCurrentConfig(conf)
+	defer singularityconf.SetCurrentConfig(prev)
+
+	if err := CheckRegistry("quay.io", nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckRegistry("ghcr.io", []string{"ghcr.io"}); err == nil {
+		t.Errorf("a user allowlist should not allow a registry the site doesn't")
+	}
+	if err := CheckRegistry("docker.io", []string{"quay.io"}); err == nil {
+		t.Errorf("a user allowlist should restrict the registries allowed by the site")
+	}
+}
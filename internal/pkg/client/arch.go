@@ -0,0 +1,34 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// ImageArch returns the architecture recorded for the root filesystem of the
+// SIF image at path. It is read from the SIF descriptors alone, so that the
+// architecture of an image that can't run on the host can be told.
+func ImageArch(path string) (string, error) {
+	f, err := sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return "", fmt.Errorf("could not load SIF %s: %v", path, err)
+	}
+	defer f.UnloadContainer()
+
+	d, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		return "", fmt.Errorf("no root filesystem in %s: %v", path, err)
+	}
+	_, _, arch, err := d.PartitionMetadata()
+	if err != nil {
+		return "", fmt.Errorf("could not read root filesystem of %s: %v", path, err)
+	}
+	return arch, nil
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+func TestImageArch(t *testing.T) {
+	dir := t.TempDir()
+
+	// The image of a foreign architecture must be readable on the host.
+	foreign := "s390x"
+	if runtime.GOARCH == foreign {
+		foreign = "amd64"
+	}
+	for _, arch := range []string{runtime.GOARCH, foreign} {
+		path := filepath.Join(dir, arch+".sif")
+		part, err := sif.NewDescriptorInput(sif.DataPartition, bytes.NewReader([]byte("rootfs")),
+			sif.OptPartitionMetadata(sif.FsSquash, sif.PartPrimSys, arch),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := sif.CreateContainerAtPath(path, sif.OptCreateWithDescriptors(part))
+		if err != nil {
+			t.Fatalf("while creating SIF: %v", err)
+		}
+		if err := f.UnloadContainer(); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ImageArch(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != arch {
+			t.Errorf("got %q, want %q", got, arch)
+		}
+	}
+
+	empty := filepath.Join(dir, "empty.sif")
+	f, err := sif.CreateContainerAtPath(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.UnloadContainer()
+	if _, err := ImageArch(empty); err == nil {
+		t.Errorf("expected error for a SIF without root filesystem")
+	}
+}
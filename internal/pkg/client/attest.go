@@ -0,0 +1,172 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+const (
+	// StatementType is the type of an in-toto statement.
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	// PullPredicateType is the predicate type of a pull attestation.
+	PullPredicateType = "https://sylabs.io/singularity/pull/v1"
+	// PayloadType is the DSSE payload type of an in-toto statement.
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Statement is an in-toto statement attesting that its subject, a pulled
+// image, was obtained as described by its predicate.
+type Statement struct {
+	Type          string        `json:"_type"`
+	Subject       []Subject     `json:"subject"`
+	PredicateType string        `json:"predicateType"`
+	Predicate     PullPredicate `json:"predicate"`
+}
+
+// Subject identifies an artifact by name and digests.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// PullPredicate describes a pull.
+type PullPredicate struct {
+	// Source is the URI the image was pulled from.
+	Source string `json:"source"`
+	// ResolvedDigest is the digest the source resolved to at the time of
+	// the pull, for sources that have one.
+	ResolvedDigest string `json:"resolvedDigest,omitempty"`
+	// PulledAt is the time the pull completed.
+	PulledAt time.Time `json:"pulledAt"`
+	// SingularityVersion is the version of singularity that pulled the
+	// image.
+	SingularityVersion string `json:"singularityVersion"`
+}
+
+// Envelope is a DSSE envelope holding a signed statement.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of a DSSE envelope. Sig is a binary OpenPGP
+// detached signature of the pre-authentication encoding of the payload.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// NewPullStatement returns a statement attesting that the image at path was
+// pulled from source, resolving to resolvedDigest, at time t.
+func NewPullStatement(path, source, resolvedDigest, version string, t time.Time) (Statement, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Statement{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return Statement{}, fmt.Errorf("while computing digest of %s: %v", path, err)
+	}
+
+	return Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   filepath.Base(path),
+			Digest: map[string]string{"sha256": hex.EncodeToString(h.Sum(nil))},
+		}},
+		PredicateType: PullPredicateType,
+		Predicate: PullPredicate{
+			Source:             source,
+			ResolvedDigest:     resolvedDigest,
+			PulledAt:           t.UTC(),
+			SingularityVersion: version,
+		},
+	}, nil
+}
+
+// pae returns the DSSE pre-authentication encoding of payload.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// SignStatement returns a DSSE envelope holding s, signed with the PGP
+// private key of e, which must be decrypted.
+func SignStatement(s Statement, e *openpgp.Entity) (Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	var sig bytes.Buffer
+	if err := openpgp.DetachSign(&sig, e, bytes.NewReader(pae(PayloadType, payload)), nil); err != nil {
+		return Envelope{}, fmt.Errorf("failed to sign statement: %v", err)
+	}
+
+	return Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{{
+			KeyID: strings.ToUpper(hex.EncodeToString(e.PrimaryKey.Fingerprint)),
+			Sig:   base64.StdEncoding.EncodeToString(sig.Bytes()),
+		}},
+	}, nil
+}
+
+// VerifyEnvelope checks that env holds a statement signed by a key of kr,
+// and returns the statement.
+func VerifyEnvelope(env Envelope, kr openpgp.KeyRing) (Statement, error) {
+	if env.PayloadType != PayloadType {
+		return Statement{}, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return Statement{}, fmt.Errorf("invalid payload: %v", err)
+	}
+	if len(env.Signatures) == 0 {
+		return Statement{}, fmt.Errorf("no signatures in envelope")
+	}
+
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			return Statement{}, fmt.Errorf("invalid signature: %v", err)
+		}
+		if _, err := openpgp.CheckDetachedSignature(kr, bytes.NewReader(pae(env.PayloadType, payload)), bytes.NewReader(sig), nil); err != nil {
+			return Statement{}, fmt.Errorf("signature by %s: %w", s.KeyID, err)
+		}
+	}
+
+	var st Statement
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return Statement{}, fmt.Errorf("invalid statement: %v", err)
+	}
+	return st, nil
+}
+
+// WriteEnvelope writes env as JSON to path.
+func WriteEnvelope(path string, env Envelope) error {
+	b, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
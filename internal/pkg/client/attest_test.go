@@ -0,0 +1,70 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func TestSignStatement(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alpine.sif")
+	if err := os.WriteFile(path, []byte("image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pulledAt := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	st, err := NewPullStatement(path, "docker://alpine", "sha256:abc", "3.11.0", pulledAt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// sha256 of "image".
+	const want = "6105d6cc76af400325e94d588ce511be5bfdbb73b437dc51eca43917d7a43e3d"
+	if got := st.Subject[0].Digest["sha256"]; got != want {
+		t.Errorf("got subject digest %s, want %s", got, want)
+	}
+	if st.Subject[0].Name != "alpine.sif" {
+		t.Errorf("got subject name %s, want alpine.sif", st.Subject[0].Name)
+	}
+
+	e, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := SignStatement(st, e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := VerifyEnvelope(env, openpgp.EntityList{e})
+	if err != nil {
+		t.Fatalf("unexpected verification error: %v", err)
+	}
+	if got.Predicate.Source != "docker://alpine" || !got.Predicate.PulledAt.Equal(pulledAt) {
+		t.Errorf("unexpected predicate %+v", got.Predicate)
+	}
+
+	// Altering the payload invalidates the signature.
+	st.Predicate.Source = "docker://evil"
+	tampered, err := SignStatement(st, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Payload = tampered.Payload
+	if _, err := VerifyEnvelope(env, openpgp.EntityList{e}); err == nil {
+		t.Errorf("unexpected success verifying a tampered envelope")
+	}
+
+	if _, err := base64.StdEncoding.DecodeString(env.Signatures[0].Sig); err != nil {
+		t.Errorf("signature is not base64: %v", err)
+	}
+}
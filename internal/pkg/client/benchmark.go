@@ -0,0 +1,204 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PullBenchmark records the timings of a pull, for pull --benchmark. The
+// DNS lookup, TCP connection, TLS handshake and time to first byte are those
+// of the first request made, from an httptrace.ClientTrace, as later
+// requests reuse its connection, or run in parallel. The download and
+// conversion phases are timed from the progress events of the pull.
+type PullBenchmark struct {
+	mu    sync.Mutex
+	start time.Time
+
+	dnsStart, connectStart, tlsStart, requestStart time.Time
+	dns, connect, tls, firstByte                   time.Duration
+
+	// lastDownload is the time of the last download event, and convert the
+	// start of the conversion, if any.
+	lastDownload, convert time.Time
+	// bytes holds the last reported byte count of each blob.
+	bytes map[string]int64
+}
+
+// NewPullBenchmark returns a PullBenchmark of a pull starting now.
+func NewPullBenchmark() *PullBenchmark {
+	return &PullBenchmark{
+		start: time.Now(),
+		bytes: make(map[string]int64),
+	}
+}
+
+// ClientTrace returns a trace recording the timings of the first request
+// made with a context carrying it.
+func (b *PullBenchmark) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn:              func(string) { b.mark(&b.requestStart) },
+		DNSStart:             func(httptrace.DNSStartInfo) { b.mark(&b.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { b.measure(&b.dnsStart, &b.dns) },
+		ConnectStart:         func(string, string) { b.mark(&b.connectStart) },
+		ConnectDone:          func(string, string, error) { b.measure(&b.connectStart, &b.connect) },
+		TLSHandshakeStart:    func() { b.mark(&b.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { b.measure(&b.tlsStart, &b.tls) },
+		GotFirstResponseByte: func() { b.measure(&b.requestStart, &b.firstByte) },
+	}
+}
+
+// mark sets t to the current time, unless it is set.
+func (b *PullBenchmark) mark(t *time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t.IsZero() {
+		*t = time.Now()
+	}
+}
+
+// measure sets d to the time since start, unless d is set or start is not.
+func (b *PullBenchmark) measure(start *time.Time, d *time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if *d == 0 && !start.IsZero() {
+		*d = time.Since(*start)
+	}
+}
+
+// Observe records ev, a progress event of the pull. It is set as the
+// function of a ProgressReporter with ProgressReporter.Observe.
+func (b *PullBenchmark) Observe(ev ProgressEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch ev.Phase {
+	case PhaseDownload:
+		b.lastDownload = time.Now()
+		b.bytes[ev.Blob] = ev.Bytes
+	case PhaseConvert:
+		if b.convert.IsZero() {
+			b.convert = time.Now()
+		}
+	}
+}
+
+// BenchmarkRun is the report of a pull run by pull --benchmark. Durations
+// are in seconds.
+type BenchmarkRun struct {
+	DNS       float64 `json:"dnsSeconds"`
+	Connect   float64 `json:"connectSeconds"`
+	TLS       float64 `json:"tlsSeconds"`
+	FirstByte float64 `json:"firstByteSeconds"`
+	// Download is the time from the start of the pull to the last byte
+	// downloaded, or 0 if nothing was downloaded.
+	Download float64 `json:"downloadSeconds"`
+	// Convert is the time from the start of the conversion of a docker/OCI
+	// image to the end of the pull, or 0 if there was no conversion.
+	Convert float64 `json:"convertSeconds"`
+	Total   float64 `json:"totalSeconds"`
+	// Bytes is the number of bytes downloaded, and Throughput their rate
+	// over Download, in bytes per second.
+	Bytes       int64   `json:"bytes"`
+	Throughput  float64 `json:"throughputBytesPerSecond"`
+	CacheHits   int64   `json:"cacheHits"`
+	CacheMisses int64   `json:"cacheMisses"`
+}
+
+// Run returns the report of the pull, ending now, during which the cache had
+// the given hits and misses.
+func (b *PullBenchmark) Run(hits, misses int64) BenchmarkRun {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	end := time.Now()
+	r := BenchmarkRun{
+		DNS:         b.dns.Seconds(),
+		Connect:     b.connect.Seconds(),
+		TLS:         b.tls.Seconds(),
+		FirstByte:   b.firstByte.Seconds(),
+		Total:       end.Sub(b.start).Seconds(),
+		CacheHits:   hits,
+		CacheMisses: misses,
+	}
+	for _, n := range b.bytes {
+		r.Bytes += n
+	}
+	if !b.lastDownload.IsZero() {
+		r.Download = b.lastDownload.Sub(b.start).Seconds()
+		if r.Download > 0 {
+			r.Throughput = float64(r.Bytes) / r.Download
+		}
+	}
+	if !b.convert.IsZero() {
+		r.Convert = end.Sub(b.convert).Seconds()
+	}
+	return r
+}
+
+// Units of the values of a BenchmarkStat.
+const (
+	UnitSeconds        = "s"
+	UnitBytes          = "B"
+	UnitBytesPerSecond = "B/s"
+	UnitCount          = ""
+)
+
+// BenchmarkStat summarizes a value of the runs of pull --benchmark.
+type BenchmarkStat struct {
+	Name   string  `json:"name"`
+	Unit   string  `json:"unit,omitempty"`
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	Max    float64 `json:"max"`
+}
+
+// benchmarkValues are the values of a BenchmarkRun summarized, in order.
+var benchmarkValues = []struct {
+	name  string
+	unit  string
+	value func(BenchmarkRun) float64
+}{
+	{"dns", UnitSeconds, func(r BenchmarkRun) float64 { return r.DNS }},
+	{"connect", UnitSeconds, func(r BenchmarkRun) float64 { return r.Connect }},
+	{"tls", UnitSeconds, func(r BenchmarkRun) float64 { return r.TLS }},
+	{"first-byte", UnitSeconds, func(r BenchmarkRun) float64 { return r.FirstByte }},
+	{"download", UnitSeconds, func(r BenchmarkRun) float64 { return r.Download }},
+	{"convert", UnitSeconds, func(r BenchmarkRun) float64 { return r.Convert }},
+	{"total", UnitSeconds, func(r BenchmarkRun) float64 { return r.Total }},
+	{"bytes", UnitBytes, func(r BenchmarkRun) float64 { return float64(r.Bytes) }},
+	{"throughput", UnitBytesPerSecond, func(r BenchmarkRun) float64 { return r.Throughput }},
+	{"cache-hits", UnitCount, func(r BenchmarkRun) float64 { return float64(r.CacheHits) }},
+	{"cache-misses", UnitCount, func(r BenchmarkRun) float64 { return float64(r.CacheMisses) }},
+}
+
+// SummarizeBenchmark returns the minimum, median and maximum of each value
+// of runs, which must not be empty.
+func SummarizeBenchmark(runs []BenchmarkRun) []BenchmarkStat {
+	stats := make([]BenchmarkStat, 0, len(benchmarkValues))
+	values := make([]float64, len(runs))
+	for _, bv := range benchmarkValues {
+		for i, r := range runs {
+			values[i] = bv.value(r)
+		}
+		sort.Float64s(values)
+		median := values[len(values)/2]
+		if len(values)%2 == 0 {
+			median = (values[len(values)/2-1] + median) / 2
+		}
+		stats = append(stats, BenchmarkStat{
+			Name:   bv.name,
+			Unit:   bv.unit,
+			Min:    values[0],
+			Median: median,
+			Max:    values[len(values)-1],
+		})
+	}
+	return stats
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+)
+
+func TestPullBenchmark(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("blob"))
+	}))
+	defer srv.Close()
+
+	b := NewPullBenchmark()
+	ctx := httptrace.WithClientTrace(context.Background(), b.ClientTrace())
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+
+	r := NewProgressReporter()
+	r.Observe(b.Observe)
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:a", Bytes: 5, Total: 10})
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:a", Bytes: 10, Total: 10})
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:b", Bytes: 6, Total: 6})
+	r.Report(ProgressEvent{Phase: PhaseConvert})
+	if err := r.Close(); err != nil {
+		t.Fatalf("unexpected error closing reporter without socket: %v", err)
+	}
+
+	run := b.Run(1, 2)
+	if run.Connect <= 0 || run.TLS <= 0 || run.FirstByte <= 0 {
+		t.Errorf("got connect %v, TLS %v, first byte %v, want them measured", run.Connect, run.TLS, run.FirstByte)
+	}
+	if run.Bytes != 16 {
+		t.Errorf("got %d bytes, want 16", run.Bytes)
+	}
+	if run.Download <= 0 || run.Throughput <= 0 || run.Convert <= 0 {
+		t.Errorf("got download %v, throughput %v, convert %v, want them measured", run.Download, run.Throughput, run.Convert)
+	}
+	if run.Total < run.Download {
+		t.Errorf("got total %v, less than download %v", run.Total, run.Download)
+	}
+	if run.CacheHits != 1 || run.CacheMisses != 2 {
+		t.Errorf("got %d cache hits and %d misses, want 1 and 2", run.CacheHits, run.CacheMisses)
+	}
+}
+
+func TestPullBenchmarkCached(t *testing.T) {
+	// A pull served from the cache downloads nothing.
+	run := NewPullBenchmark().Run(3, 0)
+	if run.Bytes != 0 || run.Download != 0 || run.Throughput != 0 || run.Convert != 0 {
+		t.Errorf("got %+v, want no download or conversion", run)
+	}
+}
+
+func TestSummarizeBenchmark(t *testing.T) {
+	runs := []BenchmarkRun{
+		{Total: 3, Bytes: 30, CacheHits: 1},
+		{Total: 1, Bytes: 10},
+		{Total: 2, Bytes: 20},
+		{Total: 8, Bytes: 40, CacheHits: 1},
+	}
+
+	stats := SummarizeBenchmark(runs)
+	if len(stats) != len(benchmarkValues) {
+		t.Fatalf("got %d stats, want %d", len(stats), len(benchmarkValues))
+	}
+	byName := make(map[string]BenchmarkStat)
+	for _, s := range stats {
+		byName[s.Name] = s
+	}
+
+	tests := []struct {
+		name string
+		want BenchmarkStat
+	}{
+		{name: "total", want: BenchmarkStat{Name: "total", Unit: UnitSeconds, Min: 1, Median: 2.5, Max: 8}},
+		{name: "bytes", want: BenchmarkStat{Name: "bytes", Unit: UnitBytes, Min: 10, Median: 25, Max: 40}},
+		{name: "cache-hits", want: BenchmarkStat{Name: "cache-hits", Min: 0, Median: 0.5, Max: 1}},
+	}
+	for _, tt := range tests {
+		if got := byName[tt.name]; got != tt.want {
+			t.Errorf("got %+v, want %+v", got, tt.want)
+		}
+	}
+
+	// The median of an odd number of runs is the middle run.
+	if got := SummarizeBenchmark(runs[:3])[6]; got.Median != 2 {
+		t.Errorf("got median total %v of 3 runs, want 2", got.Median)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// BlobDigestError is returned when blobs don't match their digest.
+type BlobDigestError struct {
+	// Digests are the digests of the blobs that don't match, sorted.
+	Digests []digest.Digest
+}
+
+func (e *BlobDigestError) Error() string {
+	s := make([]string, len(e.Digests))
+	for i, d := range e.Digests {
+		s[i] = d.String()
+	}
+	return fmt.Sprintf("content of blobs does not match digest: %s", strings.Join(s, ", "))
+}
+
+// VerifyBlobs checks that the blobs of the OCI layout at dir match their
+// digests, with jobs blobs verified in parallel. A *BlobDigestError listing
+// every blob that doesn't match is returned, after all blobs are verified.
+// Other errors, such as a missing blob, stop the verification.
+func VerifyBlobs(ctx context.Context, dir string, digests []digest.Digest, jobs int) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		mismatches []digest.Digest
+		firstErr   error
+	)
+	work := make(chan digest.Digest)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs && i < len(digests); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range work {
+				ok, err := verifyBlob(dir, d)
+				mu.Lock()
+				switch {
+				case err != nil && firstErr == nil:
+					firstErr = err
+					cancel()
+				case err == nil && !ok:
+					mismatches = append(mismatches, d)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, d := range digests {
+		select {
+		case work <- d:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(mismatches) > 0 {
+		sort.Slice(mismatches, func(i, j int) bool { return mismatches[i] < mismatches[j] })
+		return &BlobDigestError{Digests: mismatches}
+	}
+	return nil
+}
+
+// verifyBlob reports whether the blob with digest d of the OCI layout at dir
+// matches d.
+func verifyBlob(dir string, d digest.Digest) (bool, error) {
+	if err := d.Validate(); err != nil {
+		return false, err
+	}
+	f, err := os.Open(filepath.Join(dir, "blobs", d.Algorithm().String(), d.Encoded()))
+	if err != nil {
+		return false, fmt.Errorf("while verifying blob %s: %v", d, err)
+	}
+	defer f.Close()
+
+	v := d.Verifier()
+	if _, err := io.Copy(v, f); err != nil {
+		return false, fmt.Errorf("while verifying blob %s: %v", d, err)
+	}
+	return v.Verified(), nil
+}
+
+type verifyJobsKey struct{}
+
+// WithVerifyJobs returns a copy of ctx carrying jobs, the number of blobs
+// verified in parallel after they are downloaded to the cache.
+func WithVerifyJobs(ctx context.Context, jobs int) context.Context {
+	return context.WithValue(ctx, verifyJobsKey{}, jobs)
+}
+
+// VerifyJobsFromContext returns the number of blobs verified in parallel
+// carried by ctx, or the number of CPUs if it is 0, and false if ctx carries
+// none, as blobs are then not verified.
+func VerifyJobsFromContext(ctx context.Context) (int, bool) {
+	jobs, ok := ctx.Value(verifyJobsKey{}).(int)
+	if !ok {
+		return 0, false
+	}
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	return jobs, true
+}
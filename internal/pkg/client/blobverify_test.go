@@ -0,0 +1,113 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// writeBlobs writes n blobs of size bytes to an OCI layout in dir, and
+// returns their digests.
+func writeBlobs(tb testing.TB, dir string, n, size int) []digest.Digest {
+	tb.Helper()
+
+	blobDir := filepath.Join(dir, "blobs", "sha256")
+	if err := os.MkdirAll(blobDir, 0o755); err != nil {
+		tb.Fatal(err)
+	}
+	digests := make([]digest.Digest, n)
+	for i := range digests {
+		b := make([]byte, size)
+		copy(b, fmt.Sprintf("blob %d", i))
+		d := digest.FromBytes(b)
+		if err := os.WriteFile(filepath.Join(blobDir, d.Encoded()), b, 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		digests[i] = d
+	}
+	return digests
+}
+
+func TestVerifyBlobs(t *testing.T) {
+	dir := t.TempDir()
+	digests := writeBlobs(t, dir, 16, 1024)
+
+	for _, jobs := range []int{0, 1, 4, 32} {
+		if err := VerifyBlobs(context.Background(), dir, digests, jobs); err != nil {
+			t.Errorf("jobs %d: unexpected error: %v", jobs, err)
+		}
+	}
+
+	// Corrupt two blobs, which must both be reported.
+	for _, d := range []digest.Digest{digests[3], digests[11]} {
+		if err := os.WriteFile(filepath.Join(dir, "blobs", "sha256", d.Encoded()), []byte("corrupt"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := VerifyBlobs(context.Background(), dir, digests, 4)
+	var bde *BlobDigestError
+	if !errors.As(err, &bde) {
+		t.Fatalf("got error %v, want a BlobDigestError", err)
+	}
+	if len(bde.Digests) != 2 {
+		t.Errorf("got mismatches %v, want %s and %s", bde.Digests, digests[3], digests[11])
+	}
+	for _, d := range bde.Digests {
+		if d != digests[3] && d != digests[11] {
+			t.Errorf("unexpected mismatch %s", d)
+		}
+	}
+
+	// A missing blob is an error, but not a mismatch.
+	missing := digest.FromString("missing")
+	err = VerifyBlobs(context.Background(), dir, []digest.Digest{digests[0], missing}, 2)
+	if err == nil || errors.As(err, &bde) {
+		t.Errorf("got error %v, want an error for the missing blob", err)
+	}
+}
+
+func TestVerifyJobsFromContext(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := VerifyJobsFromContext(ctx); ok {
+		t.Errorf("blobs verified by default")
+	}
+	if got, ok := VerifyJobsFromContext(WithVerifyJobs(ctx, 0)); !ok || got != runtime.NumCPU() {
+		t.Errorf("got %d jobs %v, want %d", got, ok, runtime.NumCPU())
+	}
+	if got, ok := VerifyJobsFromContext(WithVerifyJobs(ctx, 3)); !ok || got != 3 {
+		t.Errorf("got %d jobs %v, want 3", got, ok)
+	}
+}
+
+// BenchmarkVerifyBlobs compares the verification of an image of many blobs
+// with a single job, and with a job per CPU.
+func BenchmarkVerifyBlobs(b *testing.B) {
+	dir := b.TempDir()
+	digests := writeBlobs(b, dir, 64, 1<<20)
+
+	jobsList := []int{1}
+	if n := runtime.NumCPU(); n > 1 {
+		jobsList = append(jobsList, n)
+	}
+	for _, jobs := range jobsList {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			b.SetBytes(int64(len(digests)) << 20)
+			for i := 0; i < b.N; i++ {
+				if err := VerifyBlobs(context.Background(), dir, digests, jobs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// SIFDifference describes a difference between two SIF images.
+type SIFDifference struct {
+	// Object describes the differing data object, or the global header.
+	Object string
+	// Reason describes the difference.
+	Reason string
+	// Offset is the offset, in the first image, of the first differing
+	// byte of the object data, or -1 if the data is identical.
+	Offset int64
+}
+
+func (d SIFDifference) String() string {
+	if d.Offset < 0 {
+		return fmt.Sprintf("%s: %s", d.Object, d.Reason)
+	}
+	return fmt.Sprintf("%s: %s, first difference at offset %d", d.Object, d.Reason, d.Offset)
+}
+
+// CompareSIFs compares the SIF images at pathA and pathB, and returns their
+// differences. The image IDs and the creation and modification times of the
+// images and their objects are ignored, as they differ between otherwise
+// identical images.
+func CompareSIFs(pathA, pathB string) ([]SIFDifference, error) {
+	fa, err := sif.LoadContainerFromPath(pathA, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return nil, fmt.Errorf("while loading %s: %v", pathA, err)
+	}
+	defer fa.UnloadContainer()
+
+	fb, err := sif.LoadContainerFromPath(pathB, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return nil, fmt.Errorf("while loading %s: %v", pathB, err)
+	}
+	defer fb.UnloadContainer()
+
+	var diffs []SIFDifference
+
+	if fa.LaunchScript() != fb.LaunchScript() {
+		diffs = append(diffs, SIFDifference{Object: "header", Reason: "launch scripts differ", Offset: -1})
+	}
+
+	da, err := fa.GetDescriptors()
+	if err != nil {
+		return nil, err
+	}
+	db, err := fb.GetDescriptors()
+	if err != nil {
+		return nil, err
+	}
+	if len(da) != len(db) {
+		reason := fmt.Sprintf("images have %d and %d objects", len(da), len(db))
+		return append(diffs, SIFDifference{Object: "header", Reason: reason, Offset: -1}), nil
+	}
+
+	for i := range da {
+		d, err := compareDescriptors(da[i], db[i])
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			diffs = append(diffs, *d)
+		}
+	}
+	return diffs, nil
+}
+
+// compareDescriptors returns the difference between the data objects a and
+// b, or nil if they are identical.
+func compareDescriptors(a, b sif.Descriptor) (*SIFDifference, error) {
+	diff := &SIFDifference{
+		Object: fmt.Sprintf("object %d (%s)", a.ID(), a.DataType()),
+		Offset: -1,
+	}
+
+	la, ga := a.LinkedID()
+	lb, gb := b.LinkedID()
+
+	switch {
+	case a.DataType() != b.DataType():
+		diff.Reason = fmt.Sprintf("data types %s and %s differ", a.DataType(), b.DataType())
+		return diff, nil
+	case a.Name() != b.Name():
+		diff.Reason = fmt.Sprintf("names %q and %q differ", a.Name(), b.Name())
+		return diff, nil
+	case a.GroupID() != b.GroupID() || la != lb || ga != gb:
+		diff.Reason = "groups or links differ"
+		return diff, nil
+	}
+
+	if a.DataType() == sif.DataPartition {
+		fsa, pta, archa, err := a.PartitionMetadata()
+		if err != nil {
+			return nil, err
+		}
+		fsb, ptb, archb, err := b.PartitionMetadata()
+		if err != nil {
+			return nil, err
+		}
+		if fsa != fsb || pta != ptb || archa != archb {
+			diff.Reason = "partition metadata differs"
+			return diff, nil
+		}
+	}
+
+	off, err := firstDifference(a.GetReader(), b.GetReader())
+	if err != nil {
+		return nil, fmt.Errorf("while comparing %s: %v", diff.Object, err)
+	}
+
+	switch {
+	case off >= 0:
+		diff.Reason = "data differs"
+		diff.Offset = a.Offset() + off
+	case a.Size() != b.Size():
+		// One object is a prefix of the other.
+		diff.Reason = fmt.Sprintf("sizes %d and %d differ", a.Size(), b.Size())
+		diff.Offset = a.Offset() + a.Size()
+		if b.Size() < a.Size() {
+			diff.Offset = a.Offset() + b.Size()
+		}
+	default:
+		return nil, nil
+	}
+	return diff, nil
+}
+
+// firstDifference returns the offset of the first byte that differs between
+// ra and rb, or -1 if they are identical up to the end of the shortest one.
+func firstDifference(ra, rb io.Reader) (int64, error) {
+	ba := bufio.NewReader(ra)
+	bb := bufio.NewReader(rb)
+
+	for off := int64(0); ; off++ {
+		ca, errA := ba.ReadByte()
+		cb, errB := bb.ReadByte()
+		if errA == io.EOF || errB == io.EOF {
+			return -1, nil
+		}
+		if errA != nil {
+			return 0, errA
+		}
+		if errB != nil {
+			return 0, errB
+		}
+		if ca != cb {
+			return off, nil
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// createSIF creates a SIF at path, created at t, with a JSON object and a
+// partition holding data.
+func createSIF(t *testing.T, path string, created time.Time, data string) {
+	t.Helper()
+
+	js, err := sif.NewDescriptorInput(sif.DataGenericJSON, strings.NewReader(`{"a":"b"}`),
+		sif.OptObjectName("labels.json"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := sif.NewDescriptorInput(sif.DataPartition, bytes.NewReader([]byte(data)),
+		sif.OptPartitionMetadata(sif.FsSquash, sif.PartPrimSys, "amd64"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := sif.CreateContainerAtPath(path,
+		sif.OptCreateWithTime(created),
+		sif.OptCreateWithDescriptors(js, part),
+	)
+	if err != nil {
+		t.Fatalf("while creating SIF: %v", err)
+	}
+	if err := f.UnloadContainer(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompareSIFs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	base := filepath.Join(dir, "base.sif")
+	createSIF(t, base, now, "0123456789")
+
+	tests := []struct {
+		name       string
+		data       string
+		wantDiff   bool
+		wantOffset int64
+	}{
+		{
+			name: "Identical",
+			data: "0123456789",
+		},
+		{
+			name:       "DataDiffers",
+			data:       "0123X56789",
+			wantDiff:   true,
+			wantOffset: 4,
+		},
+		{
+			name:       "Truncated",
+			data:       "012345",
+			wantDiff:   true,
+			wantOffset: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Created at another time, with another ID.
+			other := filepath.Join(dir, tt.name+".sif")
+			createSIF(t, other, now.Add(time.Hour), tt.data)
+
+			diffs, err := CompareSIFs(base, other)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.wantDiff {
+				if len(diffs) != 0 {
+					t.Errorf("unexpected differences: %v", diffs)
+				}
+				return
+			}
+			if len(diffs) != 1 {
+				t.Fatalf("got %d differences, want 1: %v", len(diffs), diffs)
+			}
+
+			f, err := sif.LoadContainerFromPath(base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.UnloadContainer()
+			d, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := d.Offset() + tt.wantOffset; diffs[0].Offset != want {
+				t.Errorf("got offset %d, want %d", diffs[0].Offset, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TagCompletionTTL is how long the tags of a repository listed for shell
+// completion are cached, so that repeated completions don't query the
+// registry each time.
+const TagCompletionTTL = 2 * time.Minute
+
+// cachedTags is the content of a file caching the tags of a repository.
+type cachedTags struct {
+	Repo string   `json:"repo"`
+	Tags []string `json:"tags"`
+}
+
+// tagCachePath returns the path of the file caching the tags of repo in dir.
+func tagCachePath(dir, repo string) string {
+	sum := sha256.Sum256([]byte(repo))
+	return filepath.Join(dir, hex.EncodeToString(sum[:]))
+}
+
+// CachedTags returns the tags of repo cached in dir, if they were cached less
+// than ttl ago.
+func CachedTags(dir, repo string, ttl time.Duration) ([]string, bool) {
+	path := tagCachePath(dir, repo)
+	fi, err := os.Stat(path)
+	if err != nil || time.Since(fi.ModTime()) > ttl {
+		return nil, false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var c cachedTags
+	if err := json.Unmarshal(b, &c); err != nil || c.Repo != repo {
+		return nil, false
+	}
+	return c.Tags, true
+}
+
+// CacheTags caches the tags of repo in dir, which is created if needed.
+func CacheTags(dir, repo string, tags []string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(cachedTags{Repo: repo, Tags: tags})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), tagCachePath(dir, repo))
+}
+
+// CompleteTags returns the references repo:tag, sorted, for the tags starting
+// with prefix.
+func CompleteTags(repo string, tags []string, prefix string) []string {
+	var refs []string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			refs = append(refs, repo+":"+tag)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// SplitTagPrefix splits a partial reference being completed, e.g.
+// docker://alpine:3., into its repository and the prefix of its tag. It
+// returns false if ref has no transport or tag yet, or already has a digest.
+func SplitTagPrefix(ref string) (repo, prefix string, ok bool) {
+	t := strings.Index(ref, "://")
+	if t < 0 || strings.Contains(ref, "@") {
+		return "", "", false
+	}
+	i := strings.LastIndex(ref, ":")
+	if i == t || strings.Contains(ref[i:], "/") {
+		return "", "", false
+	}
+	return ref[:i], ref[i+1:], true
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitTagPrefix(t *testing.T) {
+	tests := []struct {
+		ref        string
+		wantRepo   string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{ref: "docker://alpine:", wantRepo: "docker://alpine", wantOK: true},
+		{ref: "docker://alpine:3.", wantRepo: "docker://alpine", wantPrefix: "3.", wantOK: true},
+		{ref: "docker://localhost:5000/app:v", wantRepo: "docker://localhost:5000/app", wantPrefix: "v", wantOK: true},
+		{ref: "docker://alpine"},
+		{ref: "docker://localhost:5000/app"},
+		{ref: "docker://alpine@sha256:abc"},
+		{ref: "docker:"},
+		{ref: "alpine.sif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			repo, prefix, ok := SplitTagPrefix(tt.ref)
+			if ok != tt.wantOK || repo != tt.wantRepo || prefix != tt.wantPrefix {
+				t.Errorf("got %q %q %v, want %q %q %v", repo, prefix, ok, tt.wantRepo, tt.wantPrefix, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCompleteTags(t *testing.T) {
+	tags := []string{"latest", "3.18", "3.17", "edge"}
+	got := CompleteTags("docker://alpine", tags, "3.")
+	want := []string{"docker://alpine:3.17", "docker://alpine:3.18"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCachedTags(t *testing.T) {
+	dir := t.TempDir()
+	repo := "docker://alpine"
+	tags := []string{"3.18", "latest"}
+
+	if _, ok := CachedTags(dir, repo, time.Minute); ok {
+		t.Fatalf("unexpected cached tags before caching")
+	}
+	if err := CacheTags(dir, repo, tags); err != nil {
+		t.Fatalf("while caching tags: %v", err)
+	}
+
+	got, ok := CachedTags(dir, repo, time.Minute)
+	if !ok || !reflect.DeepEqual(got, tags) {
+		t.Errorf("got %v %v, want %v true", got, ok, tags)
+	}
+	if _, ok := CachedTags(dir, "docker://busybox", time.Minute); ok {
+		t.Errorf("unexpected cached tags for another repository")
+	}
+
+	old := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(tagCachePath(dir, repo), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := CachedTags(dir, repo, time.Minute); ok {
+		t.Errorf("unexpected cached tags after expiry")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// CreatedLabel is the label recording when an image was built, as set from
+// the created field of the OCI image config of docker/OCI images.
+const CreatedLabel = imgspecv1.AnnotationCreated
+
+// ParseCreated returns the time the image with labels was built, from its
+// CreatedLabel. It returns false if the label is not set.
+func ParseCreated(labels map[string]string) (time.Time, bool, error) {
+	s, ok := labels[CreatedLabel]
+	if !ok || strings.TrimSpace(s) == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s label %q: %v", CreatedLabel, s, err)
+	}
+	return t, true, nil
+}
+
+// ImageCreated returns the time the SIF image at path was built, from the
+// CreatedLabel of the OCI image config it holds. It returns false if the SIF
+// holds no OCI image config, or the label is not set, as for images built
+// without a created field.
+func ImageCreated(path string) (time.Time, bool, error) {
+	conf, err := imageConfig(path)
+	if conf == nil || err != nil {
+		return time.Time{}, false, err
+	}
+	return ParseCreated(conf.Labels)
+}
+
+// ParseAge parses an age, as a number of days with a d suffix, e.g. 90d, or
+// as a duration, e.g. 36h.
+func ParseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days := strings.TrimSuffix(s, "d")
+		n, err := strconv.ParseUint(days, 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// FormatAge formats d as a number of days, if it is at least a day, or as a
+// duration rounded to the minute.
+func FormatAge(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.Round(time.Minute).String()
+}
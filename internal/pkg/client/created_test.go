@@ -0,0 +1,84 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCreated(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		want    time.Time
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "NoLabels"},
+		{name: "Empty", labels: map[string]string{CreatedLabel: ""}},
+		{name: "RFC3339", labels: map[string]string{CreatedLabel: "2023-01-02T03:04:05Z"}, want: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC), wantOK: true},
+		{name: "Nanoseconds", labels: map[string]string{CreatedLabel: "2023-01-02T03:04:05.5+01:00"}, want: time.Date(2023, 1, 2, 2, 4, 5, 5e8, time.UTC), wantOK: true},
+		{name: "Invalid", labels: map[string]string{CreatedLabel: "yesterday"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := ParseCreated(tt.labels)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{age: "90d", want: 90 * 24 * time.Hour},
+		{age: "0d", want: 0},
+		{age: "36h", want: 36 * time.Hour},
+		{age: "1h30m", want: 90 * time.Minute},
+		{age: "d", wantErr: true},
+		{age: "-1d", wantErr: true},
+		{age: "1.5d", wantErr: true},
+		{age: "ninety", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.age)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAge(%q): got error %v, want error %v", tt.age, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, want %v", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		age  time.Duration
+		want string
+	}{
+		{age: 3*24*time.Hour + 5*time.Hour, want: "3d"},
+		{age: 24 * time.Hour, want: "1d"},
+		{age: 90*time.Minute + 20*time.Second, want: "1h30m0s"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAge(tt.age); got != tt.want {
+			t.Errorf("FormatAge(%v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
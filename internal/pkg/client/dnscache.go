@@ -0,0 +1,246 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dnsEntry holds the addresses of a host, or the error resolving it, once
+// ready is closed.
+type dnsEntry struct {
+	ready    chan struct{}
+	addrs    []net.IPAddr
+	err      error
+	resolved time.Time
+}
+
+// DNSCache caches the addresses of the hosts connected to, for the duration
+// of a single invocation. Concurrent lookups of a host not cached share a
+// single resolution, and failed resolutions are not cached.
+type DNSCache struct {
+	ttl    time.Duration
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+// NewDNSCache returns a DNSCache keeping the addresses of a host for ttl.
+func NewDNSCache(ttl time.Duration) *DNSCache {
+	return &DNSCache{
+		ttl:     ttl,
+		lookup:  net.DefaultResolver.LookupIPAddr,
+		entries: make(map[string]*dnsEntry),
+	}
+}
+
+// LookupIPAddr returns the addresses of host, from the cache if they were
+// resolved less than the TTL of c ago.
+func (c *DNSCache) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	c.mu.Lock()
+	e, ok := c.entries[host]
+	if ok && !c.expired(e) {
+		c.mu.Unlock()
+		select {
+		case <-e.ready:
+			return e.addrs, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	e = &dnsEntry{ready: make(chan struct{})}
+	c.entries[host] = e
+	c.mu.Unlock()
+
+	addrs, err := c.lookup(ctx, host)
+
+	c.mu.Lock()
+	e.addrs, e.err, e.resolved = addrs, err, time.Now()
+	if err != nil && c.entries[host] == e {
+		delete(c.entries, host)
+	}
+	close(e.ready)
+	c.mu.Unlock()
+	return addrs, err
+}
+
+// expired reports whether the addresses of e were resolved at least the TTL
+// of c ago. A resolution still in progress is not expired. c.mu must be held.
+func (c *DNSCache) expired(e *dnsEntry) bool {
+	select {
+	case <-e.ready:
+		return time.Since(e.resolved) >= c.ttl
+	default:
+		return false
+	}
+}
+
+// DialContext returns a DialContext function for an http.Transport, dialing
+// with d, whose lookups of hosts are answered from c. d still races the IPv4
+// and IPv6 addresses of a host (Happy Eyeballs), within its timeout. Hosts
+// listed in /etc/hosts are resolved from it, without the cache.
+func (c *DNSCache) DialContext(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	cd := *d
+	cd.Resolver = &net.Resolver{
+		PreferGo: true,
+		Dial:     c.dialDNS,
+	}
+	return cd.DialContext
+}
+
+// dialDNS returns a connection to an in-process DNS server answering a query
+// from c, in place of the name server at address.
+func (c *DNSCache) dialDNS(ctx context.Context, network, address string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go c.serveDNS(ctx, server)
+	return client, nil
+}
+
+// serveDNS answers a query received on conn, which is not a net.PacketConn
+// and so carries messages prefixed by their length, as over TCP.
+func (c *DNSCache) serveDNS(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	var l [2]byte
+	if _, err := io.ReadFull(conn, l[:]); err != nil {
+		return
+	}
+	query := make([]byte, binary.BigEndian.Uint16(l[:]))
+	if _, err := io.ReadFull(conn, query); err != nil {
+		return
+	}
+	res, err := c.answer(ctx, query)
+	if err != nil {
+		return
+	}
+	binary.BigEndian.PutUint16(l[:], uint16(len(res)))
+	conn.Write(append(l[:], res...))
+}
+
+// DNS message constants, see RFC 1035.
+const (
+	dnsHeaderLen   = 12
+	dnsTypeA       = 1
+	dnsTypeAAAA    = 28
+	dnsClassINET   = 1
+	dnsRCodeFail   = 2
+	dnsRCodeNXName = 3
+)
+
+var errDNSQuery = errors.New("malformed DNS query")
+
+// answer returns the response to a DNS query for the A or AAAA records of a
+// host, holding its addresses of that type cached by c.
+func (c *DNSCache) answer(ctx context.Context, query []byte) ([]byte, error) {
+	if len(query) < dnsHeaderLen || binary.BigEndian.Uint16(query[4:]) != 1 {
+		return nil, errDNSQuery
+	}
+
+	// The question holds the host as a sequence of labels, each prefixed by
+	// its length, then the type and class of the records queried.
+	var labels []string
+	i := dnsHeaderLen
+	for {
+		if i >= len(query) {
+			return nil, errDNSQuery
+		}
+		n := int(query[i])
+		if n == 0 {
+			break
+		}
+		if n > 63 || i+1+n > len(query) {
+			return nil, errDNSQuery
+		}
+		labels = append(labels, string(query[i+1:i+1+n]))
+		i += 1 + n
+	}
+	end := i + 5
+	if end > len(query) {
+		return nil, errDNSQuery
+	}
+	qtype := binary.BigEndian.Uint16(query[i+1:])
+
+	res := make([]byte, dnsHeaderLen, 512)
+	copy(res, query[:2])
+	// A recursive response, echoing the recursion desired bit of the query.
+	res[2] = 0x80 | query[2]&0x01
+	res[3] = 0x80
+	binary.BigEndian.PutUint16(res[4:], 1)
+	res = append(res, query[dnsHeaderLen:end]...)
+
+	addrs, err := c.LookupIPAddr(ctx, strings.Join(labels, "."))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			res[3] |= dnsRCodeNXName
+		} else {
+			res[3] |= dnsRCodeFail
+		}
+		return res, nil
+	}
+
+	var count uint16
+	for _, a := range addrs {
+		ip := a.IP.To4()
+		if qtype == dnsTypeAAAA {
+			if ip != nil {
+				continue
+			}
+			ip = a.IP.To16()
+		}
+		if ip == nil || (qtype != dnsTypeA && qtype != dnsTypeAAAA) {
+			continue
+		}
+		// The name of the record points to the one of the question.
+		res = append(res, 0xc0, dnsHeaderLen)
+		res = binary.BigEndian.AppendUint16(res, qtype)
+		res = binary.BigEndian.AppendUint16(res, dnsClassINET)
+		res = binary.BigEndian.AppendUint32(res, uint32(c.ttl/time.Second))
+		res = binary.BigEndian.AppendUint16(res, uint16(len(ip)))
+		res = append(res, ip...)
+		count++
+	}
+	binary.BigEndian.PutUint16(res[6:], count)
+	return res, nil
+}
+
+var (
+	// dnsCache is the DNSCache used by HTTP transports, if set by
+	// UseDNSCache.
+	dnsCache *DNSCache
+	// tlsSessions caches the TLS sessions of HTTP transports, if set by
+	// UseDNSCache.
+	tlsSessions tls.ClientSessionCache
+)
+
+// UseDNSCache caches the addresses of the hosts connected to by the HTTP
+// transports built by singularity, for ttl, and has them keep more idle
+// connections and TLS sessions for reuse, to speed up repeated requests to
+// the same hosts. containers/image builds the transports of docker/OCI
+// registries itself, without any way to set how they resolve hosts, so these
+// are not cached. It must be called before any transport is built.
+func UseDNSCache(ttl time.Duration) {
+	dnsCache = NewDNSCache(ttl)
+	tlsSessions = tls.NewLRUClientSessionCache(64)
+}
+
+// dialContext returns the DialContext function of d, resolving hosts through
+// the DNS cache if set by UseDNSCache.
+func dialContext(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dnsCache != nil {
+		return dnsCache.DialContext(d)
+	}
+	return d.DialContext
+}
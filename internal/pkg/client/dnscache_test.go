@@ -0,0 +1,224 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLookup resolves any host to addrs, or 127.0.0.1 if not set, after
+// delay, counting lookups, or fails once fail is set.
+type fakeLookup struct {
+	addrs   []net.IPAddr
+	delay   time.Duration
+	lookups int32
+	fail    atomic.Bool
+}
+
+func (f *fakeLookup) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	atomic.AddInt32(&f.lookups, 1)
+	time.Sleep(f.delay)
+	if f.fail.Load() {
+		return nil, &net.DNSError{Err: "lookup failed", Name: host, IsNotFound: true}
+	}
+	if f.addrs != nil {
+		return f.addrs, nil
+	}
+	return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+}
+
+func TestDNSCache(t *testing.T) {
+	f := &fakeLookup{}
+	c := NewDNSCache(time.Hour)
+	c.lookup = f.lookup
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.LookupIPAddr(context.Background(), "registry.example.com"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if f.lookups != 1 {
+		t.Errorf("got %d lookups, want 1", f.lookups)
+	}
+
+	// An expired entry is resolved again, and not used if that fails.
+	c.ttl = 0
+	f.fail.Store(true)
+	if addrs, err := c.LookupIPAddr(context.Background(), "registry.example.com"); err == nil {
+		t.Errorf("got %v, want an error resolving expired host", addrs)
+	}
+	if f.lookups != 2 {
+		t.Errorf("got %d lookups, want 2", f.lookups)
+	}
+
+	// A failed resolution is not cached.
+	c.ttl = time.Hour
+	f.fail.Store(false)
+	if _, err := c.LookupIPAddr(context.Background(), "registry.example.com"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if f.lookups != 3 {
+		t.Errorf("got %d lookups, want 3", f.lookups)
+	}
+}
+
+func TestDNSCacheConcurrentLookups(t *testing.T) {
+	f := &fakeLookup{delay: 100 * time.Millisecond}
+	c := NewDNSCache(time.Hour)
+	c.lookup = f.lookup
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.LookupIPAddr(context.Background(), "registry.example.com"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if f.lookups != 1 {
+		t.Errorf("got %d lookups for 10 concurrent ones, want 1", f.lookups)
+	}
+}
+
+func TestDNSCacheDialContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	f := &fakeLookup{}
+	c := NewDNSCache(time.Hour)
+	c.lookup = f.lookup
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext:       c.DialContext(&net.Dialer{}),
+		DisableKeepAlives: true,
+	}}
+	for i := 0; i < 3; i++ {
+		res, err := client.Get("http://registry.example.com:" + port)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		res.Body.Close()
+	}
+	if f.lookups != 1 {
+		t.Errorf("got %d lookups for 3 connections, want 1", f.lookups)
+	}
+}
+
+func TestDNSCacheDialContextErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	tests := []struct {
+		name    string
+		addrs   []net.IPAddr
+		fail    bool
+		wantErr bool
+	}{
+		{
+			// The IPv6 address, tried first, is not listened on, and the
+			// IPv4 one is raced against it.
+			name:  "DualStack",
+			addrs: []net.IPAddr{{IP: net.IPv6loopback}, {IP: net.IPv4(127, 0, 0, 1)}},
+		},
+		{
+			name:    "NotFound",
+			fail:    true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeLookup{addrs: tt.addrs}
+			f.fail.Store(tt.fail)
+			c := NewDNSCache(time.Hour)
+			c.lookup = f.lookup
+
+			dial := c.DialContext(&net.Dialer{Timeout: 5 * time.Second})
+			conn, err := dial(context.Background(), "tcp", "registry.example.com:"+port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				var dnsErr *net.DNSError
+				if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+					t.Errorf("got error %v, want a host not found", err)
+				}
+				return
+			}
+			conn.Close()
+		})
+	}
+}
+
+// BenchmarkBatchPull measures the requests of a batch of 50 pulls to the same
+// registry, with a DNS resolution taking 1ms, with and without the DNS cache
+// and connection reuse.
+func BenchmarkBatchPull(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1024))
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	url := "http://registry.example.com:" + port
+
+	batch := func(b *testing.B, newClient func() *http.Client) {
+		for i := 0; i < b.N; i++ {
+			for n := 0; n < 50; n++ {
+				res, err := newClient().Get(url)
+				if err != nil {
+					b.Fatal(err)
+				}
+				io.Copy(io.Discard, res.Body)
+				res.Body.Close()
+			}
+		}
+	}
+
+	b.Run("Uncached", func(b *testing.B) {
+		f := &fakeLookup{delay: time.Millisecond}
+		d := &net.Dialer{}
+		batch(b, func() *http.Client {
+			// A lookup and a new connection for each pull.
+			return &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					_, port, _ := net.SplitHostPort(addr)
+					addrs, err := f.lookup(ctx, "registry.example.com")
+					if err != nil {
+						return nil, err
+					}
+					return d.DialContext(ctx, network, net.JoinHostPort(addrs[0].String(), port))
+				},
+				DisableKeepAlives: true,
+			}}
+		})
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		f := &fakeLookup{delay: time.Millisecond}
+		c := NewDNSCache(time.Minute)
+		c.lookup = f.lookup
+		client := &http.Client{Transport: &http.Transport{
+			DialContext:         c.DialContext(&net.Dialer{}),
+			MaxIdleConnsPerHost: 16,
+		}}
+		batch(b, func() *http.Client { return client })
+	})
+}
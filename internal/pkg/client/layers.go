@@ -0,0 +1,59 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// LayerDescriptor describes a layer of an image.
+type LayerDescriptor struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+}
+
+// LayerList lists the layers of an image, in order from the base layer, as a
+// lockfile fragment for build systems.
+type LayerList struct {
+	// Source is the URI the image was pulled from.
+	Source string `json:"source"`
+	// Digest is the digest of the manifest of the image.
+	Digest string            `json:"digest"`
+	Layers []LayerDescriptor `json:"layers"`
+}
+
+// Write writes l to w, as an indented JSON object if asJSON is set, or as a
+// line "DIGEST MEDIATYPE SIZE" per layer otherwise.
+func (l LayerList) Write(w io.Writer, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(l)
+	}
+	for _, layer := range l.Layers {
+		if _, err := fmt.Fprintf(w, "%s %s %d\n", layer.Digest, layer.MediaType, layer.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes l to the file at path, as with Write.
+func (l LayerList) WriteFile(path string, asJSON bool) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := l.Write(f, asJSON); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
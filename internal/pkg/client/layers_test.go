@@ -0,0 +1,46 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLayerListWrite(t *testing.T) {
+	l := LayerList{
+		Source: "docker://alpine",
+		Digest: "sha256:aaa",
+		Layers: []LayerDescriptor{
+			{Digest: "sha256:111", MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Size: 10},
+			{Digest: "sha256:222", MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Size: 20},
+		},
+	}
+
+	var text bytes.Buffer
+	if err := l.Write(&text, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "sha256:111 application/vnd.oci.image.layer.v1.tar+gzip 10\n" +
+		"sha256:222 application/vnd.docker.image.rootfs.diff.tar.gzip 20\n"
+	if text.String() != want {
+		t.Errorf("got text %q, want %q", text.String(), want)
+	}
+
+	var js bytes.Buffer
+	if err := l.Write(&js, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got LayerList
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", js.String(), err)
+	}
+	if !reflect.DeepEqual(got, l) {
+		t.Errorf("got %+v, want %+v", got, l)
+	}
+}
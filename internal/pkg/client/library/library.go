@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/sylabs/singularity/internal/pkg/buildcfg"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/pkg/sylog"
 	"github.com/sylabs/singularity/pkg/util/singularityconf"
 
@@ -98,8 +99,25 @@ func getDownloadConfig() (scslibrary.Downloader, error) {
 	}, nil
 }
 
+// newClient returns a client of the library configured by config, if its host
+// is allowed by the "allowed library hosts" list of singularity.conf.
+func newClient(config *scslibrary.Config) (*scslibrary.Client, error) {
+	c, err := scslibrary.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize client library: %v", err)
+	}
+	if err := client.CheckLibraryHost(c.BaseURL.Host); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 // DownloadImage is a helper function to wrap library image download operation
 func DownloadImage(ctx context.Context, c *scslibrary.Client, imagePath, arch string, libraryRef *scslibrary.Ref, pb scslibrary.ProgressBar) error {
+	if err := client.CheckLibraryHost(c.BaseURL.Host); err != nil {
+		return err
+	}
+
 	// open destination file for writing
 	f, err := os.OpenFile(imagePath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o777)
 	if err != nil {
@@ -143,6 +161,9 @@ func SearchLibrary(ctx context.Context, c *scslibrary.Client, value, arch string
 	if len(value) < 3 {
 		return fmt.Errorf("bad query '%s'. You must search for at least 3 characters", value)
 	}
+	if err := client.CheckLibraryHost(c.BaseURL.Host); err != nil {
+		return err
+	}
 
 	searchSpec := map[string]string{
 		"value": value,
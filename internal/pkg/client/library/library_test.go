@@ -6,8 +6,18 @@
 package library
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"sync/atomic"
 	"testing"
+
+	scslibrary "github.com/sylabs/scs-library-client/client"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/pkg/util/singularityconf"
 )
 
 func TestNormalizeLibraryRef(t *testing.T) {
@@ -50,3 +60,118 @@ func TestNormalizeLibraryRef(t *testing.T) {
 		})
 	}
 }
+
+func TestPullMetadataAllowedHosts(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"hash": "sha256.aaa", "size": 42}}`))
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		wantErr bool
+	}{
+		{"no allowlist", nil, false},
+		{"allowed", []string{u.Host}, false},
+		{"not allowed", []string{"library.example.com"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := singularityconf.Parse("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			conf.AllowedLibraryHosts = tt.allowed
+			prev := singularityconf.GetCurrentConfig()
+			singularityconf.SetCurrentConfig(conf)
+			defer singularityconf.SetCurrentConfig(prev)
+
+			atomic.StoreInt32(&requests, 0)
+			ref, err := NormalizeLibraryRef("library://alpine:latest")
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := PullOptions{
+				Architecture:  "amd64",
+				LibraryConfig: &scslibrary.Config{BaseURL: srv.URL},
+			}
+			md, err := PullMetadata(context.Background(), ref, opts)
+
+			var nae *client.NotAllowedError
+			if tt.wantErr {
+				if !errors.As(err, &nae) {
+					t.Fatalf("got error %v, want a NotAllowedError", err)
+				}
+				if n := atomic.LoadInt32(&requests); n != 0 {
+					t.Errorf("got %d requests to a library not allowed", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if md.Digest != "sha256.aaa" || md.Size != 42 {
+				t.Errorf("got metadata %+v", md)
+			}
+		})
+	}
+}
+
+func TestListTags(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/search" || r.URL.Query().Get("arch") != "amd64" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"image": [
+			{"entityName": "library", "collectionName": "default", "containerName": "alpine", "tags": ["latest", "3.17"]},
+			{"entityName": "library", "collectionName": "default", "containerName": "alpine", "tags": ["3.16", "3.17"]},
+			{"entityName": "library", "collectionName": "default", "containerName": "alpine-extra", "tags": ["1.0"]},
+			{"entityName": "user", "collectionName": "default", "containerName": "alpine", "tags": ["mine"]},
+			{"entityName": "user", "collectionName": "tools", "containerName": "alpine", "tags": ["tools"]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		ref      string
+		wantTags []string
+	}{
+		{"container", "library://alpine", []string{"latest", "3.17", "3.16"}},
+		{"collection", "library://default/alpine", []string{"latest", "3.17", "3.16"}},
+		{"entity", "library://user/default/alpine", []string{"mine"}},
+		{"other collection", "library://user/tools/alpine", []string{"tools"}},
+		{"no images", "library://user/other/alpine", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := NormalizeLibraryRef(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := PullOptions{
+				Architecture:  "amd64",
+				LibraryConfig: &scslibrary.Config{BaseURL: srv.URL},
+			}
+			tags, err := ListTags(context.Background(), ref, opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("got tags %v, want %v", tags, tt.wantTags)
+			}
+		})
+	}
+}
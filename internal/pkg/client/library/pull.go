@@ -11,27 +11,67 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	keyclient "github.com/sylabs/scs-key-client/client"
 	libclient "github.com/sylabs/scs-library-client/client"
 	scslibrary "github.com/sylabs/scs-library-client/client"
+	"github.com/sylabs/sif/v2/pkg/integrity"
+	"github.com/sylabs/sif/v2/pkg/sif"
 	"github.com/sylabs/singularity/internal/app/singularity"
 	"github.com/sylabs/singularity/internal/pkg/cache"
 	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/sypgp"
 	"golang.org/x/term"
 )
 
 // ErrLibraryPullUnsigned indicates that the interactive portion of the pull was aborted.
 var ErrLibraryPullUnsigned = errors.New("failed to verify container")
 
+// PullOptions holds options for pulling a library image to a file.
+type PullOptions struct {
+	// Architecture of the image to pull from the library.
+	Architecture string
+	// TmpDir is the location for temporary files.
+	TmpDir string
+	// LibraryConfig configures the library client.
+	LibraryConfig *libclient.Config
+	// KeyClientOpts configures the keyserver client used for verification.
+	KeyClientOpts []keyclient.Option
+	// SkipVerify disables signature verification of the pulled image entirely.
+	SkipVerify bool
+	// PreferCached uses an image previously pulled for the same reference
+	// from the cache, if present, without checking the library.
+	PreferCached bool
+	// CacheKeys adds the public keys of the signers of a verified image to
+	// the local keyring, so that it can be verified again offline.
+	CacheKeys bool
+}
+
 // pull will pull a library image into the cache if directTo="", or a specific file if directTo is set.
-func pull(ctx context.Context, imgCache *cache.Handle, directTo string, imageRef *libclient.Ref, arch string, libraryConfig *libclient.Config) (string, error) {
-	c, err := libclient.NewClient(libraryConfig)
+func pull(ctx context.Context, imgCache *cache.Handle, directTo string, imageRef *libclient.Ref, arch string, libraryConfig *libclient.Config, preferCached bool) (string, error) {
+	// The same reference may resolve to different images for different
+	// architectures, or from different libraries.
+	cacheRef := fmt.Sprintf("%s %s %s", libraryConfig.BaseURL, arch, imageRef.String())
+
+	c, err := newClient(libraryConfig)
 	if err != nil {
-		return "", fmt.Errorf("unable to initialize client library: %v", err)
+		return "", err
+	}
+
+	if directTo == "" && preferCached {
+		cacheEntry, err := imgCache.GetReferenceEntry(cache.LibraryCacheType, cacheRef)
+		if err != nil {
+			sylog.Debugf("Could not look up %s in cache: %v", imageRef.String(), err)
+		} else if cacheEntry != nil {
+			sylog.Warningf("Using cached image for %s without checking for a newer version, it may be stale", imageRef.String())
+			return cacheEntry.Path, nil
+		}
+		sylog.Debugf("No cached image for %s, pulling", imageRef.String())
 	}
 
 	ref := fmt.Sprintf("%s:%s", imageRef.Path, imageRef.Tags[0])
@@ -45,8 +85,12 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo string, imageRef
 	}
 
 	var progressBar scslibrary.ProgressBar
-	if term.IsTerminal(2) {
-		progressBar = &client.DownloadProgressBar{}
+	reporter := client.ProgressReporterFromContext(ctx)
+	if term.IsTerminal(2) || reporter != nil {
+		progressBar = &client.DownloadProgressBar{
+			Hidden:   !term.IsTerminal(2),
+			Reporter: reporter,
+		}
 	}
 
 	if directTo != "" {
@@ -81,6 +125,10 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo string, imageRef
 		sylog.Infof("Using cached image")
 	}
 
+	if err := imgCache.PutReference(cache.LibraryCacheType, cacheRef, libraryImage.Hash); err != nil {
+		sylog.Debugf("Could not record %s in cache: %v", imageRef.String(), err)
+	}
+
 	return cacheEntry.Path, nil
 }
 
@@ -116,18 +164,18 @@ func Pull(ctx context.Context, imgCache *cache.Handle, pullFrom *libclient.Ref,
 		sylog.Infof("Downloading library image to tmp cache: %s", directTo)
 	}
 
-	return pull(ctx, imgCache, directTo, pullFrom, arch, libraryConfig)
+	return pull(ctx, imgCache, directTo, pullFrom, arch, libraryConfig, false)
 }
 
 // PullToFile will pull a library image to the specified location, through the cache, or directly if cache is disabled
-func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo string, pullFrom *libclient.Ref, arch string, tmpDir string, libraryConfig *libclient.Config, co []keyclient.Option) (imagePath string, err error) {
+func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo string, pullFrom *libclient.Ref, opts PullOptions) (imagePath string, err error) {
 	directTo := ""
 	if imgCache.IsDisabled() {
 		directTo = pullTo
 		sylog.Debugf("Cache disabled, pulling directly to: %s", directTo)
 	}
 
-	src, err := pull(ctx, imgCache, directTo, pullFrom, arch, libraryConfig)
+	src, err := pull(ctx, imgCache, directTo, pullFrom, opts.Architecture, opts.LibraryConfig, opts.PreferCached)
 	if err != nil {
 		return "", fmt.Errorf("error fetching image: %v", err)
 	}
@@ -140,10 +188,154 @@ func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo string, pull
 		}
 	}
 
-	if err := singularity.Verify(ctx, pullTo, singularity.OptVerifyWithPGP(co...)); err != nil {
+	// The architecture of a library image is recorded when it is built, and
+	// can't be changed without invalidating its signatures, so it is only
+	// checked against the requested architecture.
+	if arch, err := client.ImageArch(pullTo); err != nil {
+		sylog.Warningf("Could not check the architecture of %s: %v", pullTo, err)
+	} else if opts.Architecture != "" && arch != opts.Architecture {
+		sylog.Warningf("Image %s is recorded with architecture %s, not the requested %s", pullFrom.String(), arch, opts.Architecture)
+	}
+
+	if opts.SkipVerify {
+		hash, err := libclient.ImageHash(pullTo)
+		if err != nil {
+			return "", fmt.Errorf("error getting image hash: %v", err)
+		}
+		sylog.Warningf("Signature verification of %s (%s) was SKIPPED as requested with --no-verify", pullFrom.String(), hash)
+		return pullTo, nil
+	}
+
+	var signers []*openpgp.Entity
+	collectSigners := func(_ *sif.FileImage, r integrity.VerifyResult) bool {
+		if e := r.Entity(); e != nil && r.Error() == nil {
+			signers = append(signers, e)
+		}
+		return false
+	}
+
+	if err := singularity.Verify(ctx, pullTo,
+		singularity.OptVerifyWithPGP(opts.KeyClientOpts...),
+		singularity.OptVerifyCallback(collectSigners),
+	); err != nil {
 		sylog.Warningf("%v", err)
 		return pullTo, ErrLibraryPullUnsigned
 	}
 
+	if opts.CacheKeys {
+		cacheSignerKeys(signers)
+	}
+
 	return pullTo, nil
 }
+
+// cacheSignerKeys adds the public keys of signers to the local keyring, if
+// not already there. A failure is not fatal, as the image was verified.
+func cacheSignerKeys(signers []*openpgp.Entity) {
+	keyring := sypgp.NewHandle("")
+	for _, e := range signers {
+		added, err := keyring.CachePubKey(e)
+		if err != nil {
+			sylog.Warningf("Could not add key %X to local keyring: %v", e.PrimaryKey.Fingerprint, err)
+			continue
+		}
+		if added {
+			sylog.Infof("Added key %X of signer to local keyring for offline verification", e.PrimaryKey.Fingerprint)
+		} else {
+			sylog.Verbosef("Key %X of signer already in local keyring", e.PrimaryKey.Fingerprint)
+		}
+	}
+}
+
+// PullMetadata returns the metadata of a library image, without downloading it.
+func PullMetadata(ctx context.Context, pullFrom *libclient.Ref, opts PullOptions) (client.Metadata, error) {
+	c, err := newClient(opts.LibraryConfig)
+	if err != nil {
+		return client.Metadata{}, err
+	}
+
+	ref := fmt.Sprintf("%s:%s", pullFrom.Path, pullFrom.Tags[0])
+
+	libraryImage, err := c.GetImage(ctx, opts.Architecture, ref)
+	if err != nil {
+		if errors.Is(err, libclient.ErrNotFound) {
+			return client.Metadata{}, fmt.Errorf("image does not exist in the library: %s (%s)", ref, opts.Architecture)
+		}
+		return client.Metadata{}, err
+	}
+
+	arch := opts.Architecture
+	if libraryImage.Architecture != nil {
+		arch = *libraryImage.Architecture
+	}
+
+	return client.Metadata{
+		Source:       pullFrom.String(),
+		Digest:       libraryImage.Hash,
+		Architecture: arch,
+		Size:         libraryImage.Size,
+	}, nil
+}
+
+// ListTags lists the tags of the library container of pullFrom, e.g.
+// library://alpine, for the architecture of opts. The library has no call
+// listing the tags of a container, so they are found by searching its images
+// by container name, which must then be at least 3 characters long.
+func ListTags(ctx context.Context, pullFrom *libclient.Ref, opts PullOptions) ([]string, error) {
+	c, err := newClient(opts.LibraryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// A container without entity or collection is in the default collection
+	// of the default entity.
+	path := strings.Split(pullFrom.Path, "/")
+	for len(path) < 3 {
+		path = append([]string{""}, path...)
+	}
+	if path[0] == "" {
+		path[0] = "library"
+	}
+	if path[1] == "" {
+		path[1] = "default"
+	}
+
+	results, err := c.Search(ctx, map[string]string{
+		"value": path[2],
+		"arch":  opts.Architecture,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, img := range results.Images {
+		if img.EntityName != path[0] || img.CollectionName != path[1] || img.ContainerName != path[2] {
+			continue
+		}
+		for _, tag := range img.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags, nil
+}
+
+// IsCurrent reports whether the image at path is the current image for the
+// reference pullFrom in the library, by comparing their hashes. The hash of
+// the current image is also returned.
+func IsCurrent(ctx context.Context, path string, pullFrom *libclient.Ref, opts PullOptions) (bool, string, error) {
+	md, err := PullMetadata(ctx, pullFrom, opts)
+	if err != nil {
+		return false, "", err
+	}
+
+	hash, err := libclient.ImageHash(path)
+	if err != nil {
+		return false, md.Digest, fmt.Errorf("error getting image hash: %v", err)
+	}
+	return hash == md.Digest, md.Digest, nil
+}
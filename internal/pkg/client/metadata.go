@@ -0,0 +1,59 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// MetadataObjectName is the name of the SIF data object holding the pull
+// metadata of a metadata only SIF.
+const MetadataObjectName = "pull-metadata.json"
+
+// Metadata describes an image that was not downloaded, with enough
+// information to fetch it later.
+type Metadata struct {
+	// Source is the URI the image was referenced by.
+	Source string `json:"source"`
+	// Digest identifies the image content at the time of the pull.
+	Digest string `json:"digest"`
+	// Architecture of the image.
+	Architecture string `json:"architecture,omitempty"`
+	// Size is the size in bytes of the content to download.
+	Size int64 `json:"size"`
+}
+
+// WriteMetadataSIF creates a SIF at path holding only m, as a JSON data
+// object. The SIF has no root filesystem, so it can't be run.
+func WriteMetadataSIF(path string, m Metadata) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	di, err := sif.NewDescriptorInput(sif.DataGenericJSON, bytes.NewReader(b),
+		sif.OptObjectName(MetadataObjectName),
+	)
+	if err != nil {
+		return fmt.Errorf("while creating metadata descriptor: %v", err)
+	}
+
+	// Don't leave a previous image in place if creation fails.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := sif.CreateContainerAtPath(path, sif.OptCreateWithDescriptors(di))
+	if err != nil {
+		return fmt.Errorf("while creating SIF: %v", err)
+	}
+	return f.UnloadContainer()
+}
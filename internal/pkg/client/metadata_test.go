@@ -0,0 +1,63 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+func TestWriteMetadataSIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image.sif")
+	// An existing image is replaced.
+	if err := os.WriteFile(path, []byte("previous"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Metadata{
+		Source:       "docker://alpine:latest",
+		Digest:       "sha256:0123456789abcdef",
+		Architecture: "amd64",
+		Size:         1234,
+	}
+	if err := WriteMetadataSIF(path, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f, err := sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatalf("while loading SIF: %v", err)
+	}
+	defer f.UnloadContainer()
+
+	if _, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys)); err == nil {
+		t.Errorf("metadata SIF has a primary partition")
+	}
+
+	d, err := f.GetDescriptor(sif.WithDataType(sif.DataGenericJSON))
+	if err != nil {
+		t.Fatalf("while getting metadata descriptor: %v", err)
+	}
+	if d.Name() != MetadataObjectName {
+		t.Errorf("got object name %q, want %q", d.Name(), MetadataObjectName)
+	}
+
+	b, err := d.GetData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Metadata
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("while decoding metadata: %v", err)
+	}
+	if got != want {
+		t.Errorf("got metadata %+v, want %+v", got, want)
+	}
+}
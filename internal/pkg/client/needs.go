@@ -0,0 +1,97 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sylabs/singularity/pkg/util/capabilities"
+)
+
+const (
+	// CapabilitiesLabel is the label of an OCI image config declaring the
+	// capabilities the container needs, as a comma separated list, following
+	// the convention of Podman.
+	CapabilitiesLabel = "io.containers.capabilities"
+	// SeccompLabel is the label of an OCI image config declaring the seccomp
+	// profile the container runs with, or "unconfined", following the
+	// convention of Podman.
+	SeccompLabel = "io.containers.seccomp.profile"
+)
+
+// Needs are the runtime needs an image declares.
+type Needs struct {
+	// Capabilities are the capabilities needed, normalized, e.g. CAP_NET_ADMIN.
+	Capabilities []string
+	// Unknown are the capabilities declared that are not known.
+	Unknown []string
+	// Seccomp is the seccomp profile declared, if any.
+	Seccomp string
+}
+
+// ParseNeeds returns the needs declared by the labels of an OCI image config.
+func ParseNeeds(labels map[string]string) Needs {
+	var n Needs
+	if caps := strings.TrimSpace(labels[CapabilitiesLabel]); caps != "" {
+		n.Capabilities, n.Unknown = capabilities.Split(caps)
+	}
+	n.Seccomp = strings.TrimSpace(labels[SeccompLabel])
+	return n
+}
+
+// ImageNeeds returns the needs declared by the OCI image config of the SIF
+// image at path. It returns false if the SIF holds no OCI image config, as
+// for images that were not converted from docker/OCI images.
+func ImageNeeds(path string) (Needs, bool, error) {
+	conf, err := imageConfig(path)
+	if conf == nil || err != nil {
+		return Needs{}, false, err
+	}
+	return ParseNeeds(conf.Labels), true, nil
+}
+
+// HostPolicy is what the host allows a container to use.
+type HostPolicy struct {
+	// AllCapabilities is set if any capability can be granted, as to root.
+	AllCapabilities bool
+	// Capabilities are the capabilities that can be granted, otherwise.
+	Capabilities []string
+	// Seccomp is set if seccomp filters can be applied.
+	Seccomp bool
+}
+
+// Conflicts returns a description of each need of n that the host policy p
+// doesn't allow, or nil if they are all allowed.
+func (n Needs) Conflicts(p HostPolicy) []string {
+	var conflicts []string
+
+	if !p.AllCapabilities {
+		allowed := make(map[string]bool, len(p.Capabilities))
+		for _, c := range p.Capabilities {
+			allowed[c] = true
+		}
+		var forbidden []string
+		for _, c := range n.Capabilities {
+			if !allowed[c] {
+				forbidden = append(forbidden, c)
+			}
+		}
+		if len(forbidden) > 0 {
+			sort.Strings(forbidden)
+			conflicts = append(conflicts, fmt.Sprintf("capabilities not allowed: %s", strings.Join(forbidden, ",")))
+		}
+	}
+	if len(n.Unknown) > 0 {
+		conflicts = append(conflicts, fmt.Sprintf("unknown capabilities: %s", strings.Join(n.Unknown, ",")))
+	}
+
+	if n.Seccomp != "" && n.Seccomp != "unconfined" && !p.Seccomp {
+		conflicts = append(conflicts, fmt.Sprintf("seccomp profile %s can't be applied, seccomp is not supported", n.Seccomp))
+	}
+	return conflicts
+}
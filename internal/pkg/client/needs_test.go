@@ -0,0 +1,72 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNeedsConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		policy HostPolicy
+		want   []string
+	}{
+		{
+			name:   "NoNeeds",
+			policy: HostPolicy{},
+		},
+		{
+			name:   "Allowed",
+			labels: map[string]string{CapabilitiesLabel: "net_admin, CAP_SYS_PTRACE"},
+			policy: HostPolicy{Capabilities: []string{"CAP_NET_ADMIN", "CAP_SYS_PTRACE"}},
+		},
+		{
+			name:   "AllAllowed",
+			labels: map[string]string{CapabilitiesLabel: "CAP_SYS_ADMIN"},
+			policy: HostPolicy{AllCapabilities: true},
+		},
+		{
+			name:   "Forbidden",
+			labels: map[string]string{CapabilitiesLabel: "CAP_SYS_PTRACE,CAP_NET_ADMIN,CAP_CHOWN"},
+			policy: HostPolicy{Capabilities: []string{"CAP_CHOWN"}},
+			want:   []string{"capabilities not allowed: CAP_NET_ADMIN,CAP_SYS_PTRACE"},
+		},
+		{
+			name:   "Unknown",
+			labels: map[string]string{CapabilitiesLabel: "CAP_FLY"},
+			policy: HostPolicy{AllCapabilities: true},
+			want:   []string{"unknown capabilities: CAP_FLY"},
+		},
+		{
+			name:   "SeccompUnsupported",
+			labels: map[string]string{SeccompLabel: "/usr/share/containers/seccomp.json"},
+			policy: HostPolicy{},
+			want:   []string{"seccomp profile /usr/share/containers/seccomp.json can't be applied, seccomp is not supported"},
+		},
+		{
+			name:   "SeccompSupported",
+			labels: map[string]string{SeccompLabel: "/usr/share/containers/seccomp.json"},
+			policy: HostPolicy{Seccomp: true},
+		},
+		{
+			name:   "Unconfined",
+			labels: map[string]string{SeccompLabel: "unconfined"},
+			policy: HostPolicy{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseNeeds(tt.labels).Conflicts(tt.policy)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,22 +11,48 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"mime"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
+	keyclient "github.com/sylabs/scs-key-client/client"
 	"github.com/sylabs/singularity/internal/pkg/cache"
 	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/pkg/sylog"
+	"github.com/sylabs/singularity/pkg/sypgp"
 	useragent "github.com/sylabs/singularity/pkg/util/user-agent"
 )
 
 // Timeout for an image pull in seconds - could be a large download...
 const pullTimeout = 1800
 
+// PullOptions holds options for pulling an http(s) image to a file.
+type PullOptions struct {
+	// TmpDir is the location for temporary files.
+	TmpDir string
+	// Signature is the URL or path of a detached OpenPGP signature of the
+	// image. The image is only verified if it is set.
+	Signature string
+	// KeyClientOpts configures the keyserver client used for verification.
+	KeyClientOpts []keyclient.Option
+	// AllowUnsigned keeps the image, with a warning, if its signature
+	// can't be verified.
+	AllowUnsigned bool
+	// SuggestedName renames the image, once pulled, to the filename
+	// suggested by the server through the Content-Disposition header, in
+	// the directory of the destination. An existing file of that name is
+	// only replaced if Overwrite is set.
+	SuggestedName bool
+	Overwrite     bool
+}
+
 // IsNetPullRef returns true if the provided string is a valid url
 // reference for a pull operation.
 func IsNetPullRef(netRef string) bool {
@@ -37,8 +63,16 @@ func IsNetPullRef(netRef string) bool {
 // DownloadImage will retrieve an image from an http(s) URI,
 // saving it into the specified file
 func DownloadImage(ctx context.Context, filePath string, netURL string) error {
+	_, err := download(ctx, filePath, netURL)
+	return err
+}
+
+// download retrieves an image from an http(s) URI, saving it into the
+// specified file, and returns the filename suggested by the server in the
+// Content-Disposition header of the response, or "" if there is none.
+func download(ctx context.Context, filePath string, netURL string) (string, error) {
 	if !IsNetPullRef(netURL) {
-		return fmt.Errorf("not a valid url reference: %s", netURL)
+		return "", fmt.Errorf("not a valid url reference: %s", netURL)
 	}
 	if filePath == "" {
 		refParts := strings.Split(netURL, "/")
@@ -50,31 +84,33 @@ func DownloadImage(ctx context.Context, filePath string, netURL string) error {
 	sylog.Debugf("Pulling from URL: %s\n", url)
 
 	httpClient := &http.Client{
-		Timeout: pullTimeout * time.Second,
+		Transport:     client.Transport(),
+		Timeout:       pullTimeout * time.Second,
+		CheckRedirect: client.CheckRedirect(client.MaxRedirectsFromContext(ctx)),
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	req.Header.Set("User-Agent", useragent.Value())
 
 	res, err := httpClient.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode == http.StatusNotFound {
-		return fmt.Errorf("the requested image was not found")
+		return "", fmt.Errorf("the requested image was not found")
 	}
 
 	if res.StatusCode != http.StatusOK {
 		buf := new(bytes.Buffer)
 		buf.ReadFrom(res.Body)
 		s := buf.String()
-		return fmt.Errorf("Download did not succeed: %d %s\n\t",
+		return "", fmt.Errorf("Download did not succeed: %d %s\n\t",
 			res.StatusCode, s)
 	}
 
@@ -83,7 +119,7 @@ func DownloadImage(ctx context.Context, filePath string, netURL string) error {
 	// Perms are 777 *prior* to umask
 	out, err := os.OpenFile(filePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o777)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer out.Close()
 
@@ -100,16 +136,64 @@ func DownloadImage(ctx context.Context, filePath string, netURL string) error {
 		if err := os.Remove(filePath); err != nil {
 			sylog.Errorf("Error while removing incomplete download: %v", err)
 		}
-		return err
+		return "", err
 	}
 
 	sylog.Debugf("Download complete\n")
 
-	return nil
+	return suggestedFilename(res.Header), nil
+}
+
+// suggestedFilename returns the sanitized filename suggested by the server in
+// the Content-Disposition header h of a response. An empty string is returned
+// if the server doesn't suggest a usable filename.
+func suggestedFilename(h http.Header) string {
+	cd := h.Get("Content-Disposition")
+	if cd != "" {
+		sylog.Debugf("HTTP Content-Disposition header is: %s", cd)
+	}
+	return filenameFromContentDisposition(cd)
+}
+
+// filenameFromContentDisposition extracts the filename parameter from a
+// Content-Disposition header value, and sanitizes it so that it can only
+// refer to a file in the current directory.
+func filenameFromContentDisposition(cd string) string {
+	if cd == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(cd)
+	if err != nil {
+		sylog.Debugf("Ignoring malformed Content-Disposition header: %v", err)
+		return ""
+	}
+	// mime.ParseMediaType decodes an RFC 2231 filename* into filename.
+	return sanitizeFilename(params["filename"])
+}
+
+// sanitizeFilename strips any directory components from name, and rejects
+// names that are hidden, refer to a directory, or contain control characters.
+func sanitizeFilename(name string) string {
+	// Treat backslashes as separators, so that Windows paths can't be used
+	// to escape the destination directory either.
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(path.Clean("/" + name))
+
+	if name == "/" || name == "." || name == ".." || strings.HasPrefix(name, ".") {
+		return ""
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return ""
+		}
+	}
+	return name
 }
 
 // pull will pull a http(s) image into the cache if directTo="", or a specific file if directTo is set.
-func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string) (imagePath string, err error) {
+// The filename suggested by the server, if any, is returned too: through the
+// response to the download, or to the HEAD request if the image is cached.
+func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string) (imagePath, suggested string, err error) {
 	// We will cache using a sha256 over the URL and the date of the file that
 	// is to be fetched, as returned by an HTTP HEAD call and the Last-Modified
 	// header. If no date is available, use the current date-time, which will
@@ -120,10 +204,11 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 	if err != nil {
 		sylog.Fatalf("Error constructing http request: %v\n", err)
 	}
-	res, err := http.DefaultClient.Do(req)
+	res, err := client.HTTPClient(ctx).Do(req)
 	if err != nil {
 		sylog.Fatalf("Error making http request: %v\n", err)
 	}
+	res.Body.Close()
 
 	headerDate := res.Header.Get("Last-Modified")
 	sylog.Debugf("HTTP Last-Modified header is: %s", headerDate)
@@ -138,38 +223,40 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 
 	if directTo != "" {
 		sylog.Infof("Downloading network image")
-		if err := DownloadImage(ctx, directTo, pullFrom); err != nil {
-			return "", fmt.Errorf("unable to Download Image: %v", err)
+		suggested, err = download(ctx, directTo, pullFrom)
+		if err != nil {
+			return "", "", fmt.Errorf("unable to Download Image: %v", err)
 		}
 		imagePath = directTo
 
 	} else {
 		cacheEntry, err := imgCache.GetEntry(cache.NetCacheType, hash)
 		if err != nil {
-			return "", fmt.Errorf("unable to check if %v exists in cache: %v", hash, err)
+			return "", "", fmt.Errorf("unable to check if %v exists in cache: %v", hash, err)
 		}
 		defer cacheEntry.CleanTmp()
 
 		if !cacheEntry.Exists {
 			sylog.Infof("Downloading network image")
-			err := DownloadImage(ctx, cacheEntry.TmpPath, pullFrom)
+			suggested, err = download(ctx, cacheEntry.TmpPath, pullFrom)
 			if err != nil {
 				sylog.Fatalf("%v\n", err)
 			}
 
 			err = cacheEntry.Finalize()
 			if err != nil {
-				return "", err
+				return "", "", err
 			}
 
 		} else {
 			sylog.Verbosef("Using image from cache")
+			suggested = suggestedFilename(res.Header)
 		}
 
 		imagePath = cacheEntry.Path
 	}
 
-	return imagePath, nil
+	return imagePath, suggested, nil
 }
 
 // Pull will pull a http(s) image to the cache or direct to a temporary file if cache is disabled
@@ -185,22 +272,38 @@ func Pull(ctx context.Context, imgCache *cache.Handle, pullFrom string, tmpDir s
 		sylog.Infof("Downloading library image to tmp cache: %s", directTo)
 	}
 
-	return pull(ctx, imgCache, directTo, pullFrom)
+	imagePath, _, err = pull(ctx, imgCache, directTo, pullFrom)
+	return imagePath, err
 }
 
 // PullToFile will pull an http(s) image to the specified location, through the cache, or directly if cache is disabled
-func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom, tmpDir string) (imagePath string, err error) {
+func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	directTo := ""
 	if imgCache.IsDisabled() {
 		directTo = pullTo
 		sylog.Debugf("Cache disabled, pulling directly to: %s", directTo)
 	}
 
-	src, err := pull(ctx, imgCache, directTo, pullFrom)
+	src, suggested, err := pull(ctx, imgCache, directTo, pullFrom)
 	if err != nil {
 		return "", fmt.Errorf("error fetching image to cache: %v", err)
 	}
 
+	if opts.Signature != "" {
+		if err := verify(ctx, src, pullFrom, opts); err != nil {
+			if !opts.AllowUnsigned {
+				// Don't keep, or serve from the cache later, an image
+				// which may have been tampered with.
+				if err := os.Remove(src); err != nil {
+					sylog.Errorf("Error while removing unverified image: %v", err)
+				}
+				return "", err
+			}
+			sylog.Warningf("%v", err)
+			sylog.Warningf("Keeping unverified image as requested with --allow-unsigned")
+		}
+	}
+
 	if directTo == "" {
 		// mode is before umask if pullTo doesn't exist
 		err = fs.CopyFileAtomic(src, pullTo, 0o777)
@@ -209,5 +312,51 @@ func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom, t
 		}
 	}
 
+	if opts.SuggestedName && suggested != "" {
+		return renameToSuggested(pullTo, suggested, opts.Overwrite)
+	}
 	return pullTo, nil
 }
+
+// renameToSuggested renames the image pulled to pullTo to the filename
+// suggested by the server, in the same directory, and returns its new path.
+// The image is left at pullTo, with a warning, if a file of that name exists
+// and overwrite is not set.
+func renameToSuggested(pullTo, suggested string, overwrite bool) (string, error) {
+	dest := filepath.Join(filepath.Dir(pullTo), suggested)
+	if dest == filepath.Clean(pullTo) {
+		return pullTo, nil
+	}
+	if _, err := os.Stat(dest); err == nil && !overwrite {
+		sylog.Warningf("Keeping image at %s, as the server suggested filename %s already exists", pullTo, dest)
+		return pullTo, nil
+	}
+
+	if err := os.Rename(pullTo, dest); err != nil {
+		return "", fmt.Errorf("while renaming image to server suggested filename: %v", err)
+	}
+	sylog.Infof("Using server suggested filename: %s", suggested)
+	return dest, nil
+}
+
+// verify checks the detached signature opts.Signature of the image at
+// imagePath, pulled from pullFrom, and reports its signer.
+func verify(ctx context.Context, imagePath, pullFrom string, opts PullOptions) error {
+	kr, err := sypgp.NewHybridKeyRing(ctx, opts.KeyClientOpts...)
+	if err != nil {
+		return fmt.Errorf("could not load keyring: %v", err)
+	}
+
+	sylog.Infof("Verifying %s against detached signature %s", pullFrom, opts.Signature)
+	signer, err := verifyDetachedSignature(ctx, imagePath, opts.Signature, kr)
+	if err != nil {
+		return fmt.Errorf("signature verification of %s failed: %v", pullFrom, err)
+	}
+
+	name := ""
+	if id := signer.PrimaryIdentity(); id != nil {
+		name = id.Name
+	}
+	sylog.Infof("Signature verified, signed by %s (%X)", name, signer.PrimaryKey.Fingerprint)
+	return nil
+}
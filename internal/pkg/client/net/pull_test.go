@@ -0,0 +1,115 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package net
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFilenameFromContentDisposition(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"no filename", "attachment", ""},
+		{"simple", `attachment; filename="image.sif"`, "image.sif"},
+		{"unquoted", "attachment; filename=image.sif", "image.sif"},
+		{"rfc2231", "attachment; filename*=UTF-8''caf%C3%A9.sif", "café.sif"},
+		{"malformed", `attachment; filename="image.sif`, ""},
+		{"relative traversal", `attachment; filename="../../etc/passwd"`, "passwd"},
+		{"absolute path", `attachment; filename="/etc/cron.d/job"`, "job"},
+		{"windows traversal", `attachment; filename="..\\..\\image.sif"`, "image.sif"},
+		{"dot dot", `attachment; filename=".."`, ""},
+		{"hidden", `attachment; filename=".bashrc"`, ""},
+		{"trailing slash", `attachment; filename="dir/"`, "dir"},
+		{"control character", "attachment; filename*=UTF-8''image%0A.sif", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n := filenameFromContentDisposition(tt.header); n != tt.expected {
+				t.Errorf("got filename %q (expected %q)", n, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPullSuggestedName(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.Method]++
+		mu.Unlock()
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Disposition", `attachment; filename="alpine.sif"`)
+		}
+		w.Write([]byte("image"))
+	}))
+	defer srv.Close()
+
+	directTo := filepath.Join(t.TempDir(), "download")
+	_, suggested, err := pull(context.Background(), nil, directTo, srv.URL+"/download")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if suggested != "alpine.sif" {
+		t.Errorf("got suggested filename %q, want %q", suggested, "alpine.sif")
+	}
+	// The filename is read from the download, without another request.
+	mu.Lock()
+	defer mu.Unlock()
+	if requests[http.MethodHead] != 1 || requests[http.MethodGet] != 1 {
+		t.Errorf("got requests %v, want one HEAD and one GET", requests)
+	}
+}
+
+func TestRenameToSuggested(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  bool
+		overwrite bool
+		expected  string
+	}{
+		{"renamed", false, false, "alpine.sif"},
+		{"existing kept", true, false, "download"},
+		{"existing overwritten", true, true, "alpine.sif"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			pullTo := filepath.Join(dir, "download")
+			if err := os.WriteFile(pullTo, []byte("image"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, "alpine.sif"), []byte("other"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			dest, err := renameToSuggested(pullTo, "alpine.sif", tt.overwrite)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := filepath.Join(dir, tt.expected); dest != want {
+				t.Errorf("got destination %q (expected %q)", dest, want)
+			}
+			if b, err := os.ReadFile(dest); err != nil || string(b) != "image" {
+				t.Errorf("image not at destination %q: %q, %v", dest, b, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package net
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	useragent "github.com/sylabs/singularity/pkg/util/user-agent"
+)
+
+// maxSignatureSize is the maximum size of a detached signature read from a
+// URL. Real signatures are a few hundred bytes.
+const maxSignatureSize = 1 << 20
+
+// fetchSignature returns the content of the detached signature at sigSource,
+// which is either an http(s) URL or a local path.
+func fetchSignature(ctx context.Context, sigSource string) ([]byte, error) {
+	if !IsNetPullRef(sigSource) {
+		return os.ReadFile(sigSource)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sigSource, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", useragent.Value())
+
+	res, err := client.HTTPClient(ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download did not succeed: %s", res.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxSignatureSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxSignatureSize {
+		return nil, fmt.Errorf("signature is larger than %d bytes", maxSignatureSize)
+	}
+	return b, nil
+}
+
+// verifyDetachedSignature checks the OpenPGP detached signature, armored or
+// not, read from sigSource against the content of the file at imagePath. The
+// entity that made the signature is returned if it is valid and its key is
+// found in kr.
+func verifyDetachedSignature(ctx context.Context, imagePath, sigSource string, kr openpgp.KeyRing) (*openpgp.Entity, error) {
+	sig, err := fetchSignature(ctx, sigSource)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signature %s: %v", sigSource, err)
+	}
+
+	f, err := os.Open(imagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte("-----BEGIN PGP SIGNATURE-----")) {
+		return openpgp.CheckArmoredDetachedSignature(kr, f, bytes.NewReader(sig), nil)
+	}
+	return openpgp.CheckDetachedSignature(kr, f, bytes.NewReader(sig), nil)
+}
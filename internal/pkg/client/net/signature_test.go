@@ -0,0 +1,93 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package net
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	useragent "github.com/sylabs/singularity/pkg/util/user-agent"
+)
+
+func init() {
+	useragent.InitValue("singularity", "3.0.0")
+}
+
+func TestVerifyDetachedSignature(t *testing.T) {
+	signer, err := openpgp.NewEntity("Signer", "", "signer@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("Other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "image.sif")
+	content := []byte("image content")
+	if err := os.WriteFile(image, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tampered := filepath.Join(dir, "tampered.sif")
+	if err := os.WriteFile(tampered, []byte("tampered content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var binSig, armoredSig bytes.Buffer
+	if err := openpgp.DetachSign(&binSig, signer, bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := openpgp.ArmoredDetachSign(&armoredSig, signer, bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	sigPath := filepath.Join(dir, "image.sif.sig")
+	if err := os.WriteFile(sigPath, binSig.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/image.sif.asc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(armoredSig.Bytes())
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		image   string
+		sig     string
+		kr      openpgp.EntityList
+		wantErr bool
+	}{
+		{name: "LocalBinary", image: image, sig: sigPath, kr: openpgp.EntityList{signer}},
+		{name: "RemoteArmored", image: image, sig: srv.URL + "/image.sif.asc", kr: openpgp.EntityList{signer}},
+		{name: "Tampered", image: tampered, sig: sigPath, kr: openpgp.EntityList{signer}, wantErr: true},
+		{name: "UnknownKey", image: image, sig: sigPath, kr: openpgp.EntityList{other}, wantErr: true},
+		{name: "MissingLocal", image: image, sig: filepath.Join(dir, "missing.sig"), kr: openpgp.EntityList{signer}, wantErr: true},
+		{name: "MissingRemote", image: image, sig: srv.URL + "/missing.sig", kr: openpgp.EntityList{signer}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := verifyDetachedSignature(context.Background(), tt.image, tt.sig, tt.kr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && e.PrimaryKey.KeyId != signer.PrimaryKey.KeyId {
+				t.Errorf("unexpected signer %X", e.PrimaryKey.Fingerprint)
+			}
+		})
+	}
+}
@@ -8,13 +8,19 @@ package oci
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	ocitypes "github.com/containers/image/v5/types"
 	"github.com/sylabs/singularity/internal/pkg/build"
 	"github.com/sylabs/singularity/internal/pkg/build/oci"
 	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	buildtypes "github.com/sylabs/singularity/pkg/build/types"
 	"github.com/sylabs/singularity/pkg/syfs"
@@ -28,10 +34,126 @@ type PullOptions struct {
 	DockerHost string
 	NoHTTPS    bool
 	NoCleanUp  bool
+	// PreferCached uses a SIF previously built for the same reference from
+	// the cache, if present, without checking the remote digest.
+	PreferCached bool
+	// ImportAnnotations lists the manifest and config annotation keys to import as
+	// labels of the SIF.
+	ImportAnnotations []string
+	// NoXattrs removes extended attributes from the extracted files.
+	NoXattrs bool
+	// NormalizePerms sets the permissions of the extracted files to 0755
+	// or 0644.
+	NormalizePerms bool
+	// PostScript is run with /bin/sh in the extracted root filesystem, as
+	// the %post section of a build, before the SIF is created.
+	PostScript string
+	// BuildTime, if set, is used instead of the current time in the SIF.
+	BuildTime time.Time
+	// Architecture and Variant select the platform of the image to pull
+	// from a multi-platform image, instead of the host platform, if set.
+	Architecture string
+	Variant      string
+	// MaxLayers, if set, is the maximum number of layers of an image to
+	// convert. The conversion of an image with more layers fails.
+	MaxLayers int
+	// DetectOS records the OS distribution of the image, read from its
+	// os-release file, as labels of the SIF.
+	DetectOS bool
+	// RecordCreated records when the image was created, from the created
+	// field of its config, as a label of the SIF.
+	RecordCreated bool
+	// ExcludePaths lists patterns of the absolute paths of the files and
+	// directories not to include in the SIF.
+	ExcludePaths []string
+	// Dedup stores files with identical content and metadata in the image as
+	// hardlinks to a single inode.
+	Dedup bool
+	// ExportRootfs, if set, is the path the root filesystem of the image is
+	// written to as a tar archive, gzip compressed if ExportGzip is set. The
+	// image is always converted then, without using the cache.
+	ExportRootfs string
+	ExportGzip   bool
+	// TmpfsWork extracts and converts the image in a tmpfs-backed work
+	// directory, limited to TmpfsSize bytes, or half of the available
+	// memory if 0. The conversion falls back to TmpDir, with a warning, if
+	// there is not enough memory for the estimated size of the image.
+	TmpfsWork bool
+	TmpfsSize int64
+	// SetArch, if set, is the architecture recorded in the SIF, instead of
+	// the architecture of the executables of the image, or of its platform.
+	// The conversion fails if it doesn't match the executables.
+	SetArch string
+	// RawSymlinks keeps the symlinks of the image as they are, instead of
+	// rewriting those that resolve outside of its root filesystem when
+	// followed from the host.
+	RawSymlinks bool
+	// AllowedRegistries, if set, lists the hosts of the only registries
+	// images can be pulled from, among those allowed by the site.
+	AllowedRegistries []string
 }
 
-// pull will build a SIF image into the cache if directTo="", or a specific file if directTo is set.
-func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
+// tmpfsWorkFactor is the ratio of the space used to convert an image, for its
+// extracted root file system and squashfs, to the size of its compressed
+// layers.
+const tmpfsWorkFactor = 4
+
+// cacheVariant returns a suffix identifying the options used to build a SIF,
+// to be appended to its key in the cache. Options that alter the content of
+// the SIF must be reflected here, so that SIFs built from the same image with
+// different options don't collide.
+func (opts PullOptions) cacheVariant() string {
+	var variant []string
+	if len(opts.ImportAnnotations) > 0 {
+		keys := append([]string{}, opts.ImportAnnotations...)
+		sort.Strings(keys)
+		variant = append(variant, "annotations="+strings.Join(keys, ","))
+	}
+	if opts.NoXattrs {
+		variant = append(variant, "no-xattrs")
+	}
+	if opts.NormalizePerms {
+		variant = append(variant, "normalize-perms")
+	}
+	if opts.PostScript != "" {
+		sum := sha256.Sum256([]byte(opts.PostScript))
+		variant = append(variant, "post-script="+hex.EncodeToString(sum[:]))
+	}
+	if !opts.BuildTime.IsZero() {
+		variant = append(variant, fmt.Sprintf("build-time=%d", opts.BuildTime.Unix()))
+	}
+	if opts.Architecture != "" {
+		variant = append(variant, "platform="+opts.Architecture+"/"+opts.Variant)
+	}
+	if opts.DetectOS {
+		variant = append(variant, "detect-os")
+	}
+	if opts.RecordCreated {
+		variant = append(variant, "created")
+	}
+	if len(opts.ExcludePaths) > 0 {
+		patterns := append([]string{}, opts.ExcludePaths...)
+		sort.Strings(patterns)
+		variant = append(variant, "exclude="+strings.Join(patterns, ","))
+	}
+	if opts.Dedup {
+		variant = append(variant, "dedup")
+	}
+	if opts.SetArch != "" {
+		variant = append(variant, "set-arch="+opts.SetArch)
+	}
+	if opts.RawSymlinks {
+		variant = append(variant, "raw-symlinks")
+	}
+	if len(variant) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(variant, ";")))
+	return "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// systemContext returns the containers/image configuration for opts.
+func (opts PullOptions) systemContext() *ocitypes.SystemContext {
 	// DockerInsecureSkipTLSVerify is set only if --no-https is specified to honor
 	// configuration from /etc/containers/registries.conf because DockerInsecureSkipTLSVerify
 	// can have three possible values true/false and undefined, so we left it as undefined instead
@@ -40,27 +162,60 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 	sysCtx := &ocitypes.SystemContext{
 		OCIInsecureSkipTLSVerify: opts.NoHTTPS,
 		DockerAuthConfig:         opts.OciAuth,
+		DockerDaemonHost:         opts.DockerHost,
 		AuthFilePath:             syfs.DockerConf(),
 		DockerRegistryUserAgent:  useragent.Value(),
 		BigFilesTemporaryDir:     opts.TmpDir,
+		ArchitectureChoice:       opts.Architecture,
+		VariantChoice:            opts.Variant,
 	}
 	if opts.NoHTTPS {
 		sysCtx.DockerInsecureSkipTLSVerify = ocitypes.NewOptionalBool(true)
 	}
+	return sysCtx
+}
+
+// pull will build a SIF image into the cache if directTo="", or a specific file if directTo is set.
+func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
+	if err := checkRegistry(pullFrom, opts); err != nil {
+		return "", err
+	}
 
-	hash, err := oci.ImageDigest(ctx, pullFrom, sysCtx)
+	if directTo == "" && opts.PreferCached {
+		cacheEntry, err := imgCache.GetReferenceEntry(cache.OciTempCacheType, pullFrom+opts.cacheVariant())
+		if err != nil {
+			sylog.Debugf("Could not look up %s in cache: %v", pullFrom, err)
+		} else if cacheEntry != nil {
+			sylog.Warningf("Using cached SIF image for %s without checking for a newer version, it may be stale", pullFrom)
+			return cacheEntry.Path, nil
+		}
+		sylog.Debugf("No cached SIF image for %s, pulling", pullFrom)
+	}
+
+	hash, err := oci.ImageDigest(ctx, pullFrom, opts.systemContext())
 	if err != nil {
 		return "", fmt.Errorf("failed to get checksum for %s: %s", pullFrom, err)
 	}
 
+	// The source is recorded in the SIF, so that IsCurrent can check it
+	// without reading the SIF.
+	source := client.PullSource{
+		Digest:  strings.Replace(hash, ".", ":", 1),
+		Variant: opts.cacheVariant(),
+	}
+
 	if directTo != "" {
 		sylog.Infof("Converting OCI blobs to SIF format")
 		if err := convertOciToSIF(ctx, imgCache, pullFrom, directTo, opts); err != nil {
 			return "", fmt.Errorf("while building SIF from layers: %v", err)
 		}
+		if err := client.WritePullSource(directTo, source); err != nil {
+			return "", fmt.Errorf("while recording source of SIF: %v", err)
+		}
 		imagePath = directTo
 	} else {
 
+		hash += opts.cacheVariant()
 		cacheEntry, err := imgCache.GetEntry(cache.OciTempCacheType, hash)
 		if err != nil {
 			return "", fmt.Errorf("unable to check if %v exists in cache: %v", hash, err)
@@ -72,6 +227,9 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 			if err := convertOciToSIF(ctx, imgCache, pullFrom, cacheEntry.TmpPath, opts); err != nil {
 				return "", fmt.Errorf("while building SIF from layers: %v", err)
 			}
+			if err := client.WritePullSource(cacheEntry.TmpPath, source); err != nil {
+				return "", fmt.Errorf("while recording source of SIF: %v", err)
+			}
 
 			err = cacheEntry.Finalize()
 			if err != nil {
@@ -82,6 +240,10 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 			sylog.Infof("Using cached SIF image")
 		}
 		imagePath = cacheEntry.Path
+
+		if err := imgCache.PutReference(cache.OciTempCacheType, pullFrom+opts.cacheVariant(), hash); err != nil {
+			sylog.Debugf("Could not record %s in cache: %v", pullFrom, err)
+		}
 	}
 
 	return imagePath, nil
@@ -93,23 +255,64 @@ func convertOciToSIF(ctx context.Context, imgCache *cache.Handle, image, cachedI
 		return fmt.Errorf("image cache is undefined")
 	}
 
-	b, err := build.NewBuild(
-		image,
-		build.Config{
-			Dest:      cachedImgPath,
-			Format:    "sif",
-			NoCleanUp: opts.NoCleanUp,
-			Opts: buildtypes.Options{
-				TmpDir:           opts.TmpDir,
-				NoCache:          imgCache.IsDisabled(),
-				NoTest:           true,
-				NoHTTPS:          opts.NoHTTPS,
-				DockerAuthConfig: opts.OciAuth,
-				DockerDaemonHost: opts.DockerHost,
-				ImgCache:         imgCache,
-			},
+	if opts.MaxLayers > 0 {
+		if err := checkLayerCount(ctx, image, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.TmpfsWork {
+		dir, cleanup := tmpfsWorkDir(ctx, image, opts)
+		if cleanup != nil {
+			defer cleanup()
+			opts.TmpDir = dir
+		}
+	}
+
+	client.ReportPhase(ctx, client.PhaseConvert)
+
+	conf := build.Config{
+		Dest:      cachedImgPath,
+		Format:    "sif",
+		NoCleanUp: opts.NoCleanUp,
+		Opts: buildtypes.Options{
+			TmpDir:           opts.TmpDir,
+			NoCache:          imgCache.IsDisabled(),
+			NoTest:           true,
+			NoHTTPS:          opts.NoHTTPS,
+			DockerAuthConfig: opts.OciAuth,
+			DockerDaemonHost: opts.DockerHost,
+			ImgCache:         imgCache,
+
+			ImportAnnotations: opts.ImportAnnotations,
+			NoXattrs:          opts.NoXattrs,
+			NormalizePerms:    opts.NormalizePerms,
+			BuildTime:         opts.BuildTime,
+			Architecture:      opts.Architecture,
+			Variant:           opts.Variant,
+			DetectOS:          opts.DetectOS,
+			RecordCreated:     opts.RecordCreated,
+			ExcludePaths:      opts.ExcludePaths,
+			Dedup:             opts.Dedup,
+			ExportRootfs:      opts.ExportRootfs,
+			ExportRootfsGzip:  opts.ExportGzip,
+			SetArch:           opts.SetArch,
+			ConfineSymlinks:   !opts.RawSymlinks,
 		},
-	)
+	}
+
+	var b *build.Build
+	var err error
+	if opts.PostScript == "" {
+		b, err = build.NewBuild(image, conf)
+	} else {
+		var def buildtypes.Definition
+		def, err = buildtypes.NewDefinitionFromURIWithPost(image, opts.PostScript)
+		if err != nil {
+			return fmt.Errorf("unable to parse spec %v: %v", image, err)
+		}
+		b, err = build.New([]buildtypes.Definition{def}, conf)
+	}
 	if err != nil {
 		return fmt.Errorf("unable to create new build: %v", err)
 	}
@@ -117,6 +320,43 @@ func convertOciToSIF(ctx context.Context, imgCache *cache.Handle, image, cachedI
 	return b.Full(ctx)
 }
 
+// tmpfsWorkDir returns a tmpfs-backed work directory to convert image in, and
+// the function to remove it, or a nil function, with a warning, if it can't be
+// created for the estimated size of the image.
+func tmpfsWorkDir(ctx context.Context, image string, opts PullOptions) (string, func()) {
+	_, layers, err := oci.ImageLayers(ctx, image, opts.systemContext())
+	if err != nil {
+		sylog.Warningf("Could not estimate size of %s, converting on disk: %v", image, err)
+		return "", nil
+	}
+	var size int64
+	for _, l := range layers {
+		size += l.Size
+	}
+
+	dir, cleanup, err := client.TmpfsWorkDir(opts.TmpDir, size*tmpfsWorkFactor, opts.TmpfsSize)
+	if err != nil {
+		sylog.Warningf("Could not use tmpfs work directory, converting on disk: %v", err)
+		return "", nil
+	}
+	sylog.Infof("Converting in tmpfs work directory %s", dir)
+	return dir, cleanup
+}
+
+// checkLayerCount checks that the number of layers of image is within
+// opts.MaxLayers, before any layer is fetched.
+func checkLayerCount(ctx context.Context, image string, opts PullOptions) error {
+	n, err := oci.LayerCount(ctx, image, opts.systemContext())
+	if err != nil {
+		return fmt.Errorf("while counting layers of %s: %v", image, err)
+	}
+	if n <= opts.MaxLayers {
+		sylog.Infof("Image %s has %d layers, within the maximum of %d", image, n, opts.MaxLayers)
+		return nil
+	}
+	return fmt.Errorf("image %s has %d layers, over the maximum of %d", image, n, opts.MaxLayers)
+}
+
 // Pull will build a SIF image to the cache or direct to a temporary file if cache is disabled
 func Pull(ctx context.Context, imgCache *cache.Handle, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	directTo := ""
@@ -139,6 +379,10 @@ func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom st
 	if imgCache.IsDisabled() {
 		directTo = pullTo
 		sylog.Debugf("Cache disabled, pulling directly to: %s", directTo)
+	} else if opts.ExportRootfs != "" {
+		// The root filesystem is only extracted on conversion.
+		directTo = pullTo
+		sylog.Debugf("Exporting root filesystem, pulling directly to: %s", directTo)
 	}
 
 	src, err := pull(ctx, imgCache, directTo, pullFrom, opts)
@@ -156,3 +400,88 @@ func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom st
 
 	return pullTo, nil
 }
+
+// IsCurrent reports whether the SIF at path was converted with opts from the
+// current version of the image pullFrom, from the source recorded in the SIF
+// when it was pulled. A SIF without a recorded source, e.g. pulled by an older
+// version, is not current. The digest of the current version of the image is
+// also returned.
+func IsCurrent(ctx context.Context, path, pullFrom string, opts PullOptions) (bool, string, error) {
+	if err := checkRegistry(pullFrom, opts); err != nil {
+		return false, "", err
+	}
+	hash, err := oci.ImageDigest(ctx, pullFrom, opts.systemContext())
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get checksum for %s: %s", pullFrom, err)
+	}
+	digest := strings.Replace(hash, ".", ":", 1)
+
+	source, ok, err := client.ReadPullSource(path)
+	if err != nil {
+		return false, digest, err
+	}
+	if !ok {
+		sylog.Debugf("No source recorded in %s, cannot check if it is current", path)
+		return false, digest, nil
+	}
+	return source.Digest == digest && source.Variant == opts.cacheVariant(), digest, nil
+}
+
+// PullAttestations returns the digest of the image at the specified docker
+// URI, e.g. sha256:abc..., and the DSSE envelopes attached to it as
+// attestations.
+func PullAttestations(ctx context.Context, pullFrom string, opts PullOptions) (string, [][]byte, error) {
+	if err := checkRegistry(pullFrom, opts); err != nil {
+		return "", nil, err
+	}
+	digest, envelopes, err := oci.Attestations(ctx, pullFrom, opts.systemContext())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get attestations of %s: %s", pullFrom, err)
+	}
+	return strings.Replace(digest, ".", ":", 1), envelopes, nil
+}
+
+// PullLayers returns the layers of the image at the specified oci URI, from
+// its manifest, without fetching them.
+func PullLayers(ctx context.Context, pullFrom string, opts PullOptions) (client.LayerList, error) {
+	if err := checkRegistry(pullFrom, opts); err != nil {
+		return client.LayerList{}, err
+	}
+	digest, layers, err := oci.ImageLayers(ctx, pullFrom, opts.systemContext())
+	if err != nil {
+		return client.LayerList{}, fmt.Errorf("failed to get layers of %s: %s", pullFrom, err)
+	}
+
+	l := client.LayerList{
+		Source: pullFrom,
+		Digest: strings.Replace(digest, ".", ":", 1),
+		Layers: make([]client.LayerDescriptor, 0, len(layers)),
+	}
+	for _, layer := range layers {
+		l.Layers = append(l.Layers, client.LayerDescriptor{
+			Digest:    layer.Digest,
+			MediaType: layer.MediaType,
+			Size:      layer.Size,
+		})
+	}
+	return l, nil
+}
+
+// PullMetadata returns the metadata of the image at the specified oci URI,
+// fetching its manifest and config but not its layers.
+func PullMetadata(ctx context.Context, pullFrom string, opts PullOptions) (client.Metadata, error) {
+	if err := checkRegistry(pullFrom, opts); err != nil {
+		return client.Metadata{}, err
+	}
+	digest, arch, size, err := oci.ImageInfo(ctx, pullFrom, opts.systemContext())
+	if err != nil {
+		return client.Metadata{}, fmt.Errorf("failed to get image information for %s: %s", pullFrom, err)
+	}
+
+	return client.Metadata{
+		Source:       pullFrom,
+		Digest:       strings.Replace(digest, ".", ":", 1),
+		Architecture: arch,
+		Size:         size,
+	}, nil
+}
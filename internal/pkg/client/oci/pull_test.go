@@ -0,0 +1,70 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"testing"
+)
+
+func TestCacheVariant(t *testing.T) {
+	if v := (PullOptions{TmpDir: "/tmp", NoHTTPS: true}).cacheVariant(); v != "" {
+		t.Errorf("unexpected variant %q for options that don't alter the SIF", v)
+	}
+
+	a := PullOptions{ImportAnnotations: []string{"a", "b"}}.cacheVariant()
+	b := PullOptions{ImportAnnotations: []string{"b", "a"}}.cacheVariant()
+	c := PullOptions{ImportAnnotations: []string{"a"}}.cacheVariant()
+
+	if a == "" || a != b {
+		t.Errorf("variant should be non-empty and independent of key order: %q %q", a, b)
+	}
+	if a == c {
+		t.Errorf("different annotation keys gave the same variant %q", a)
+	}
+
+	x := PullOptions{NoXattrs: true}.cacheVariant()
+	p := PullOptions{NormalizePerms: true}.cacheVariant()
+	if x == "" || p == "" || x == p {
+		t.Errorf("extraction options should give distinct variants: %q %q", x, p)
+	}
+
+	s1 := PullOptions{PostScript: "echo a"}.cacheVariant()
+	s2 := PullOptions{PostScript: "echo b"}.cacheVariant()
+	if s1 == "" || s1 == s2 {
+		t.Errorf("post scripts should give distinct variants: %q %q", s1, s2)
+	}
+
+	arm64 := PullOptions{Architecture: "arm64"}.cacheVariant()
+	armv7 := PullOptions{Architecture: "arm", Variant: "v7"}.cacheVariant()
+	if arm64 == "" || arm64 == armv7 {
+		t.Errorf("platforms should give distinct variants: %q %q", arm64, armv7)
+	}
+
+	if v := (PullOptions{RecordCreated: true}).cacheVariant(); v == "" {
+		t.Errorf("expected a cache variant for RecordCreated")
+	}
+	if v := (PullOptions{DetectOS: true}).cacheVariant(); v == "" {
+		t.Errorf("OS detection, which adds labels, should give a variant")
+	}
+
+	e1 := PullOptions{ExcludePaths: []string{"/usr/share/doc", "/var/cache/*"}}.cacheVariant()
+	e2 := PullOptions{ExcludePaths: []string{"/var/cache/*", "/usr/share/doc"}}.cacheVariant()
+	if e1 == "" || e1 != e2 {
+		t.Errorf("variant should be non-empty and independent of pattern order: %q %q", e1, e2)
+	}
+
+	if v := (PullOptions{Dedup: true}).cacheVariant(); v == "" {
+		t.Errorf("deduplication, which hardlinks files, should give a variant")
+	}
+
+	if v := (PullOptions{SetArch: "arm64"}).cacheVariant(); v == "" || v == arm64 {
+		t.Errorf("architecture override should give a variant distinct from the platform: %q %q", v, arm64)
+	}
+
+	if v := (PullOptions{RawSymlinks: true}).cacheVariant(); v == "" {
+		t.Errorf("raw symlinks, which are otherwise rewritten, should give a variant")
+	}
+}
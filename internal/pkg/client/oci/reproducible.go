@@ -0,0 +1,54 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
+	"github.com/sylabs/singularity/internal/pkg/util/fs"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// PullReproducible converts the image at the specified oci URI to SIF twice,
+// bypassing the SIF cache, and compares the results. If they are identical,
+// the first is placed at pullTo. Otherwise, their differences are returned.
+//
+// opts.BuildTime should be set, so that the build date label and the SIF
+// creation times are the same in both images.
+func PullReproducible(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom string, opts PullOptions) ([]client.SIFDifference, error) {
+	dir, err := os.MkdirTemp(opts.TmpDir, "reproducible-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	paths := []string{filepath.Join(dir, "first.sif"), filepath.Join(dir, "second.sif")}
+	for i, path := range paths {
+		sylog.Infof("Converting OCI blobs to SIF format (%d/%d)", i+1, len(paths))
+		if err := convertOciToSIF(ctx, imgCache, pullFrom, path, opts); err != nil {
+			return nil, fmt.Errorf("while building SIF from layers: %v", err)
+		}
+	}
+
+	diffs, err := client.CompareSIFs(paths[0], paths[1])
+	if err != nil {
+		return nil, fmt.Errorf("while comparing images: %v", err)
+	}
+	if len(diffs) > 0 {
+		return diffs, nil
+	}
+
+	// mode is before umask if pullTo doesn't exist
+	if err := fs.CopyFileAtomic(paths[0], pullTo, 0o777); err != nil {
+		return nil, fmt.Errorf("error copying image: %v", err)
+	}
+	return nil, nil
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// PullStreamToFile will build a SIF image from an OCI or docker save archive,
+// optionally gzip compressed, read from r and place it at pullTo.
+//
+// An OCI archive is unpacked as it is read, into a layout under
+// opts.TmpDir, so no copy of the archive itself is made. A docker save
+// archive must be seekable, so it is written back to a tar file under
+// opts.TmpDir once unpacked.
+func PullStreamToFile(ctx context.Context, imgCache *cache.Handle, pullTo string, r io.Reader, opts PullOptions) (imagePath string, err error) {
+	dir, err := os.MkdirTemp(opts.TmpDir, "stdin-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sylog.Infof("Reading image archive from standard input")
+	pullFrom, err := unpackStream(r, dir)
+	if err != nil {
+		return "", fmt.Errorf("while reading image archive: %v", err)
+	}
+	sylog.Debugf("Image archive from standard input available as %s", pullFrom)
+
+	return PullToFile(ctx, imgCache, pullTo, pullFrom, opts)
+}
+
+// unpackStream unpacks the tar stream r under dir and returns an image
+// reference, usable with PullToFile, to the image it holds.
+func unpackStream(r io.Reader, dir string) (string, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return "", err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	layoutDir := filepath.Join(dir, "image")
+	if err := untar(r, layoutDir); err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(filepath.Join(layoutDir, "oci-layout")); err == nil {
+		return "oci:" + layoutDir, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(layoutDir, "manifest.json")); err == nil {
+		archive := filepath.Join(dir, "image.tar")
+		if err := retar(layoutDir, archive); err != nil {
+			return "", err
+		}
+		return "docker-archive:" + archive, nil
+	}
+
+	return "", fmt.Errorf("not an OCI or docker save archive")
+}
+
+// untar extracts the tar stream r into dst, refusing entries that would be
+// created, or symlinks that would point, outside of dst.
+func untar(r io.Reader, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+
+	var links []string
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(root, header.Name)
+		if target == root {
+			continue
+		}
+		if !inside(root, target) {
+			return fmt.Errorf("%s: illegal extraction path", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeReg, tar.TypeSymlink:
+		default:
+			sylog.Debugf("Ignoring %s in image archive: unsupported type %c", header.Name, header.Typeflag)
+			continue
+		}
+
+		// Entries must not be created through a previously extracted
+		// symlink pointing outside of root.
+		parent := filepath.Dir(target)
+		if err := os.MkdirAll(parent, 0o755); err != nil {
+			return err
+		}
+		if parent, err = filepath.EvalSymlinks(parent); err != nil {
+			return err
+		}
+		if parent != root && !inside(root, parent) {
+			return fmt.Errorf("%s: illegal extraction path", header.Name)
+		}
+		target = filepath.Join(parent, filepath.Base(target))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// docker save links layers shared between images.
+			if filepath.IsAbs(header.Linkname) || !inside(root, filepath.Join(parent, header.Linkname)) {
+				return fmt.Errorf("%s: illegal link target %s", header.Name, header.Linkname)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+			links = append(links, target)
+		}
+	}
+
+	// Links may be chained through other links, so they are only checked
+	// for good once all of them exist.
+	for _, l := range links {
+		resolved, err := filepath.EvalSymlinks(l)
+		if err != nil {
+			return fmt.Errorf("%s: %v", l, err)
+		}
+		if !inside(root, resolved) {
+			return fmt.Errorf("%s: illegal link target", l)
+		}
+	}
+	return nil
+}
+
+// inside returns true if path is located under root.
+func inside(root, path string) bool {
+	return strings.HasPrefix(filepath.Clean(path), root+string(os.PathSeparator))
+}
+
+// retar writes the content of src to a tar file at dst.
+func retar(src, dst string) (err error) {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	tw := tar.NewWriter(f)
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == src {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(fi, link)
+		if err != nil {
+			return err
+		}
+		if header.Name, err = filepath.Rel(src, path); err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(tw, in)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
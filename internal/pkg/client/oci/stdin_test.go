@@ -0,0 +1,126 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name string
+	link string
+	data string
+}
+
+func makeTar(t *testing.T, entries []tarEntry, compress bool) *bytes.Buffer {
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Mode: 0o644}
+		switch {
+		case strings.HasSuffix(e.name, "/"):
+			h.Typeflag = tar.TypeDir
+			h.Mode = 0o755
+		case e.link != "":
+			h.Typeflag = tar.TypeSymlink
+			h.Linkname = e.link
+		default:
+			h.Typeflag = tar.TypeReg
+			h.Size = int64(len(e.data))
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &buf
+}
+
+func TestUnpackStream(t *testing.T) {
+	ociEntries := []tarEntry{
+		{name: "./"},
+		{name: "./oci-layout", data: `{"imageLayoutVersion": "1.0.0"}`},
+		{name: "./index.json", data: `{}`},
+		{name: "./blobs/sha256/abc", data: "blob"},
+	}
+	dockerEntries := []tarEntry{
+		{name: "manifest.json", data: `[]`},
+		{name: "abc/layer.tar", data: "layer"},
+		{name: "def/layer.tar", link: "../abc/layer.tar"},
+	}
+
+	tests := []struct {
+		name       string
+		entries    []tarEntry
+		compress   bool
+		wantPrefix string
+		wantErr    bool
+	}{
+		{name: "OCI", entries: ociEntries, wantPrefix: "oci:"},
+		{name: "OCIGzip", entries: ociEntries, compress: true, wantPrefix: "oci:"},
+		{name: "Docker", entries: dockerEntries, wantPrefix: "docker-archive:"},
+		{name: "Unknown", entries: []tarEntry{{name: "foo", data: "bar"}}, wantErr: true},
+		{name: "PathEscape", entries: []tarEntry{{name: "../oci-layout", data: "{}"}}, wantErr: true},
+		{name: "LinkEscape", entries: []tarEntry{{name: "manifest.json", link: "/etc/passwd"}}, wantErr: true},
+		{name: "LinkChainEscape", entries: []tarEntry{
+			{name: "a", link: "."},
+			{name: "a/b", link: "../x"},
+			{name: "manifest.json", data: "[]"},
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ref, err := unpackStream(makeTar(t, tt.entries, tt.compress), dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.HasPrefix(ref, tt.wantPrefix) {
+				t.Fatalf("got reference %q, want prefix %q", ref, tt.wantPrefix)
+			}
+
+			path := strings.TrimPrefix(ref, tt.wantPrefix)
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("referenced image not found: %v", err)
+			}
+			if tt.wantPrefix == "oci:" {
+				b, err := os.ReadFile(filepath.Join(path, "blobs", "sha256", "abc"))
+				if err != nil || string(b) != "blob" {
+					t.Errorf("unexpected blob content %q: %v", b, err)
+				}
+			}
+		})
+	}
+}
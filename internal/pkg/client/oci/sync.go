@@ -0,0 +1,158 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sylabs/singularity/internal/pkg/build/oci"
+	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// SyncResult lists the tags of a repository by the outcome of a sync.
+type SyncResult struct {
+	Added     []string
+	Updated   []string
+	Unchanged []string
+	Removed   []string
+	// Failed lists the tags which could not be checked or pulled.
+	Failed []string
+}
+
+// Sync pulls the tags of the docker repository repo, e.g. docker://alpine,
+// which are new or changed since the last sync to dir, to SIFs in dir named
+// <repository>_<tag>.sif. The digests of the tags pulled are recorded in a
+// state file in dir. Tags removed from the repository are reported, and
+// their SIF deleted if prune is set. A failure to pull a tag doesn't stop the
+// sync, and is reported in the result.
+func Sync(ctx context.Context, imgCache *cache.Handle, repo, dir string, opts PullOptions, prune bool) (SyncResult, error) {
+	var res SyncResult
+
+	sysCtx := opts.systemContext()
+	tags, err := ListTags(ctx, repo, opts)
+	if err != nil {
+		return res, fmt.Errorf("while listing tags of %s: %v", repo, err)
+	}
+	sylog.Debugf("Found %d tags in %s", len(tags), repo)
+
+	remote := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		hash, err := oci.ImageDigest(ctx, repo+":"+tag, sysCtx)
+		if err != nil {
+			sylog.Warningf("Failed to get digest of %s:%s: %v", repo, tag, err)
+			res.Failed = append(res.Failed, tag)
+			continue
+		}
+		remote[tag] = strings.Replace(hash, ".", ":", 1)
+	}
+
+	state, err := loadSyncState(dir)
+	if err != nil {
+		return res, err
+	}
+	rs := state[repo]
+	if rs == nil {
+		rs = &repoState{}
+	}
+	if rs.Tags == nil {
+		rs.Tags = make(map[string]syncedTag)
+	}
+	state[repo] = rs
+
+	variant := opts.cacheVariant()
+	plan := planSync(rs, remote, variant, func(file string) bool {
+		_, err := os.Stat(filepath.Join(dir, file))
+		return err == nil
+	})
+	// Tags whose digest couldn't be obtained are still in the repository.
+	for _, tag := range res.Failed {
+		plan.Remove = removeString(plan.Remove, tag)
+	}
+	// Previously pulled tags are all updated if the variant changed, so it
+	// holds for them from now on.
+	rs.Variant = variant
+
+	pullTag := func(tag string) bool {
+		file := syncFileName(repo, tag)
+		sylog.Infof("Pulling %s:%s to %s", repo, tag, file)
+		if _, err := PullToFile(ctx, imgCache, filepath.Join(dir, file), repo+":"+tag, opts); err != nil {
+			sylog.Errorf("Failed to pull %s:%s: %v", repo, tag, err)
+			res.Failed = append(res.Failed, tag)
+			delete(rs.Tags, tag)
+			return false
+		}
+		rs.Tags[tag] = syncedTag{Digest: remote[tag], File: file}
+		// Save after each pull, so an interrupted sync resumes where it
+		// stopped.
+		if err := state.save(dir); err != nil {
+			sylog.Warningf("Failed to save sync state: %v", err)
+		}
+		return true
+	}
+
+	for _, tag := range plan.Add {
+		if pullTag(tag) {
+			res.Added = append(res.Added, tag)
+		}
+	}
+	for _, tag := range plan.Update {
+		if pullTag(tag) {
+			res.Updated = append(res.Updated, tag)
+		}
+	}
+	res.Unchanged = plan.Unchanged
+
+	for _, tag := range plan.Remove {
+		res.Removed = append(res.Removed, tag)
+		if !prune {
+			continue
+		}
+		file := rs.Tags[tag].File
+		sylog.Infof("Removing %s for tag %s, removed from %s", file, tag, repo)
+		if err := os.Remove(filepath.Join(dir, file)); err != nil && !os.IsNotExist(err) {
+			sylog.Warningf("Failed to remove %s: %v", file, err)
+			continue
+		}
+		delete(rs.Tags, tag)
+	}
+
+	if err := state.save(dir); err != nil {
+		return res, fmt.Errorf("while saving sync state: %v", err)
+	}
+	return res, nil
+}
+
+// ListTags lists the tags of the docker repository repo, e.g. docker://alpine.
+func ListTags(ctx context.Context, repo string, opts PullOptions) ([]string, error) {
+	if err := checkRegistry(repo, opts); err != nil {
+		return nil, err
+	}
+	return oci.ListTags(ctx, repo, opts.systemContext())
+}
+
+// syncFileName returns the name of the SIF a tag of repo is pulled to by a
+// sync.
+func syncFileName(repo, tag string) string {
+	name := strings.TrimPrefix(repo, "docker://")
+	return path.Base(name) + "_" + tag + ".sif"
+}
+
+// removeString returns s without the element v.
+func removeString(s []string, v string) []string {
+	out := s[:0]
+	for _, e := range s {
+		if e != v {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// syncStateFile is the name of the file, in the directory of a sync,
+// recording the digests of the tags previously pulled.
+const syncStateFile = ".singularity-sync.json"
+
+// syncedTag records a tag pulled by a sync.
+type syncedTag struct {
+	// Digest is the digest of the image the tag referred to.
+	Digest string `json:"digest"`
+	// File is the name of the SIF the tag was pulled to.
+	File string `json:"file"`
+}
+
+// repoState records the tags of a repository pulled by a sync.
+type repoState struct {
+	// Variant identifies the options the SIFs were built with.
+	Variant string               `json:"variant"`
+	Tags    map[string]syncedTag `json:"tags"`
+}
+
+// syncState records the tags pulled by syncs to a directory, by repository.
+type syncState map[string]*repoState
+
+// loadSyncState reads the sync state of dir, which is empty if dir has not
+// been synced to yet.
+func loadSyncState(dir string) (syncState, error) {
+	b, err := os.ReadFile(filepath.Join(dir, syncStateFile))
+	if os.IsNotExist(err) {
+		return syncState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := syncState{}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %v", syncStateFile, err)
+	}
+	return state, nil
+}
+
+// save writes the sync state of dir atomically.
+func (s syncState) save(dir string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, syncStateFile+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, syncStateFile))
+}
+
+// syncPlan lists the tags of a repository by the action a sync takes.
+type syncPlan struct {
+	// Add lists the tags not pulled before.
+	Add []string
+	// Update lists the tags pulled before, whose digest or build options
+	// changed, or whose SIF is missing.
+	Update []string
+	// Unchanged lists the tags whose SIF is current.
+	Unchanged []string
+	// Remove lists the tags pulled before, no longer in the repository.
+	Remove []string
+}
+
+// planSync compares the digests of the tags of a repository, in remote, with
+// those recorded in rs for SIFs built with the options identified by
+// variant. exists reports whether a SIF previously pulled is still present.
+func planSync(rs *repoState, remote map[string]string, variant string, exists func(file string) bool) syncPlan {
+	var p syncPlan
+
+	for tag, digest := range remote {
+		prev, ok := rs.Tags[tag]
+		switch {
+		case !ok:
+			p.Add = append(p.Add, tag)
+		case prev.Digest != digest || rs.Variant != variant || !exists(prev.File):
+			p.Update = append(p.Update, tag)
+		default:
+			p.Unchanged = append(p.Unchanged, tag)
+		}
+	}
+	for tag := range rs.Tags {
+		if _, ok := remote[tag]; !ok {
+			p.Remove = append(p.Remove, tag)
+		}
+	}
+
+	sort.Strings(p.Add)
+	sort.Strings(p.Update)
+	sort.Strings(p.Unchanged)
+	sort.Strings(p.Remove)
+	return p
+}
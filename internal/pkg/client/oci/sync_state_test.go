@@ -0,0 +1,100 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package oci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanSync(t *testing.T) {
+	rs := &repoState{
+		Variant: "",
+		Tags: map[string]syncedTag{
+			"1.0":    {Digest: "sha256:10", File: "repo_1.0.sif"},
+			"1.1":    {Digest: "sha256:11", File: "repo_1.1.sif"},
+			"latest": {Digest: "sha256:11", File: "repo_latest.sif"},
+			"old":    {Digest: "sha256:01", File: "repo_old.sif"},
+		},
+	}
+	remote := map[string]string{
+		"1.0":    "sha256:10",
+		"1.1":    "sha256:11",
+		"1.2":    "sha256:12",
+		"latest": "sha256:12",
+	}
+	exists := func(file string) bool { return file != "repo_1.1.sif" }
+
+	tests := []struct {
+		name    string
+		variant string
+		want    syncPlan
+	}{
+		{
+			name: "SameVariant",
+			want: syncPlan{
+				Add:       []string{"1.2"},
+				Update:    []string{"1.1", "latest"},
+				Unchanged: []string{"1.0"},
+				Remove:    []string{"old"},
+			},
+		},
+		{
+			name:    "OtherVariant",
+			variant: "-0123456789ab",
+			want: syncPlan{
+				Add:    []string{"1.2"},
+				Update: []string{"1.0", "1.1", "latest"},
+				Remove: []string{"old"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planSync(rs, remote, tt.variant, exists)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("FirstSync", func(t *testing.T) {
+		got := planSync(&repoState{}, remote, "", exists)
+		want := syncPlan{Add: []string{"1.0", "1.1", "1.2", "latest"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestSyncState(t *testing.T) {
+	dir := t.TempDir()
+
+	state, err := loadSyncState(dir)
+	if err != nil {
+		t.Fatalf("unexpected error loading missing state: %v", err)
+	}
+	if len(state) != 0 {
+		t.Fatalf("unexpected state %v in empty directory", state)
+	}
+
+	state["docker://alpine"] = &repoState{
+		Variant: "-0123456789ab",
+		Tags:    map[string]syncedTag{"latest": {Digest: "sha256:12", File: "alpine_latest.sif"}},
+	}
+	if err := state.save(dir); err != nil {
+		t.Fatalf("unexpected error saving state: %v", err)
+	}
+
+	got, err := loadSyncState(dir)
+	if err != nil {
+		t.Fatalf("unexpected error loading state: %v", err)
+	}
+	if !reflect.DeepEqual(got, state) {
+		t.Errorf("got state %+v, want %+v", got, state)
+	}
+}
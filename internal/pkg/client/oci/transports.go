@@ -1,4 +1,4 @@
-// Copyright (c) 2018, Sylabs Inc. All rights reserved.
+// Copyright (c) 2018-2023, Sylabs Inc. All rights reserved.
 // This software is licensed under a 3-clause BSD license. Please consult the
 // LICENSE.md file distributed with the sources of this project regarding your
 // rights to use or distribute this software.
@@ -6,7 +6,12 @@
 package oci
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/containers/image/v5/docker/reference"
 	"github.com/containers/image/v5/transports"
+	"github.com/sylabs/singularity/internal/pkg/client"
 )
 
 // IsSupported returns whether or not the transport given is supported. To fit within a switch/case
@@ -20,3 +25,28 @@ func IsSupported(transport string) string {
 
 	return ""
 }
+
+// RegistryHost returns the host of the registry the image pullFrom is pulled
+// from, docker.io for Docker Hub, or "" if it is not pulled from a registry.
+func RegistryHost(pullFrom string) (string, error) {
+	transport, ref, ok := strings.Cut(pullFrom, ":")
+	if !ok || transport != "docker" {
+		return "", nil
+	}
+	named, err := reference.ParseNormalizedNamed(strings.TrimPrefix(ref, "//"))
+	if err != nil {
+		return "", fmt.Errorf("invalid image reference %s: %v", pullFrom, err)
+	}
+	return reference.Domain(named), nil
+}
+
+// checkRegistry returns a *client.NotAllowedError if the image pullFrom is
+// in a registry not allowed by singularity.conf or opts.AllowedRegistries, to
+// be checked before any request is made to the registry.
+func checkRegistry(pullFrom string, opts PullOptions) error {
+	host, err := RegistryHost(pullFrom)
+	if err != nil || host == "" {
+		return err
+	}
+	return client.CheckRegistry(host, opts.AllowedRegistries)
+}
@@ -6,9 +6,16 @@
 package oci
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/containers/image/v5/transports"
+	"github.com/sylabs/singularity/internal/pkg/client"
 )
 
 func TestIsSupported(t *testing.T) {
@@ -44,3 +51,99 @@ func TestIsSupported(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryHost(t *testing.T) {
+	tests := []struct {
+		pullFrom string
+		want     string
+		wantErr  bool
+	}{
+		{pullFrom: "docker://alpine", want: "docker.io"},
+		{pullFrom: "docker://library/alpine:3.17", want: "docker.io"},
+		{pullFrom: "docker://index.docker.io/library/alpine", want: "docker.io"},
+		{pullFrom: "docker://quay.io/centos/centos:stream9", want: "quay.io"},
+		{pullFrom: "docker://registry.local:5000/app@sha256:" + strings.Repeat("a", 64), want: "registry.local:5000"},
+		{pullFrom: "docker-archive:/tmp/alpine.tar", want: ""},
+		{pullFrom: "oci:/tmp/layout:latest", want: ""},
+		{pullFrom: "docker://Invalid/Name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pullFrom, func(t *testing.T) {
+			got, err := RegistryHost(tt.pullFrom)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryFetchesNotAllowed(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	image := "docker://" + strings.TrimPrefix(srv.URL, "http://") + "/test:latest"
+	repo := strings.TrimSuffix(image, ":latest")
+	opts := PullOptions{NoHTTPS: true, AllowedRegistries: []string{"registry.example.com"}}
+
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		fetch func() error
+	}{
+		{
+			name: "PullMetadata",
+			fetch: func() error {
+				_, err := PullMetadata(ctx, image, opts)
+				return err
+			},
+		},
+		{
+			name: "PullLayers",
+			fetch: func() error {
+				_, err := PullLayers(ctx, image, opts)
+				return err
+			},
+		},
+		{
+			name: "PullAttestations",
+			fetch: func() error {
+				_, _, err := PullAttestations(ctx, image, opts)
+				return err
+			},
+		},
+		{
+			name: "IsCurrent",
+			fetch: func() error {
+				_, _, err := IsCurrent(ctx, "/nonexistent.sif", image, opts)
+				return err
+			},
+		},
+		{
+			name: "ListTags",
+			fetch: func() error {
+				_, err := ListTags(ctx, repo, opts)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&requests, 0)
+			var nae *client.NotAllowedError
+			if err := tt.fetch(); !errors.As(err, &nae) {
+				t.Fatalf("got error %v, want a NotAllowedError", err)
+			}
+			if n := atomic.LoadInt32(&requests); n != 0 {
+				t.Errorf("got %d requests to a registry not allowed", n)
+			}
+		})
+	}
+}
@@ -13,7 +13,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,6 +24,7 @@ import (
 	ocitypes "github.com/containers/image/v5/types"
 	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/pkg/image"
 	"github.com/sylabs/singularity/pkg/syfs"
 	"github.com/sylabs/singularity/pkg/sylog"
@@ -56,8 +56,14 @@ const (
 
 var sifLayerMediaTypes = []string{SifLayerMediaTypeV1, SifLayerMediaTypeProto}
 
+// getResolver returns a resolver authenticating with ociAuth, or the docker
+// credential file, applying the registry timeouts and maximum number of
+// redirects carried by ctx.
 func getResolver(ctx context.Context, ociAuth *ocitypes.DockerAuthConfig) (remotes.Resolver, error) {
-	opts := docker.ResolverOptions{Credentials: genCredfn(ociAuth)}
+	httpClient := client.RegistryTimeoutsFromContext(ctx).HTTPClient()
+	httpClient.CheckRedirect = client.CheckRedirect(client.MaxRedirectsFromContext(ctx))
+
+	opts := docker.ResolverOptions{Credentials: genCredfn(ociAuth), Client: httpClient}
 	if ociAuth != nil && (ociAuth.Username != "" || ociAuth.Password != "") {
 		return docker.NewResolver(opts), nil
 	}
@@ -68,7 +74,7 @@ func getResolver(ctx context.Context, ociAuth *ocitypes.DockerAuthConfig) (remot
 		return docker.NewResolver(opts), nil
 	}
 
-	return cli.Resolver(ctx, &http.Client{}, false)
+	return cli.Resolver(ctx, httpClient, false)
 }
 
 // DownloadImage downloads a SIF image specified by an oci reference to a file using the included credentials
@@ -223,6 +229,22 @@ func ensureSIF(filepath string) error {
 	return nil
 }
 
+// RegistryHost returns the host of the registry of the oras image ref.
+func RegistryHost(ref string) (string, error) {
+	ref = strings.TrimPrefix(ref, "oras://")
+	ref = strings.TrimPrefix(ref, "//")
+
+	spec, err := reference.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse oci reference: %w", err)
+	}
+	// Hostname() will panic if there is no '/' in the locator
+	if !strings.Contains(spec.Locator, "/") {
+		return "", fmt.Errorf("not a valid oci object uri: %s", ref)
+	}
+	return spec.Hostname(), nil
+}
+
 // ImageSHA returns the sha256 digest of the SIF layer of the OCI manifest
 // oci spec dictates only sha256 and sha512 are supported at time creation for this function
 // sha512 is currently optional for implementations, this function will return an error when
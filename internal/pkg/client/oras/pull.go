@@ -12,12 +12,46 @@ import (
 
 	ocitypes "github.com/containers/image/v5/types"
 	"github.com/sylabs/singularity/internal/pkg/cache"
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/internal/pkg/util/fs"
 	"github.com/sylabs/singularity/pkg/sylog"
 )
 
+// PullOptions holds options for pulling an oras image to a file.
+type PullOptions struct {
+	// TmpDir is the location for temporary files.
+	TmpDir string
+	// OciAuth holds credentials for the registry.
+	OciAuth *ocitypes.DockerAuthConfig
+	// PreferCached uses an image previously pulled for the same reference
+	// from the cache, if present, without checking the remote digest.
+	PreferCached bool
+	// AllowedRegistries, if set, lists the hosts of the only registries
+	// images can be pulled from, among those allowed by the site.
+	AllowedRegistries []string
+}
+
 // pull will pull an oras image into the cache if directTo="", or a specific file if directTo is set.
-func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, ociAuth *ocitypes.DockerAuthConfig) (imagePath string, err error) {
+func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string, ociAuth *ocitypes.DockerAuthConfig, preferCached bool, allowed []string) (imagePath string, err error) {
+	host, err := RegistryHost(pullFrom)
+	if err != nil {
+		return "", err
+	}
+	if err := client.CheckRegistry(host, allowed); err != nil {
+		return "", err
+	}
+
+	if directTo == "" && preferCached {
+		cacheEntry, err := imgCache.GetReferenceEntry(cache.OrasCacheType, pullFrom)
+		if err != nil {
+			sylog.Debugf("Could not look up %s in cache: %v", pullFrom, err)
+		} else if cacheEntry != nil {
+			sylog.Warningf("Using cached image for %s without checking for a newer version, it may be stale", pullFrom)
+			return cacheEntry.Path, nil
+		}
+		sylog.Debugf("No cached image for %s, pulling", pullFrom)
+	}
+
 	hash, err := ImageSHA(ctx, pullFrom, ociAuth)
 	if err != nil {
 		return "", fmt.Errorf("failed to get checksum for %s: %s", pullFrom, err)
@@ -57,6 +91,10 @@ func pull(ctx context.Context, imgCache *cache.Handle, directTo, pullFrom string
 			sylog.Infof("Using cached SIF image")
 		}
 		imagePath = cacheEntry.Path
+
+		if err := imgCache.PutReference(cache.OrasCacheType, pullFrom, hash); err != nil {
+			sylog.Debugf("Could not record %s in cache: %v", pullFrom, err)
+		}
 	}
 
 	return imagePath, nil
@@ -75,18 +113,18 @@ func Pull(ctx context.Context, imgCache *cache.Handle, pullFrom, tmpDir string,
 		sylog.Infof("Downloading oras image to tmp cache: %s", directTo)
 	}
 
-	return pull(ctx, imgCache, directTo, pullFrom, ociAuth)
+	return pull(ctx, imgCache, directTo, pullFrom, ociAuth, false, nil)
 }
 
 // PullToFile will pull an oras image to the specified location, through the cache, or directly if cache is disabled
-func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom, tmpDir string, ociAuth *ocitypes.DockerAuthConfig) (imagePath string, err error) {
+func PullToFile(ctx context.Context, imgCache *cache.Handle, pullTo, pullFrom string, opts PullOptions) (imagePath string, err error) {
 	directTo := ""
 	if imgCache.IsDisabled() {
 		directTo = pullTo
 		sylog.Debugf("Cache disabled, pulling directly to: %s", directTo)
 	}
 
-	src, err := pull(ctx, imgCache, directTo, pullFrom, ociAuth)
+	src, err := pull(ctx, imgCache, directTo, pullFrom, opts.OciAuth, opts.PreferCached, opts.AllowedRegistries)
 	if err != nil {
 		return "", fmt.Errorf("error fetching image to cache: %v", err)
 	}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// UnknownOS is the ID of the OS of an image without an os-release file.
+const UnknownOS = "unknown"
+
+// OSReleasePaths lists the paths of the os-release file, relative to the root
+// filesystem of an image, in order of precedence.
+var OSReleasePaths = []string{"etc/os-release", "usr/lib/os-release"}
+
+// OSRelease identifies the OS distribution of an image, from the fields of
+// its os-release file.
+type OSRelease struct {
+	// ID is the lower case identifier of the distribution, e.g. alpine.
+	ID string `json:"id"`
+	// VersionID is the version of the distribution, e.g. 3.18.4.
+	VersionID string `json:"versionID,omitempty"`
+	// PrettyName is the full name of the distribution, e.g. Alpine Linux
+	// v3.18.
+	PrettyName string `json:"prettyName,omitempty"`
+}
+
+// String returns a description of o, for display.
+func (o OSRelease) String() string {
+	if o.PrettyName != "" {
+		return o.PrettyName
+	}
+	if o.VersionID != "" {
+		return o.ID + " " + o.VersionID
+	}
+	return o.ID
+}
+
+// Labels returns the SIF labels recording o.
+func (o OSRelease) Labels() map[string]string {
+	labels := map[string]string{"org.sylabs.os.id": o.ID}
+	if o.VersionID != "" {
+		labels["org.sylabs.os.version-id"] = o.VersionID
+	}
+	if o.PrettyName != "" {
+		labels["org.sylabs.os.pretty-name"] = o.PrettyName
+	}
+	return labels
+}
+
+// ParseOSRelease parses the ID, VERSION_ID and PRETTY_NAME fields of an
+// os-release file, as described by os-release(5). A file without ID
+// identifies the OS as linux.
+func ParseOSRelease(r io.Reader) (OSRelease, error) {
+	o := OSRelease{ID: "linux"}
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if uq, err := strconv.Unquote(value); err == nil {
+			value = uq
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+
+		switch key {
+		case "ID":
+			if value != "" {
+				o.ID = value
+			}
+		case "VERSION_ID":
+			o.VersionID = value
+		case "PRETTY_NAME":
+			o.PrettyName = value
+		}
+	}
+	return o, s.Err()
+}
+
+// ReadOSRelease returns the OS of the root filesystem at rootfs, from the first
+// of OSReleasePaths that is a regular file. Symbolic links aren't followed, as
+// they would be resolved against the host. The ID of the OS is UnknownOS if
+// there is no such file.
+func ReadOSRelease(rootfs string) (OSRelease, error) {
+	for _, p := range OSReleasePaths {
+		path := filepath.Join(rootfs, p)
+		fi, err := os.Lstat(path)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return OSRelease{}, err
+		}
+		defer f.Close()
+		return ParseOSRelease(f)
+	}
+	return OSRelease{ID: UnknownOS}, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sylabs/singularity/internal/pkg/image/unpacker"
+	"github.com/sylabs/singularity/pkg/image"
+)
+
+// DetectOS returns the OS of the SIF image at path, extracting only its
+// os-release file from its squashfs root filesystem, to a temporary directory
+// created in tmpDir.
+func DetectOS(path, tmpDir string) (OSRelease, error) {
+	img, err := image.Init(path, false)
+	if err != nil {
+		return OSRelease{}, fmt.Errorf("could not open image %s: %v", path, err)
+	}
+	defer img.File.Close()
+
+	part, err := img.GetRootFsPartition()
+	if err != nil {
+		return OSRelease{}, fmt.Errorf("while getting root filesystem in %s: %v", path, err)
+	}
+	if part.Type != image.SQUASHFS {
+		return OSRelease{}, fmt.Errorf("root filesystem of %s is not squashfs", path)
+	}
+	reader, err := image.NewPartitionReader(img, "", 0)
+	if err != nil {
+		return OSRelease{}, fmt.Errorf("could not read root filesystem: %v", err)
+	}
+
+	dir, err := os.MkdirTemp(tmpDir, "detect-os-")
+	if err != nil {
+		return OSRelease{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	files := make([]string, len(OSReleasePaths))
+	for i, p := range OSReleasePaths {
+		files[i] = "/" + p
+	}
+	rootfs := filepath.Join(dir, "rootfs")
+	if err := unpacker.NewSquashfs().ExtractFiles(files, reader, rootfs); err != nil {
+		return OSRelease{}, fmt.Errorf("while extracting os-release: %v", err)
+	}
+
+	return ReadOSRelease(rootfs)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOSRelease(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    OSRelease
+	}{
+		{
+			name: "Alpine",
+			content: `NAME="Alpine Linux"
+ID=alpine
+VERSION_ID=3.18.4
+PRETTY_NAME="Alpine Linux v3.18"
+`,
+			want: OSRelease{ID: "alpine", VersionID: "3.18.4", PrettyName: "Alpine Linux v3.18"},
+		},
+		{
+			name: "QuotedAndComments",
+			content: `# comment
+ID='ubuntu'
+VERSION_ID="22.04"
+PRETTY_NAME="Ubuntu 22.04.3 LTS"
+`,
+			want: OSRelease{ID: "ubuntu", VersionID: "22.04", PrettyName: "Ubuntu 22.04.3 LTS"},
+		},
+		{
+			name:    "NoID",
+			content: "NAME=Custom\n",
+			want:    OSRelease{ID: "linux"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOSRelease(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOSRelease(t *testing.T) {
+	rootfs := t.TempDir()
+
+	got, err := ReadOSRelease(rootfs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != UnknownOS {
+		t.Errorf("got ID %q for rootfs without os-release, want %q", got.ID, UnknownOS)
+	}
+
+	// A link to the host os-release must not be followed.
+	if err := os.MkdirAll(filepath.Join(rootfs, "etc"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/os-release", filepath.Join(rootfs, "etc/os-release")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(rootfs, "usr/lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rootfs, "usr/lib/os-release"), []byte("ID=debian\nVERSION_ID=\"12\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = ReadOSRelease(rootfs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (OSRelease{ID: "debian", VersionID: "12"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
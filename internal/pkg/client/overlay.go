@@ -0,0 +1,156 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// Overlay is an image embedded into another as an overlay, for pull
+// --with-overlay.
+type Overlay struct {
+	// Name identifies the overlay, e.g. the URI it was pulled from.
+	Name string
+	// Path is the path of the SIF image holding the overlay.
+	Path string
+}
+
+// OverlayLayer is a layer of the root filesystem of a SIF image.
+type OverlayLayer struct {
+	// Name is the name of the overlay the layer was embedded from, or of its
+	// SIF descriptor.
+	Name string
+	// FS is the filesystem of the layer, e.g. Squashfs.
+	FS string
+	// Size is the size of the layer, in bytes.
+	Size int64
+}
+
+// EmbedOverlays embeds the root filesystems of the SIF images of overlays, in
+// order, into the SIF image at path, as overlay partitions of its root
+// filesystem. The runtime stacks overlay partitions in the order of the SIF,
+// so each overlay sits above the ones before it. The overlays must hold a
+// squashfs root filesystem of the architecture of the image, which must not
+// be signed or encrypted. They are all checked before the image is modified.
+//
+// The layers of the resulting root filesystem are returned from the bottom,
+// the root filesystem of the image, to the top.
+func EmbedOverlays(path string, overlays []Overlay) ([]OverlayLayer, error) {
+	f, err := sif.LoadContainerFromPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not load SIF %s: %v", path, err)
+	}
+	defer f.UnloadContainer()
+
+	sigs, err := f.GetDescriptors(sif.WithDataType(sif.DataSignature))
+	if err != nil {
+		return nil, err
+	}
+	if len(sigs) > 0 {
+		return nil, fmt.Errorf("SIF image %s is signed: could not embed overlays", path)
+	}
+
+	rootfs, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		return nil, fmt.Errorf("SIF image %s has no root filesystem: %v", path, err)
+	}
+	fs, _, arch, err := rootfs.PartitionMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if fs == sif.FsEncryptedSquashfs {
+		return nil, fmt.Errorf("SIF image %s is encrypted: could not embed overlays", path)
+	}
+	partArch := arch
+	if partArch == "unknown" {
+		partArch = runtime.GOARCH
+	}
+	stack := []OverlayLayer{{Name: "rootfs", FS: fs.String(), Size: rootfs.Size()}}
+
+	// Overlays already embedded stay below the new ones.
+	existing, err := f.GetDescriptors(sif.WithDataType(sif.DataPartition))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range existing {
+		if rootfs.GroupID() != 0 && d.GroupID() != rootfs.GroupID() {
+			continue
+		}
+		if fs, pt, _, err := d.PartitionMetadata(); err == nil && pt == sif.PartOverlay {
+			stack = append(stack, OverlayLayer{Name: d.Name(), FS: fs.String(), Size: d.Size()})
+		}
+	}
+
+	inputs := make([]sif.DescriptorInput, 0, len(overlays))
+	for _, o := range overlays {
+		ov, err := sif.LoadContainerFromPath(o.Path, sif.OptLoadWithFlag(os.O_RDONLY))
+		if err != nil {
+			return nil, fmt.Errorf("could not load overlay %s: %v", o.Name, err)
+		}
+		defer ov.UnloadContainer()
+
+		d, err := overlayPartition(ov, arch)
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s is not compatible with %s: %v", o.Name, path, err)
+		}
+
+		opts := []sif.DescriptorInputOpt{
+			sif.OptPartitionMetadata(sif.FsSquash, sif.PartOverlay, partArch),
+			sif.OptObjectName(overlayName(o.Name)),
+		}
+		if rootfs.GroupID() != 0 {
+			opts = append(opts, sif.OptGroupID(rootfs.GroupID()))
+		}
+		di, err := sif.NewDescriptorInput(sif.DataPartition, d.GetReader(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, di)
+		stack = append(stack, OverlayLayer{Name: o.Name, FS: sif.FsSquash.String(), Size: d.Size()})
+	}
+
+	for i, di := range inputs {
+		if err := f.AddObject(di); err != nil {
+			return nil, fmt.Errorf("while embedding overlay %s: %v", overlays[i].Name, err)
+		}
+	}
+	return stack, nil
+}
+
+// maxOverlayName is the maximum length of the name of a SIF descriptor.
+const maxOverlayName = 128
+
+// overlayName returns name, truncated to fit the name of a SIF descriptor.
+func overlayName(name string) string {
+	if len(name) > maxOverlayName {
+		return name[:maxOverlayName]
+	}
+	return name
+}
+
+// overlayPartition returns the root filesystem of the SIF f, checking it can
+// be embedded as an overlay of a root filesystem of architecture arch.
+func overlayPartition(f *sif.FileImage, arch string) (sif.Descriptor, error) {
+	d, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		return sif.Descriptor{}, fmt.Errorf("no root filesystem: %v", err)
+	}
+	fs, _, ovArch, err := d.PartitionMetadata()
+	if err != nil {
+		return sif.Descriptor{}, err
+	}
+	if fs != sif.FsSquash {
+		return sif.Descriptor{}, fmt.Errorf("root filesystem is %s, only squashfs can be embedded", fs)
+	}
+	if arch != "unknown" && ovArch != "unknown" && ovArch != arch {
+		return sif.Descriptor{}, fmt.Errorf("architecture %s does not match %s", ovArch, arch)
+	}
+	return d, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"crypto"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// testSIF creates a SIF at path with a root filesystem of fs and arch holding
+// content, and a signature if signed.
+func testSIF(t *testing.T, path string, fs sif.FSType, arch, content string, signed bool) {
+	t.Helper()
+
+	di, err := sif.NewDescriptorInput(sif.DataPartition, bytes.NewBufferString(content),
+		sif.OptPartitionMetadata(fs, sif.PartPrimSys, arch),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dis := []sif.DescriptorInput{di}
+	if signed {
+		sig, err := sif.NewDescriptorInput(sif.DataSignature, bytes.NewBufferString("signature"),
+			sif.OptSignatureMetadata(crypto.SHA256, make([]byte, 20)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dis = append(dis, sig)
+	}
+
+	f, err := sif.CreateContainerAtPath(path, sif.OptCreateWithDescriptors(dis...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.UnloadContainer(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEmbedOverlays(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.sif")
+	testSIF(t, base, sif.FsSquash, "amd64", "base", false)
+	first := filepath.Join(dir, "first.sif")
+	testSIF(t, first, sif.FsSquash, "amd64", "first", false)
+	second := filepath.Join(dir, "second.sif")
+	testSIF(t, second, sif.FsSquash, "amd64", "second!", false)
+
+	stack, err := EmbedOverlays(base, []Overlay{
+		{Name: "docker://example/first", Path: first},
+		{Name: "docker://example/second", Path: second},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []OverlayLayer{
+		{Name: "rootfs", FS: "Squashfs", Size: 4},
+		{Name: "docker://example/first", FS: "Squashfs", Size: 5},
+		{Name: "docker://example/second", FS: "Squashfs", Size: 7},
+	}
+	if len(stack) != len(want) {
+		t.Fatalf("got stack %+v, want %+v", stack, want)
+	}
+	for i := range want {
+		if stack[i] != want[i] {
+			t.Errorf("got layer %d %+v, want %+v", i, stack[i], want[i])
+		}
+	}
+
+	f, err := sif.LoadContainerFromPath(base, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnloadContainer()
+	rootfs, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlays, err := f.GetDescriptors(sif.WithPartitionType(sif.PartOverlay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlays) != 2 {
+		t.Fatalf("got %d overlay partitions, want 2", len(overlays))
+	}
+	for i, content := range []string{"first", "second!"} {
+		d := overlays[i]
+		if d.GroupID() != rootfs.GroupID() {
+			t.Errorf("got overlay %d in group %d, want %d", i, d.GroupID(), rootfs.GroupID())
+		}
+		b, err := io.ReadAll(d.GetReader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("got overlay %d %q, want %q", i, b, content)
+		}
+	}
+
+	// Embedding more overlays stacks them above those already embedded.
+	third := filepath.Join(dir, "third.sif")
+	testSIF(t, third, sif.FsSquash, "amd64", "third", false)
+	stack, err = EmbedOverlays(base, []Overlay{{Name: "third", Path: third}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stack) != 4 || stack[1].Name != "docker://example/first" || stack[3].Name != "third" {
+		t.Errorf("got stack %+v, want third above the overlays already embedded", stack)
+	}
+}
+
+func TestEmbedOverlaysIncompatible(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseFS      sif.FSType
+		baseSigned  bool
+		overlayFS   sif.FSType
+		overlayArch string
+	}{
+		{name: "Signed", baseFS: sif.FsSquash, baseSigned: true, overlayFS: sif.FsSquash, overlayArch: "amd64"},
+		{name: "Encrypted", baseFS: sif.FsEncryptedSquashfs, overlayFS: sif.FsSquash, overlayArch: "amd64"},
+		{name: "Ext3", baseFS: sif.FsSquash, overlayFS: sif.FsExt3, overlayArch: "amd64"},
+		{name: "Arch", baseFS: sif.FsSquash, overlayFS: sif.FsSquash, overlayArch: "arm64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			base := filepath.Join(dir, "base.sif")
+			testSIF(t, base, tt.baseFS, "amd64", "base", tt.baseSigned)
+			good := filepath.Join(dir, "good.sif")
+			testSIF(t, good, sif.FsSquash, "amd64", "good", false)
+			bad := filepath.Join(dir, "bad.sif")
+			testSIF(t, bad, tt.overlayFS, tt.overlayArch, "bad", false)
+
+			before, err := os.ReadFile(base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := EmbedOverlays(base, []Overlay{{Name: "good", Path: good}, {Name: "bad", Path: bad}}); err == nil {
+				t.Fatal("unexpected success")
+			}
+			after, err := os.ReadFile(base)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(before, after) {
+				t.Error("image was modified by a failed embedding")
+			}
+		})
+	}
+}
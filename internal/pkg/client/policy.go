@@ -0,0 +1,98 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// PolicyInput describes a pulled image to a policy endpoint, to decide
+// whether it is admitted.
+type PolicyInput struct {
+	// Reference is the URI the image was pulled from.
+	Reference string `json:"reference"`
+	// Digest is the digest the reference resolved to at the time of the
+	// pull, for sources that have one.
+	Digest string `json:"digest,omitempty"`
+	// Labels are the labels of the image.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Signers are the fingerprints of the keys that the signatures of the
+	// image claim to be made with. They are not verified.
+	Signers []string `json:"signers,omitempty"`
+}
+
+// PolicyDecision is the decision of a policy endpoint on a pulled image.
+type PolicyDecision struct {
+	Allow   bool     `json:"allow"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// QueryPolicy posts in, as the input of a query of the data API of an Open
+// Policy Agent, to url, e.g. http://opa:8181/v1/data/singularity/pull, and
+// returns its decision. The result of the query must be either a boolean,
+// allowing the image if true, or an object with an allow boolean and an
+// optional list of reasons. An error is returned if the endpoint can't be
+// queried, or the result is undefined or of another form.
+func QueryPolicy(ctx context.Context, url string, in PolicyInput) (PolicyDecision, error) {
+	body, err := json.Marshal(struct {
+		Input PolicyInput `json:"input"`
+	}{in})
+	if err != nil {
+		return PolicyDecision{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return PolicyDecision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := HTTPClient(ctx).Do(req)
+	if err != nil {
+		return PolicyDecision{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return PolicyDecision{}, fmt.Errorf("policy endpoint returned %s: %s", res.Status, bytes.TrimSpace(msg))
+	}
+
+	var out struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return PolicyDecision{}, fmt.Errorf("invalid policy response: %v", err)
+	}
+	return parseDecision(out.Result)
+}
+
+// parseDecision returns the decision held by result, the result of a query
+// of the data API of an Open Policy Agent.
+func parseDecision(result json.RawMessage) (PolicyDecision, error) {
+	if len(result) == 0 || string(result) == "null" {
+		return PolicyDecision{}, fmt.Errorf("policy result is undefined, check the policy path")
+	}
+
+	var allow bool
+	if err := json.Unmarshal(result, &allow); err == nil {
+		return PolicyDecision{Allow: allow}, nil
+	}
+
+	var d struct {
+		Allow   *bool    `json:"allow"`
+		Reasons []string `json:"reasons"`
+	}
+	if err := json.Unmarshal(result, &d); err != nil || d.Allow == nil {
+		return PolicyDecision{}, fmt.Errorf("policy result %s is neither a boolean nor an object with an allow boolean", result)
+	}
+	return PolicyDecision{Allow: *d.Allow, Reasons: d.Reasons}, nil
+}
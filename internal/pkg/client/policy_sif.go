@@ -0,0 +1,71 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+	"github.com/sylabs/singularity/pkg/image"
+	"github.com/sylabs/singularity/pkg/inspect"
+)
+
+// ImageLabels returns the labels of the SIF image at path, from its inspect
+// metadata. A SIF without inspect metadata has no labels.
+func ImageLabels(path string) (map[string]string, error) {
+	img, err := image.Init(path, false)
+	if err != nil {
+		return nil, fmt.Errorf("could not open image %s: %v", path, err)
+	}
+	defer img.File.Close()
+
+	r, err := image.NewSectionReader(img, image.SIFDescInspectMetadataJSON, -1)
+	if err == image.ErrNoSection {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read metadata of %s: %v", path, err)
+	}
+
+	md := inspect.NewMetadata()
+	if err := json.NewDecoder(r).Decode(md); err != nil {
+		return nil, fmt.Errorf("could not decode metadata of %s: %v", path, err)
+	}
+	return md.Attributes.Labels, nil
+}
+
+// ImageSigners returns the fingerprints of the keys that the signatures of
+// the SIF image at path claim to be made with, without verifying them.
+func ImageSigners(path string) ([]string, error) {
+	f, err := sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return nil, fmt.Errorf("could not load SIF %s: %v", path, err)
+	}
+	defer f.UnloadContainer()
+
+	sigs, err := f.GetDescriptors(sif.WithDataType(sif.DataSignature))
+	if err != nil {
+		return nil, err
+	}
+
+	var signers []string
+	seen := make(map[string]bool)
+	for _, d := range sigs {
+		_, fp, err := d.SignatureMetadata()
+		if err != nil {
+			return nil, fmt.Errorf("could not read signature of %s: %v", path, err)
+		}
+		s := strings.ToUpper(fmt.Sprintf("%x", fp))
+		if !seen[s] {
+			seen[s] = true
+			signers = append(signers, s)
+		}
+	}
+	return signers, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestQueryPolicy(t *testing.T) {
+	in := PolicyInput{
+		Reference: "docker://alpine:3.17",
+		Digest:    "sha256:aaa",
+		Labels:    map[string]string{"maintainer": "ops"},
+		Signers:   []string{"0123ABCD"},
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		result  string
+		want    PolicyDecision
+		wantErr bool
+	}{
+		{name: "AllowBool", status: http.StatusOK, result: `{"result": true}`, want: PolicyDecision{Allow: true}},
+		{name: "DenyBool", status: http.StatusOK, result: `{"result": false}`, want: PolicyDecision{Allow: false}},
+		{
+			name:   "DenyObject",
+			status: http.StatusOK,
+			result: `{"result": {"allow": false, "reasons": ["image is unsigned"]}}`,
+			want:   PolicyDecision{Allow: false, Reasons: []string{"image is unsigned"}},
+		},
+		{name: "Undefined", status: http.StatusOK, result: `{}`, wantErr: true},
+		{name: "NoAllow", status: http.StatusOK, result: `{"result": {"reasons": []}}`, wantErr: true},
+		{name: "ServerError", status: http.StatusInternalServerError, result: `{"code": "internal_error"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input PolicyInput `json:"input"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("invalid request: %v", err)
+				}
+				if !reflect.DeepEqual(body.Input, in) {
+					t.Errorf("got input %+v, want %+v", body.Input, in)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.result))
+			}))
+			defer srv.Close()
+
+			got, err := QueryPolicy(context.Background(), srv.URL+"/v1/data/singularity/pull", in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
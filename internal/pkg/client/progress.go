@@ -8,6 +8,7 @@ package client
 import (
 	"context"
 	"io"
+	"sync/atomic"
 
 	"github.com/sylabs/singularity/pkg/sylog"
 	"github.com/vbauerster/mpb/v8"
@@ -47,17 +48,26 @@ func (rf readerFunc) Read(p []byte) (n int, err error) { return rf(p) }
 // ProgressCallback is a function that provides progress information copying from a Reader to a Writer
 type ProgressCallback func(int64, io.Reader, io.Writer) error
 
-// ProgressBarCallback returns a progress bar callback unless e.g. --quiet or lower loglevel is set
+// ProgressBarCallback returns a progress bar callback unless e.g. --quiet or lower loglevel is set.
+// Progress is also sent to the ProgressReporter carried by ctx, if any.
 func ProgressBarCallback(ctx context.Context) ProgressCallback {
+	reporter := ProgressReporterFromContext(ctx)
+
 	if sylog.GetLevel() <= -1 {
 		// If we don't need a bar visible, we just copy data through the callback func
 		return func(totalSize int64, r io.Reader, w io.Writer) error {
+			if reporter != nil {
+				r = reporter.ProxyReader("", totalSize, r)
+			}
 			_, err := CopyWithContext(ctx, w, r)
 			return err
 		}
 	}
 
 	return func(totalSize int64, r io.Reader, w io.Writer) error {
+		if reporter != nil {
+			r = reporter.ProxyReader("", totalSize, r)
+		}
 		p, bar := initProgressBar(totalSize) //nolint:contextcheck
 
 		// create proxy reader
@@ -104,10 +114,19 @@ func CopyWithContext(ctx context.Context, dst io.Writer, src io.Reader) (written
 type DownloadProgressBar struct {
 	bar *mpb.Bar
 	p   *mpb.Progress
+
+	// Hidden disables the bar, e.g. when stderr is not a terminal.
+	Hidden bool
+	// Reporter, if set, also receives the progress of the download.
+	Reporter *ProgressReporter
+
+	total int64
+	done  int64
 }
 
 func (pb *DownloadProgressBar) Init(contentLength int64) {
-	if sylog.GetLevel() <= -1 {
+	pb.total = contentLength
+	if pb.Hidden || sylog.GetLevel() <= -1 {
 		// we don't need a bar visible
 		return
 	}
@@ -115,10 +134,21 @@ func (pb *DownloadProgressBar) Init(contentLength int64) {
 }
 
 func (pb *DownloadProgressBar) ProxyReader(r io.Reader) io.ReadCloser {
+	if pb.Reporter != nil {
+		r = pb.Reporter.ProxyReader("", pb.total, r)
+	}
+	if pb.bar == nil {
+		return io.NopCloser(r)
+	}
 	return pb.bar.ProxyReader(r)
 }
 
 func (pb *DownloadProgressBar) IncrBy(n int) {
+	if pb.Reporter != nil {
+		// Chunks are downloaded concurrently.
+		done := atomic.AddInt64(&pb.done, int64(n))
+		pb.Reporter.Report(ProgressEvent{Phase: PhaseDownload, Bytes: done, Total: pb.total})
+	}
 	if pb.bar == nil {
 		return
 	}
@@ -0,0 +1,204 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// Phases of a pull reported in a ProgressEvent.
+const (
+	// PhaseDownload reports bytes fetched for a blob, or a whole image.
+	PhaseDownload = "download"
+	// PhaseConvert reports the start of the conversion of an image to SIF.
+	PhaseConvert = "convert"
+	// PhaseDone is the final summary of a pull.
+	PhaseDone = "done"
+)
+
+// progressInterval is the minimum interval between two download events for
+// the same blob.
+const progressInterval = 100 * time.Millisecond
+
+// ProgressEvent is a progress report, sent as a line of JSON to a progress
+// socket.
+type ProgressEvent struct {
+	// Phase of the pull, one of the Phase constants.
+	Phase string `json:"phase"`
+	// Blob is the digest of the blob being downloaded, if known.
+	Blob string `json:"blob,omitempty"`
+	// Bytes is the number of bytes downloaded so far, for the blob or, in
+	// the summary, in total.
+	Bytes int64 `json:"bytes"`
+	// Total is the size of the blob, or -1 if unknown.
+	Total int64 `json:"total,omitempty"`
+	// Elapsed is the duration of the pull in seconds, in the summary.
+	Elapsed float64 `json:"elapsed,omitempty"`
+	// Error is the error the pull failed with, in the summary.
+	Error string `json:"error,omitempty"`
+}
+
+// ProgressReporter sends ProgressEvents to a Unix socket. A failure to send
+// an event is logged, and disables further reporting, but never fails a pull.
+type ProgressReporter struct {
+	mu    sync.Mutex
+	conn  net.Conn
+	enc   *json.Encoder
+	start time.Time
+	// bytes holds the last reported byte count of each blob.
+	bytes map[string]int64
+	// observe, if set, is called with each event reported.
+	observe func(ProgressEvent)
+}
+
+// NewProgressReporter returns a ProgressReporter sending events to no
+// socket, only to the function set with Observe.
+func NewProgressReporter() *ProgressReporter {
+	return &ProgressReporter{
+		start: time.Now(),
+		bytes: make(map[string]int64),
+	}
+}
+
+// DialProgressSocket connects to the Unix socket at path, to which progress
+// events will be sent.
+func DialProgressSocket(path string) (*ProgressReporter, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return &ProgressReporter{
+		conn:  conn,
+		enc:   json.NewEncoder(conn),
+		start: time.Now(),
+		bytes: make(map[string]int64),
+	}, nil
+}
+
+// Report sends ev to the socket.
+func (r *ProgressReporter) Report(ev ProgressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ev.Phase == PhaseDownload {
+		r.bytes[ev.Blob] = ev.Bytes
+	}
+	r.send(ev)
+	if r.observe != nil {
+		r.observe(ev)
+	}
+}
+
+// Observe sets f to be called with each event reported, e.g. to time the
+// phases of a pull, replacing any function set before. It is called with
+// the reporter locked, so must not report events itself.
+func (r *ProgressReporter) Observe(f func(ProgressEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.observe = f
+}
+
+func (r *ProgressReporter) send(ev ProgressEvent) {
+	if r.enc == nil {
+		return
+	}
+	if err := r.enc.Encode(ev); err != nil {
+		sylog.Warningf("Could not send progress to socket, disabling progress reporting: %v", err)
+		r.enc = nil
+	}
+}
+
+// Close sends a summary event of a successful pull, with the total number of
+// bytes downloaded, and closes the socket.
+func (r *ProgressReporter) Close() error {
+	return r.CloseWithError(nil)
+}
+
+// CloseWithError sends a summary event, with the total number of bytes
+// downloaded and pullErr, the error the pull failed with if not nil, and
+// closes the socket.
+func (r *ProgressReporter) CloseWithError(pullErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var total int64
+	for _, b := range r.bytes {
+		total += b
+	}
+	ev := ProgressEvent{
+		Phase:   PhaseDone,
+		Bytes:   total,
+		Elapsed: time.Since(r.start).Seconds(),
+	}
+	if pullErr != nil {
+		ev.Error = pullErr.Error()
+	}
+	r.send(ev)
+	r.enc = nil
+	if r.conn == nil {
+		return nil
+	}
+	return r.conn.Close()
+}
+
+// ProxyReader returns a reader reporting the progress of reading total bytes
+// of blob from rd. Events are sent at most every progressInterval, and once
+// rd is exhausted.
+func (r *ProgressReporter) ProxyReader(blob string, total int64, rd io.Reader) io.Reader {
+	return &progressReader{r: r, blob: blob, total: total, rd: rd}
+}
+
+type progressReader struct {
+	r     *ProgressReporter
+	blob  string
+	total int64
+	rd    io.Reader
+	n     int64
+	last  time.Time
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.rd.Read(p)
+	pr.n += int64(n)
+	if err != nil || time.Since(pr.last) >= progressInterval {
+		pr.last = time.Now()
+		pr.r.Report(ProgressEvent{Phase: PhaseDownload, Blob: pr.blob, Bytes: pr.n, Total: pr.total})
+	}
+	return n, err
+}
+
+type progressReporterKey struct{}
+
+// WithProgressReporter returns a copy of ctx carrying r, to which pulls
+// using ctx report their progress.
+func WithProgressReporter(ctx context.Context, r *ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressReporterKey{}, r)
+}
+
+// ProgressReporterFromContext returns the ProgressReporter carried by ctx, or
+// nil if there is none.
+func ProgressReporterFromContext(ctx context.Context) *ProgressReporter {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(progressReporterKey{}).(*ProgressReporter)
+	return r
+}
+
+// ReportPhase sends an event for the start of phase to the ProgressReporter
+// carried by ctx, if any.
+func ReportPhase(ctx context.Context, phase string) {
+	if r := ProgressReporterFromContext(ctx); r != nil {
+		r.Report(ProgressEvent{Phase: phase})
+	}
+}
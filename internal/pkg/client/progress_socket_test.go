@@ -0,0 +1,134 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+// listenProgress listens on a Unix socket, and returns its path and a channel
+// receiving the events read from the first connection, once it is closed.
+func listenProgress(t *testing.T) (string, <-chan []ProgressEvent) {
+	path := filepath.Join(t.TempDir(), "progress.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("while listening: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	ch := make(chan []ProgressEvent, 1)
+	go func() {
+		var events []ProgressEvent
+		defer func() { ch <- events }()
+
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		s := bufio.NewScanner(conn)
+		for s.Scan() {
+			var ev ProgressEvent
+			if err := json.Unmarshal(s.Bytes(), &ev); err != nil {
+				t.Errorf("invalid event %q: %v", s.Text(), err)
+				return
+			}
+			events = append(events, ev)
+		}
+	}()
+	return path, ch
+}
+
+func TestProgressReporter(t *testing.T) {
+	path, ch := listenProgress(t)
+
+	r, err := DialProgressSocket(path)
+	if err != nil {
+		t.Fatalf("while dialing: %v", err)
+	}
+	ctx := WithProgressReporter(context.Background(), r)
+
+	if got := ProgressReporterFromContext(ctx); got != r {
+		t.Fatalf("got reporter %p from context, want %p", got, r)
+	}
+
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:a", Bytes: 5, Total: 10})
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:a", Bytes: 10, Total: 10})
+	ReportPhase(ctx, PhaseConvert)
+
+	// A reader reports once it is exhausted.
+	data := []byte("0123456789abcdef")
+	if _, err := io.Copy(io.Discard, r.ProxyReader("sha256:b", int64(len(data)), bytes.NewReader(data))); err != nil {
+		t.Fatalf("while reading: %v", err)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("while closing: %v", err)
+	}
+	// Reporting after Close is a no-op.
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:c", Bytes: 1})
+
+	events := <-ch
+	if len(events) < 5 {
+		t.Fatalf("got %d events, want at least 5: %+v", len(events), events)
+	}
+
+	last := events[len(events)-2]
+	if last.Blob != "sha256:b" || last.Bytes != int64(len(data)) {
+		t.Errorf("got last download event %+v, want all of sha256:b", last)
+	}
+
+	done := events[len(events)-1]
+	if done.Phase != PhaseDone {
+		t.Errorf("got last event phase %q, want %q", done.Phase, PhaseDone)
+	}
+	if want := int64(10 + len(data)); done.Bytes != want {
+		t.Errorf("got %d bytes in summary, want %d", done.Bytes, want)
+	}
+	if done.Error != "" {
+		t.Errorf("got error %q in summary, want none", done.Error)
+	}
+}
+
+func TestProgressReporterCloseWithError(t *testing.T) {
+	path, ch := listenProgress(t)
+
+	r, err := DialProgressSocket(path)
+	if err != nil {
+		t.Fatalf("while dialing: %v", err)
+	}
+	r.Report(ProgressEvent{Phase: PhaseDownload, Blob: "sha256:a", Bytes: 5, Total: 10})
+
+	if err := r.CloseWithError(errors.New("manifest unknown")); err != nil {
+		t.Fatalf("while closing: %v", err)
+	}
+
+	events := <-ch
+	if len(events) == 0 {
+		t.Fatal("got no events")
+	}
+	done := events[len(events)-1]
+	if done.Phase != PhaseDone || done.Error != "manifest unknown" {
+		t.Errorf("got summary %+v, want phase %q and error %q", done, PhaseDone, "manifest unknown")
+	}
+}
+
+func TestProgressReporterNoContext(t *testing.T) {
+	if r := ProgressReporterFromContext(context.Background()); r != nil {
+		t.Errorf("got reporter %p, want none", r)
+	}
+	// Must not panic.
+	ReportPhase(context.Background(), PhaseConvert)
+}
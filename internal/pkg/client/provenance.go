@@ -0,0 +1,240 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+const (
+	// DSSEMediaType is the media type of the DSSE envelopes attached to
+	// images as attestations.
+	DSSEMediaType = "application/vnd.dsse.envelope.v1+json"
+	// SLSAProvenanceV02 and SLSAProvenanceV1 are the predicate types of SLSA
+	// provenance.
+	SLSAProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	SLSAProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+// ErrNoProvenance is returned when an image has no SLSA provenance
+// attestation.
+var ErrNoProvenance = errors.New("no SLSA provenance attestation attached to the image")
+
+// Provenance is the identity of the build of an image, from its SLSA
+// provenance.
+type Provenance struct {
+	// PredicateType is the SLSA provenance version.
+	PredicateType string
+	// BuilderID identifies the builder that built the image.
+	BuilderID string
+	// SourceRepo is the URI of the source the image was built from, if
+	// recorded.
+	SourceRepo string
+}
+
+// provenanceStatement is an in-toto statement with any predicate.
+type provenanceStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// slsaPredicate holds the fields of SLSA provenance v0.2 and v1 predicates
+// identifying the builder and source of a build.
+type slsaPredicate struct {
+	// v0.2
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	Invocation struct {
+		ConfigSource struct {
+			URI string `json:"uri"`
+		} `json:"configSource"`
+	} `json:"invocation"`
+	Materials []struct {
+		URI string `json:"uri"`
+	} `json:"materials"`
+
+	// v1
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+	BuildDefinition struct {
+		ExternalParameters struct {
+			Workflow struct {
+				Repository string `json:"repository"`
+			} `json:"workflow"`
+		} `json:"externalParameters"`
+		ResolvedDependencies []struct {
+			URI string `json:"uri"`
+		} `json:"resolvedDependencies"`
+	} `json:"buildDefinition"`
+}
+
+// ParseProvenance returns the builder and source of a SLSA provenance
+// predicate of predicateType.
+func ParseProvenance(predicateType string, predicate []byte) (Provenance, error) {
+	var p slsaPredicate
+	if err := json.Unmarshal(predicate, &p); err != nil {
+		return Provenance{}, fmt.Errorf("invalid provenance: %v", err)
+	}
+
+	prov := Provenance{PredicateType: predicateType}
+	switch predicateType {
+	case SLSAProvenanceV02:
+		prov.BuilderID = p.Builder.ID
+		prov.SourceRepo = p.Invocation.ConfigSource.URI
+		if prov.SourceRepo == "" && len(p.Materials) > 0 {
+			prov.SourceRepo = p.Materials[0].URI
+		}
+	case SLSAProvenanceV1:
+		prov.BuilderID = p.RunDetails.Builder.ID
+		prov.SourceRepo = p.BuildDefinition.ExternalParameters.Workflow.Repository
+		if prov.SourceRepo == "" && len(p.BuildDefinition.ResolvedDependencies) > 0 {
+			prov.SourceRepo = p.BuildDefinition.ResolvedDependencies[0].URI
+		}
+	default:
+		return Provenance{}, fmt.Errorf("unsupported predicate type %q", predicateType)
+	}
+	if prov.BuilderID == "" {
+		return Provenance{}, fmt.Errorf("provenance has no builder ID")
+	}
+	return prov, nil
+}
+
+// BuilderAllowed reports whether id is one of builders. A builder ending
+// with * matches any ID with that prefix, e.g. a reusable workflow at any
+// ref. Any builder is allowed if builders is empty.
+func BuilderAllowed(id string, builders []string) bool {
+	if len(builders) == 0 {
+		return true
+	}
+	for _, b := range builders {
+		if b == id || (strings.HasSuffix(b, "*") && strings.HasPrefix(id, strings.TrimSuffix(b, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyProvenance returns the provenance of the image with digest dgst,
+// e.g. sha256:abc..., from the first of envelopes that holds a SLSA
+// provenance statement about the image, signed by v, whose builder is one of
+// builders. ErrNoProvenance is returned if none of envelopes is a SLSA
+// provenance statement, and an error giving the reason each was rejected if
+// none is trusted.
+func VerifyProvenance(envelopes [][]byte, dgst string, v signature.Verifier, builders []string) (Provenance, error) {
+	var reasons []string
+	for _, b := range envelopes {
+		var env Envelope
+		if err := json.Unmarshal(b, &env); err != nil {
+			sylog.Debugf("Skipping invalid DSSE envelope: %v", err)
+			continue
+		}
+		st, err := decodeStatement(env)
+		if err != nil {
+			sylog.Debugf("Skipping DSSE envelope: %v", err)
+			continue
+		}
+		if st.PredicateType != SLSAProvenanceV02 && st.PredicateType != SLSAProvenanceV1 {
+			sylog.Debugf("Skipping attestation of predicate type %s", st.PredicateType)
+			continue
+		}
+
+		prov, err := verifyProvenance(env, st, dgst, v, builders)
+		if err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		return prov, nil
+	}
+	if len(reasons) == 0 {
+		return Provenance{}, ErrNoProvenance
+	}
+	return Provenance{}, fmt.Errorf("no trusted SLSA provenance: %s", strings.Join(reasons, "; "))
+}
+
+// decodeStatement returns the in-toto statement held by env, without
+// verifying its signatures.
+func decodeStatement(env Envelope) (provenanceStatement, error) {
+	if env.PayloadType != PayloadType {
+		return provenanceStatement{}, fmt.Errorf("unexpected payload type %q", env.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return provenanceStatement{}, fmt.Errorf("invalid payload: %v", err)
+	}
+	var st provenanceStatement
+	if err := json.Unmarshal(payload, &st); err != nil {
+		return provenanceStatement{}, fmt.Errorf("invalid statement: %v", err)
+	}
+	return st, nil
+}
+
+// verifyProvenance checks that env, holding the SLSA provenance statement
+// st, is signed by v, is about the image with digest dgst, and was built by
+// one of builders.
+func verifyProvenance(env Envelope, st provenanceStatement, dgst string, v signature.Verifier, builders []string) (Provenance, error) {
+	if err := verifyEnvelopeSignature(env, v); err != nil {
+		return Provenance{}, err
+	}
+
+	alg, hex, ok := strings.Cut(dgst, ":")
+	if !ok {
+		return Provenance{}, fmt.Errorf("invalid image digest %q", dgst)
+	}
+	found := false
+	for _, s := range st.Subject {
+		if s.Digest[alg] == hex {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return Provenance{}, fmt.Errorf("provenance is not about image %s", dgst)
+	}
+
+	prov, err := ParseProvenance(st.PredicateType, st.Predicate)
+	if err != nil {
+		return Provenance{}, err
+	}
+	if !BuilderAllowed(prov.BuilderID, builders) {
+		return Provenance{}, fmt.Errorf("builder %s is not allowed", prov.BuilderID)
+	}
+	return prov, nil
+}
+
+// verifyEnvelopeSignature checks that a signature of env is by v.
+func verifyEnvelopeSignature(env Envelope, v signature.Verifier) error {
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+	if len(env.Signatures) == 0 {
+		return fmt.Errorf("provenance is not signed")
+	}
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if err := v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(pae(env.PayloadType, payload))); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("provenance is not signed by a trusted key")
+}
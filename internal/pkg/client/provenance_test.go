@@ -0,0 +1,181 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+const (
+	testImageDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testBuilderID   = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.5.0"
+)
+
+// testProvenance returns a DSSE envelope, signed by s, holding a statement
+// of predicateType about the image with digest dgst, with predicate.
+func testProvenance(t *testing.T, s signature.Signer, predicateType, dgst, predicate string) []byte {
+	t.Helper()
+
+	alg, hex, _ := strings.Cut(dgst, ":")
+	st := provenanceStatement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: "ghcr.io/example/app", Digest: map[string]string{alg: hex}}},
+		PredicateType: predicateType,
+		Predicate:     json.RawMessage(predicate),
+	}
+	payload, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := s.SignMessage(bytes.NewReader(pae(PayloadType, payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestParseProvenance(t *testing.T) {
+	tests := []struct {
+		name          string
+		predicateType string
+		predicate     string
+		want          Provenance
+		wantErr       bool
+	}{
+		{
+			name:          "V02",
+			predicateType: SLSAProvenanceV02,
+			predicate:     `{"builder":{"id":"builder"},"invocation":{"configSource":{"uri":"git+https://github.com/example/app@refs/heads/main"}}}`,
+			want:          Provenance{PredicateType: SLSAProvenanceV02, BuilderID: "builder", SourceRepo: "git+https://github.com/example/app@refs/heads/main"},
+		},
+		{
+			name:          "V02Materials",
+			predicateType: SLSAProvenanceV02,
+			predicate:     `{"builder":{"id":"builder"},"materials":[{"uri":"git+https://github.com/example/app"}]}`,
+			want:          Provenance{PredicateType: SLSAProvenanceV02, BuilderID: "builder", SourceRepo: "git+https://github.com/example/app"},
+		},
+		{
+			name:          "V1",
+			predicateType: SLSAProvenanceV1,
+			predicate:     `{"buildDefinition":{"externalParameters":{"workflow":{"repository":"https://github.com/example/app"}}},"runDetails":{"builder":{"id":"builder"}}}`,
+			want:          Provenance{PredicateType: SLSAProvenanceV1, BuilderID: "builder", SourceRepo: "https://github.com/example/app"},
+		},
+		{
+			name:          "NoSource",
+			predicateType: SLSAProvenanceV1,
+			predicate:     `{"runDetails":{"builder":{"id":"builder"}}}`,
+			want:          Provenance{PredicateType: SLSAProvenanceV1, BuilderID: "builder"},
+		},
+		{name: "NoBuilder", predicateType: SLSAProvenanceV02, predicate: `{}`, wantErr: true},
+		{name: "OtherPredicate", predicateType: PullPredicateType, predicate: `{}`, wantErr: true},
+		{name: "Invalid", predicateType: SLSAProvenanceV1, predicate: `[]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProvenance(tt.predicateType, []byte(tt.predicate))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		builders []string
+		want     bool
+	}{
+		{name: "Any", want: true},
+		{name: "Exact", builders: []string{"other", testBuilderID}, want: true},
+		{name: "Prefix", builders: []string{"https://github.com/slsa-framework/slsa-github-generator/*"}, want: true},
+		{name: "Other", builders: []string{"https://github.com/example/*", "other"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuilderAllowed(testBuilderID, tt.builders); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	sv, _, err := signature.NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := signature.NewDefaultECDSASignerVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	predicate := `{"builder":{"id":"` + testBuilderID + `"},"invocation":{"configSource":{"uri":"git+https://github.com/example/app"}}}`
+	valid := testProvenance(t, sv, SLSAProvenanceV02, testImageDigest, predicate)
+	otherKey := testProvenance(t, other, SLSAProvenanceV02, testImageDigest, predicate)
+	otherImage := testProvenance(t, sv, SLSAProvenanceV02, "sha256:"+strings.Repeat("f", 64), predicate)
+	pull := testProvenance(t, sv, PullPredicateType, testImageDigest, `{}`)
+
+	tests := []struct {
+		name      string
+		envelopes [][]byte
+		builders  []string
+		wantErr   error
+		wantAny   bool
+	}{
+		{name: "Valid", envelopes: [][]byte{valid}},
+		{name: "ValidAfterOthers", envelopes: [][]byte{[]byte("{"), pull, otherKey, valid}},
+		{name: "AllowedBuilder", envelopes: [][]byte{valid}, builders: []string{"https://github.com/slsa-framework/*"}},
+		{name: "None", wantErr: ErrNoProvenance},
+		{name: "NoSLSA", envelopes: [][]byte{pull}, wantErr: ErrNoProvenance},
+		{name: "Untrusted", envelopes: [][]byte{otherKey}, wantAny: true},
+		{name: "OtherImage", envelopes: [][]byte{otherImage}, wantAny: true},
+		{name: "DisallowedBuilder", envelopes: [][]byte{valid}, builders: []string{"https://github.com/example/*"}, wantAny: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov, err := VerifyProvenance(tt.envelopes, testImageDigest, sv, tt.builders)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAny:
+				if err == nil || errors.Is(err, ErrNoProvenance) {
+					t.Fatalf("got error %v, want an untrusted provenance error", err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if prov.BuilderID != testBuilderID || prov.SourceRepo != "git+https://github.com/example/app" {
+					t.Errorf("got provenance %+v", prov)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// DefaultMaxRedirects is the maximum number of redirects followed by a
+// request, unless set otherwise.
+const DefaultMaxRedirects = 10
+
+// CheckRedirect returns an http.Client CheckRedirect function following at
+// most max redirects. Each redirect is logged at debug level. Once the limit
+// is reached, the error returned lists the URLs visited, without their
+// credentials.
+func CheckRedirect(max int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		prev := via[len(via)-1].URL
+		if prev.Host != req.URL.Host {
+			sylog.Debugf("Redirected from %s to %s on another host", redactURL(prev), redactURL(req.URL))
+		} else {
+			sylog.Debugf("Redirected from %s to %s", redactURL(prev), redactURL(req.URL))
+		}
+
+		if len(via) > max {
+			chain := make([]string, 0, len(via)+1)
+			for _, r := range via {
+				chain = append(chain, redactURL(r.URL))
+			}
+			chain = append(chain, redactURL(req.URL))
+			return fmt.Errorf("stopped after %d redirects: %s", max, strings.Join(chain, " -> "))
+		}
+		return nil
+	}
+}
+
+// redactURL returns u as a string, with any user information and query, which
+// may hold credentials or signed tokens, redacted.
+func redactURL(u *url.URL) string {
+	r := *u
+	if r.User != nil {
+		r.User = url.User("REDACTED")
+	}
+	if r.RawQuery != "" {
+		r.RawQuery = "REDACTED"
+	}
+	return r.String()
+}
+
+type maxRedirectsKey struct{}
+
+// WithMaxRedirects returns a copy of ctx carrying max, the maximum number of
+// redirects followed by HTTP clients using ctx.
+func WithMaxRedirects(ctx context.Context, max int) context.Context {
+	return context.WithValue(ctx, maxRedirectsKey{}, max)
+}
+
+// MaxRedirectsFromContext returns the maximum number of redirects carried by
+// ctx, or DefaultMaxRedirects if there is none.
+func MaxRedirectsFromContext(ctx context.Context) int {
+	if max, ok := ctx.Value(maxRedirectsKey{}).(int); ok {
+		return max
+	}
+	return DefaultMaxRedirects
+}
+
+// HTTPClient returns an HTTP client using Transport, following at most the
+// maximum number of redirects carried by ctx.
+func HTTPClient(ctx context.Context) *http.Client {
+	return &http.Client{
+		Transport:     Transport(),
+		CheckRedirect: CheckRedirect(MaxRedirectsFromContext(ctx)),
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	// /n redirects to /n-1, and /0 serves the content.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if n == 0 {
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/%d?token=secret", n-1), http.StatusFound)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		redirects int
+		max       int
+		wantError bool
+	}{
+		{name: "None", redirects: 0, max: 0},
+		{name: "WithinLimit", redirects: 3, max: 3},
+		{name: "OverLimit", redirects: 4, max: 3, wantError: true},
+		{name: "Disallowed", redirects: 1, max: 0, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &http.Client{CheckRedirect: CheckRedirect(tt.max)}
+			u := strings.Replace(srv.URL, "http://", "http://user:pass@", 1)
+			res, err := c.Get(fmt.Sprintf("%s/%d", u, tt.redirects))
+			if (err != nil) != tt.wantError {
+				t.Fatalf("got error %v, want error %v", err, tt.wantError)
+			}
+			if err == nil {
+				res.Body.Close()
+				return
+			}
+			// The error of CheckRedirect is wrapped with the location
+			// of the last redirect, as sent by the server.
+			var ue *url.Error
+			if !errors.As(err, &ue) {
+				t.Fatalf("unexpected error type %T", err)
+			}
+			err = ue.Err
+			if strings.Contains(err.Error(), "pass") || strings.Contains(err.Error(), "secret") {
+				t.Errorf("credentials not redacted in error: %v", err)
+			}
+			if !strings.Contains(err.Error(), " -> ") {
+				t.Errorf("redirect chain missing from error: %v", err)
+			}
+		})
+	}
+}
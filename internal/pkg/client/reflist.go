@@ -0,0 +1,36 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ReadRefs reads image references from r, one per line, and calls f with
+// each reference and its line number as soon as its line is read, so that
+// references can be pulled while r is still being written. Blank lines, and
+// lines starting with #, are skipped. A last line without a newline is read
+// at EOF. Reading stops at the first error returned by f, which is returned.
+func ReadRefs(r io.Reader, f func(line int, ref string) error) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		s, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if ref := strings.TrimSpace(s); ref != "" && !strings.HasPrefix(ref, "#") {
+			if ferr := f(line, ref); ferr != nil {
+				return ferr
+			}
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
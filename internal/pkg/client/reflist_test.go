@@ -0,0 +1,72 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadRefs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "Empty", input: ""},
+		{name: "Lines", input: "docker://alpine\nlibrary://busybox\n", want: []string{"1:docker://alpine", "2:library://busybox"}},
+		{name: "PartialLastLine", input: "docker://alpine\ndocker://nginx", want: []string{"1:docker://alpine", "2:docker://nginx"}},
+		{name: "CRLF", input: "docker://alpine\r\ndocker://nginx\r\n", want: []string{"1:docker://alpine", "2:docker://nginx"}},
+		{name: "BlankAndComments", input: "\n# images\n  docker://alpine  \n\t\n#docker://nginx\n", want: []string{"3:docker://alpine"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			// Reading a byte at a time splits lines across reads, as a
+			// pipe can.
+			r := iotest.OneByteReader(strings.NewReader(tt.input))
+			err := ReadRefs(r, func(line int, ref string) error {
+				got = append(got, fmt.Sprintf("%d:%s", line, ref))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRefsErrors(t *testing.T) {
+	errStop := errors.New("stop")
+	n := 0
+	err := ReadRefs(strings.NewReader("a\nb\nc\n"), func(int, string) error {
+		n++
+		return errStop
+	})
+	if !errors.Is(err, errStop) || n != 1 {
+		t.Errorf("got error %v after %d references, want %v after 1", err, n, errStop)
+	}
+
+	errRead := errors.New("read")
+	n = 0
+	r := io.MultiReader(strings.NewReader("a\n"), iotest.ErrReader(errRead))
+	err = ReadRefs(r, func(int, string) error {
+		n++
+		return nil
+	})
+	if !errors.Is(err, errRead) || n != 1 {
+		t.Errorf("got error %v after %d references, want %v after 1", err, n, errRead)
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	useragent "github.com/sylabs/singularity/pkg/util/user-agent"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// PullMetrics are the metrics of the pull of an image.
+type PullMetrics struct {
+	// Transport is the transport of the image pulled, and Registry the host
+	// of the registry, or library, it was pulled from, if any. The image
+	// itself is not recorded, so that the number of series stays bounded.
+	Transport string
+	Registry  string
+	// Time is when the pull ended, and Duration how long it took.
+	Time     time.Time
+	Duration time.Duration
+	// Bytes is the size of the pulled image, or 0 if unknown.
+	Bytes int64
+	// CacheHits and CacheMisses count the images found, and not found, in
+	// the cache by the pull.
+	CacheHits   int64
+	CacheMisses int64
+	// Success is set if the image was pulled.
+	Success bool
+}
+
+// sample is a sample of a time series of a remote-write request.
+type sample struct {
+	value float64
+	ms    int64
+}
+
+// series is a time series of a remote-write request.
+type series struct {
+	labels  [][2]string
+	samples []sample
+}
+
+// remoteWriteSeries returns the time series of metrics, labelled with job,
+// instance, and the transport and registry of each pull. The samples of the
+// same metric for the same labels are held by a single series, in time
+// order, with only the latest of several samples at the same millisecond.
+func remoteWriteSeries(metrics []PullMetrics, job, instance string) []series {
+	var out []series
+	index := make(map[string]int)
+	add := func(name string, m PullMetrics, value float64) {
+		labels := [][2]string{
+			{"__name__", name},
+			{"instance", instance},
+			{"job", job},
+			{"registry", m.Registry},
+			{"transport", m.Transport},
+		}
+		sort.Slice(labels, func(i, j int) bool { return labels[i][0] < labels[j][0] })
+
+		var key strings.Builder
+		for _, l := range labels {
+			key.WriteString(l[0] + "\x00" + l[1] + "\x00")
+		}
+		i, ok := index[key.String()]
+		if !ok {
+			i = len(out)
+			index[key.String()] = i
+			out = append(out, series{labels: labels})
+		}
+		out[i].samples = append(out[i].samples, sample{value: value, ms: m.Time.UnixMilli()})
+	}
+
+	sorted := append([]PullMetrics(nil), metrics...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	for _, m := range sorted {
+		add("singularity_pull_duration_seconds", m, m.Duration.Seconds())
+		success := 0.0
+		if m.Success {
+			success = 1
+		}
+		add("singularity_pull_success", m, success)
+		if m.Bytes > 0 {
+			add("singularity_pull_bytes", m, float64(m.Bytes))
+		}
+		if n := m.CacheHits + m.CacheMisses; n > 0 {
+			add("singularity_pull_cache_hit_ratio", m, float64(m.CacheHits)/float64(n))
+		}
+	}
+
+	// A remote-write endpoint rejects a second sample of a series at the
+	// same timestamp, so only the latest is kept.
+	for i, s := range out {
+		samples := s.samples[:0]
+		for _, smp := range s.samples {
+			if n := len(samples); n > 0 && samples[n-1].ms == smp.ms {
+				samples[n-1] = smp
+				continue
+			}
+			samples = append(samples, smp)
+		}
+		out[i].samples = samples
+	}
+	return out
+}
+
+// encodeWriteRequest returns the protobuf encoding of a prometheus.WriteRequest
+// holding ts.
+func encodeWriteRequest(ts []series) []byte {
+	var req []byte
+	for _, s := range ts {
+		var b []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l[0])
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l[1])
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendBytes(b, lb)
+		}
+		for _, smp := range s.samples {
+			var sb []byte
+			sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+			sb = protowire.AppendFixed64(sb, math.Float64bits(smp.value))
+			sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+			sb = protowire.AppendVarint(sb, uint64(smp.ms))
+			b = protowire.AppendTag(b, 2, protowire.BytesType)
+			b = protowire.AppendBytes(b, sb)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, b)
+	}
+	return req
+}
+
+// remoteWriteAttempts is the number of attempts made to push metrics, and
+// remoteWriteBackoff the delay before the second one, doubled after each
+// failed attempt.
+var (
+	remoteWriteAttempts = 3
+	remoteWriteBackoff  = time.Second
+)
+
+// PushRemoteWrite pushes metrics, labelled with job and instance, to the
+// Prometheus remote-write endpoint rawURL, in a single request. Credentials
+// in rawURL are sent with basic authentication, and token, if set, as a
+// bearer token. The push is retried on connection errors, rate limiting and
+// server errors, as the remote-write protocol requires.
+func PushRemoteWrite(ctx context.Context, rawURL, token, job, instance string, metrics []PullMetrics) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid remote-write URL: %v", err)
+	}
+	user := u.User
+	u.User = nil
+
+	body := s2.EncodeSnappy(nil, encodeWriteRequest(remoteWriteSeries(metrics, job, instance)))
+	c := HTTPClient(ctx)
+
+	backoff := remoteWriteBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := pushRemoteWrite(ctx, c, u.String(), user, token, body)
+		if err == nil || !retry || attempt == remoteWriteAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// pushRemoteWrite posts body to the remote-write endpoint u with c, and
+// reports whether a failure may be retried.
+func pushRemoteWrite(ctx context.Context, c *http.Client, u string, user *url.Userinfo, token string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	req.Header.Set("User-Agent", useragent.Value())
+	if user != nil {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 == 2 {
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+	retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+	return retry, fmt.Errorf("remote-write endpoint returned %s: %s", res.Status, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	useragent "github.com/sylabs/singularity/pkg/util/user-agent"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func init() {
+	useragent.InitValue("singularity", "3.0.0")
+}
+
+// decodeWriteRequest decodes the time series of the protobuf encoding of a
+// prometheus.WriteRequest.
+func decodeWriteRequest(t *testing.T, b []byte) []series {
+	t.Helper()
+
+	// fields calls f with the number and value of each field of b.
+	fields := func(b []byte, f func(protowire.Number, protowire.Type, []byte)) {
+		for len(b) > 0 {
+			num, typ, n := protowire.ConsumeTag(b)
+			if n < 0 {
+				t.Fatalf("invalid tag: %v", protowire.ParseError(n))
+			}
+			b = b[n:]
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				t.Fatalf("invalid field %d: %v", num, protowire.ParseError(n))
+			}
+			f(num, typ, b[:n])
+			b = b[n:]
+		}
+	}
+	bytesValue := func(b []byte) []byte {
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			t.Fatalf("invalid bytes: %v", protowire.ParseError(n))
+		}
+		return v
+	}
+
+	var ts []series
+	fields(b, func(num protowire.Number, _ protowire.Type, v []byte) {
+		if num != 1 {
+			return
+		}
+		var s series
+		fields(bytesValue(v), func(num protowire.Number, _ protowire.Type, v []byte) {
+			switch num {
+			case 1:
+				var l [2]string
+				fields(bytesValue(v), func(num protowire.Number, _ protowire.Type, v []byte) {
+					l[num-1] = string(bytesValue(v))
+				})
+				s.labels = append(s.labels, l)
+			case 2:
+				var smp sample
+				fields(bytesValue(v), func(num protowire.Number, _ protowire.Type, v []byte) {
+					switch num {
+					case 1:
+						bits, _ := protowire.ConsumeFixed64(v)
+						smp.value = math.Float64frombits(bits)
+					case 2:
+						ms, _ := protowire.ConsumeVarint(v)
+						smp.ms = int64(ms)
+					}
+				})
+				s.samples = append(s.samples, smp)
+			}
+		})
+		ts = append(ts, s)
+	})
+	return ts
+}
+
+// seriesValues returns the values of the samples of ts, by metric name.
+func seriesValues(ts []series) map[string][]float64 {
+	values := make(map[string][]float64)
+	for _, s := range ts {
+		for _, l := range s.labels {
+			if l[0] != "__name__" {
+				continue
+			}
+			for _, smp := range s.samples {
+				values[l[1]] = append(values[l[1]], smp.value)
+			}
+		}
+	}
+	return values
+}
+
+var testPullMetrics = []PullMetrics{
+	{
+		Transport:   "docker",
+		Registry:    "docker.io",
+		Time:        time.UnixMilli(2000),
+		Duration:    1500 * time.Millisecond,
+		Bytes:       1024,
+		CacheHits:   3,
+		CacheMisses: 1,
+		Success:     true,
+	},
+	{
+		Transport: "docker",
+		Registry:  "docker.io",
+		Time:      time.UnixMilli(1000),
+		Duration:  time.Second,
+		Success:   false,
+	},
+	{
+		Transport: "oras",
+		Registry:  "ghcr.io",
+		Time:      time.UnixMilli(1000),
+		Duration:  time.Second,
+		Success:   true,
+	},
+}
+
+func TestRemoteWriteSeries(t *testing.T) {
+	ts := remoteWriteSeries(testPullMetrics, "singularity", "host")
+
+	values := seriesValues(ts)
+	want := map[string][]float64{
+		"singularity_pull_duration_seconds": {1, 1.5, 1},
+		"singularity_pull_success":          {0, 1, 1},
+		"singularity_pull_bytes":            {1024},
+		"singularity_pull_cache_hit_ratio":  {0.75},
+	}
+	// The duration and success of each registry, and the size and hit ratio
+	// of docker.io only.
+	if len(ts) != 6 {
+		t.Errorf("got %d series, want 6", len(ts))
+	}
+	for name, w := range want {
+		got := values[name]
+		if len(got) != len(w) {
+			t.Errorf("%s: got %v, want %v", name, got, w)
+			continue
+		}
+		for i := range w {
+			if got[i] != w[i] {
+				t.Errorf("%s: got %v, want %v", name, got, w)
+			}
+		}
+	}
+
+	for _, s := range ts {
+		for i := 1; i < len(s.labels); i++ {
+			if s.labels[i-1][0] >= s.labels[i][0] {
+				t.Errorf("labels not sorted: %v", s.labels)
+			}
+		}
+		for i := 1; i < len(s.samples); i++ {
+			if s.samples[i-1].ms >= s.samples[i].ms {
+				t.Errorf("samples not in time order: %v", s.samples)
+			}
+		}
+	}
+}
+
+func TestRemoteWriteSeriesSameTime(t *testing.T) {
+	metrics := []PullMetrics{
+		{Transport: "library", Time: time.UnixMilli(1000), Duration: time.Second},
+		{Transport: "library", Time: time.UnixMilli(1000), Duration: 2 * time.Second},
+	}
+	values := seriesValues(remoteWriteSeries(metrics, "singularity", "host"))
+	if got := values["singularity_pull_duration_seconds"]; len(got) != 1 || got[0] != 2 {
+		t.Errorf("got durations %v, want only the latest, [2]", got)
+	}
+}
+
+func TestPushRemoteWrite(t *testing.T) {
+	var got []series
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ce := r.Header.Get("Content-Encoding"); ce != "snappy" {
+			t.Errorf("got Content-Encoding %q, want snappy", ce)
+		}
+		if v := r.Header.Get("X-Prometheus-Remote-Write-Version"); v != "0.1.0" {
+			t.Errorf("got remote-write version %q, want 0.1.0", v)
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "user" || password != "secret" {
+			t.Errorf("got basic auth %q %q %v, want user secret", user, password, ok)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := s2.Decode(nil, body)
+		if err != nil {
+			t.Fatalf("invalid snappy payload: %v", err)
+		}
+		got = decodeWriteRequest(t, req)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	u := strings.Replace(srv.URL, "://", "://user:secret@", 1)
+	if err := PushRemoteWrite(context.Background(), u, "", "singularity", "host", testPullMetrics); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := remoteWriteSeries(testPullMetrics, "singularity", "host")
+	if len(got) != len(want) {
+		t.Fatalf("got %d series, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i].labels) != len(want[i].labels) || len(got[i].samples) != len(want[i].samples) {
+			t.Fatalf("got series %v, want %v", got[i], want[i])
+		}
+		for j := range want[i].labels {
+			if got[i].labels[j] != want[i].labels[j] {
+				t.Errorf("got label %v, want %v", got[i].labels[j], want[i].labels[j])
+			}
+		}
+		for j := range want[i].samples {
+			if got[i].samples[j] != want[i].samples[j] {
+				t.Errorf("got sample %v, want %v", got[i].samples[j], want[i].samples[j])
+			}
+		}
+	}
+}
+
+func TestPushRemoteWriteRetry(t *testing.T) {
+	defer func(b time.Duration) { remoteWriteBackoff = b }(remoteWriteBackoff)
+	remoteWriteBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		wantReqs int
+	}{
+		{name: "Success", statuses: []int{http.StatusOK}, wantReqs: 1},
+		{name: "ServerError", statuses: []int{http.StatusInternalServerError, http.StatusNoContent}, wantReqs: 2},
+		{name: "RateLimited", statuses: []int{http.StatusTooManyRequests, http.StatusNoContent}, wantReqs: 2},
+		{name: "BadRequest", statuses: []int{http.StatusBadRequest}, wantErr: true, wantReqs: 1},
+		{name: "Unavailable", statuses: []int{http.StatusServiceUnavailable}, wantErr: true, wantReqs: remoteWriteAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if auth := r.Header.Get("Authorization"); auth != "Bearer token" {
+					t.Errorf("got Authorization %q, want a bearer token", auth)
+				}
+				status := tt.statuses[len(tt.statuses)-1]
+				if reqs < len(tt.statuses) {
+					status = tt.statuses[reqs]
+				}
+				reqs++
+				w.WriteHeader(status)
+			}))
+			defer srv.Close()
+
+			err := PushRemoteWrite(context.Background(), srv.URL, "token", "singularity", "host", testPullMetrics)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if reqs != tt.wantReqs {
+				t.Errorf("got %d requests, want %d", reqs, tt.wantReqs)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// MaxSchemaVersion is the newest schemaVersion of image manifests, indexes
+// and manifest lists supported by the conversion of docker/OCI images.
+const MaxSchemaVersion = 2
+
+// schemaFamilies are the media types of the image manifests, indexes,
+// manifest lists and configs, with the newest version of each supported by
+// the conversion of docker/OCI images. The version of a media type follows
+// the prefix of its family.
+var schemaFamilies = []struct {
+	prefix    string
+	version   int
+	supported string
+}{
+	{"application/vnd.oci.image.manifest.v", 1, imgspecv1.MediaTypeImageManifest},
+	{"application/vnd.oci.image.index.v", 1, imgspecv1.MediaTypeImageIndex},
+	{"application/vnd.oci.image.config.v", 1, imgspecv1.MediaTypeImageConfig},
+	{"application/vnd.docker.distribution.manifest.list.v", 2, "application/vnd.docker.distribution.manifest.list.v2+json"},
+	{"application/vnd.docker.distribution.manifest.v", 2, "application/vnd.docker.distribution.manifest.v2+json"},
+	{"application/vnd.docker.container.image.v", 1, "application/vnd.docker.container.image.v1+json"},
+}
+
+// UnsupportedSchemaError is returned for an image using a schema version, or
+// a version of a media type, newer than the conversion of docker/OCI images
+// supports.
+type UnsupportedSchemaError struct {
+	// Object is the part of the image using the schema, e.g. manifest.
+	Object string
+	// Schema is the unsupported schema version or media type.
+	Schema string
+	// Supported is the newest schema version or media type supported.
+	Supported string
+}
+
+func (e *UnsupportedSchemaError) Error() string {
+	return fmt.Sprintf("image %s uses %s, newer than the newest supported, %s: "+
+		"the image must be pushed again in a supported format, e.g. with 'skopeo copy --format oci' or 'docker push'",
+		e.Object, e.Schema, e.Supported)
+}
+
+// schemaManifest holds the fields of an image manifest, index or manifest
+// list identifying their schema, and those of the descriptors they hold.
+type schemaManifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	MediaType     string `json:"mediaType"`
+	Config        struct {
+		MediaType string `json:"mediaType"`
+	} `json:"config"`
+	Manifests []struct {
+		MediaType string `json:"mediaType"`
+	} `json:"manifests"`
+}
+
+// CheckManifestSchema returns an *UnsupportedSchemaError if the image
+// manifest, index or manifest list man, served as mediaType, or the config or
+// manifests it refers to, use a schema version or a version of a media type
+// newer than the conversion of docker/OCI images supports. Media types of
+// other families, e.g. of artifacts, are not checked.
+func CheckManifestSchema(man []byte, mediaType string) error {
+	var m schemaManifest
+	if err := json.Unmarshal(man, &m); err != nil {
+		return fmt.Errorf("invalid manifest: %v", err)
+	}
+
+	if m.SchemaVersion > MaxSchemaVersion {
+		return &UnsupportedSchemaError{
+			Object:    "manifest",
+			Schema:    fmt.Sprintf("schema version %d", m.SchemaVersion),
+			Supported: fmt.Sprintf("schema version %d", MaxSchemaVersion),
+		}
+	}
+	if err := checkMediaType("manifest", mediaType); err != nil {
+		return err
+	}
+	if err := checkMediaType("manifest", m.MediaType); err != nil {
+		return err
+	}
+	if err := checkMediaType("config", m.Config.MediaType); err != nil {
+		return err
+	}
+	for _, d := range m.Manifests {
+		if err := checkMediaType("index entry", d.MediaType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkMediaType returns an *UnsupportedSchemaError for object if mediaType
+// is a version of one of schemaFamilies newer than supported.
+func checkMediaType(object, mediaType string) error {
+	for _, f := range schemaFamilies {
+		if !strings.HasPrefix(mediaType, f.prefix) {
+			continue
+		}
+		v := strings.TrimPrefix(mediaType, f.prefix)
+		if i := strings.IndexFunc(v, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+			v = v[:i]
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		if n > f.version {
+			return &UnsupportedSchemaError{Object: object, Schema: "media type " + mediaType, Supported: f.supported}
+		}
+		return nil
+	}
+	return nil
+}
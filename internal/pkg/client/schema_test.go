@@ -0,0 +1,112 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckManifestSchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		manifest      string
+		mediaType     string
+		wantSchema    string
+		wantSupported string
+		wantErr       bool
+	}{
+		{
+			name:      "OCIManifest",
+			manifest:  `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json"}}`,
+			mediaType: "application/vnd.oci.image.manifest.v1+json",
+		},
+		{
+			name:      "OCIIndex",
+			manifest:  `{"schemaVersion":2,"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json"}]}`,
+			mediaType: "application/vnd.oci.image.index.v1+json",
+		},
+		{
+			name:      "DockerSchema2",
+			manifest:  `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{"mediaType":"application/vnd.docker.container.image.v1+json"}}`,
+			mediaType: "application/vnd.docker.distribution.manifest.v2+json",
+		},
+		{
+			name:      "DockerManifestList",
+			manifest:  `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.list.v2+json","manifests":[{"mediaType":"application/vnd.docker.distribution.manifest.v2+json"}]}`,
+			mediaType: "application/vnd.docker.distribution.manifest.list.v2+json",
+		},
+		{
+			name:      "DockerSchema1",
+			manifest:  `{"schemaVersion":1,"name":"library/alpine","fsLayers":[]}`,
+			mediaType: "application/vnd.docker.distribution.manifest.v1+prettyjws",
+		},
+		{
+			name:     "ArtifactConfig",
+			manifest: `{"schemaVersion":2,"config":{"mediaType":"application/vnd.cncf.helm.config.v1+json"}}`,
+		},
+		{
+			name:          "SchemaVersion",
+			manifest:      `{"schemaVersion":3,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`,
+			wantSchema:    "schema version 3",
+			wantSupported: "schema version 2",
+		},
+		{
+			name:          "ManifestMediaType",
+			manifest:      `{"schemaVersion":2,"config":{"mediaType":"application/vnd.oci.image.config.v1+json"}}`,
+			mediaType:     "application/vnd.oci.image.manifest.v2+json",
+			wantSchema:    "media type application/vnd.oci.image.manifest.v2+json",
+			wantSupported: "application/vnd.oci.image.manifest.v1+json",
+		},
+		{
+			name:          "BodyMediaType",
+			manifest:      `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v3+json"}`,
+			wantSchema:    "media type application/vnd.docker.distribution.manifest.v3+json",
+			wantSupported: "application/vnd.docker.distribution.manifest.v2+json",
+		},
+		{
+			name:          "ConfigMediaType",
+			manifest:      `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v2+json"}}`,
+			wantSchema:    "media type application/vnd.oci.image.config.v2+json",
+			wantSupported: "application/vnd.oci.image.config.v1+json",
+		},
+		{
+			name:          "IndexEntry",
+			manifest:      `{"schemaVersion":2,"manifests":[{"mediaType":"application/vnd.oci.image.manifest.v1+json"},{"mediaType":"application/vnd.oci.image.manifest.v10+json"}]}`,
+			mediaType:     "application/vnd.oci.image.index.v1+json",
+			wantSchema:    "media type application/vnd.oci.image.manifest.v10+json",
+			wantSupported: "application/vnd.oci.image.manifest.v1+json",
+		},
+		{name: "Invalid", manifest: `{"schemaVersion":"2"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckManifestSchema([]byte(tt.manifest), tt.mediaType)
+
+			var schemaErr *UnsupportedSchemaError
+			switch {
+			case tt.wantSchema != "":
+				if !errors.As(err, &schemaErr) {
+					t.Fatalf("got error %v, want an unsupported schema error", err)
+				}
+				if schemaErr.Schema != tt.wantSchema || schemaErr.Supported != tt.wantSupported {
+					t.Errorf("got schema %q, supported %q, want %q, %q", schemaErr.Schema, schemaErr.Supported, tt.wantSchema, tt.wantSupported)
+				}
+				if !strings.Contains(err.Error(), "pushed again") {
+					t.Errorf("got error %q, want a suggestion", err)
+				}
+			case tt.wantErr:
+				if err == nil || errors.As(err, &schemaErr) {
+					t.Errorf("got error %v, want an invalid manifest error", err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// serviceNameRegexp matches valid service names, as accepted by docker
+// compose. A name is used as the file name of the image of the service.
+var serviceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Platform is the platform an image is pulled for.
+type Platform struct {
+	OS           string
+	Architecture string
+	Variant      string
+}
+
+// ParsePlatform parses a platform of the form os/arch[/variant], e.g.
+// linux/arm64 or linux/arm/v7.
+func ParsePlatform(s string) (Platform, error) {
+	fields := strings.Split(s, "/")
+	if len(fields) < 2 || len(fields) > 3 {
+		return Platform{}, fmt.Errorf("invalid platform %q: must be os/arch[/variant]", s)
+	}
+	for _, f := range fields {
+		if f == "" {
+			return Platform{}, fmt.Errorf("invalid platform %q: must be os/arch[/variant]", s)
+		}
+	}
+	p := Platform{OS: fields[0], Architecture: fields[1]}
+	if len(fields) == 3 {
+		p.Variant = fields[2]
+	}
+	if p.OS != "linux" {
+		return Platform{}, fmt.Errorf("invalid platform %q: only linux images are supported", s)
+	}
+	return p, nil
+}
+
+// Service is a service of a services file, whose image is pulled to a SIF
+// named after the service.
+type Service struct {
+	// Name is the name of the service.
+	Name string
+	// Image is the URI of the image of the service. An image without a
+	// transport is a docker image, as in a compose file.
+	Image string
+	// Platform is the platform to pull the image for, if set.
+	Platform *Platform
+}
+
+// servicesFile is the subset of a compose file read from a services file.
+// Other keys, describing how services are run, are ignored.
+type servicesFile struct {
+	Services map[string]struct {
+		Image    string `yaml:"image"`
+		Platform string `yaml:"platform"`
+	} `yaml:"services"`
+}
+
+// ReadServices reads and validates the services of a Compose-like services
+// file from r, sorted by name.
+func ReadServices(r io.Reader) ([]Service, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read services file: %v", err)
+	}
+
+	var f servicesFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode services file: %v", err)
+	}
+	if len(f.Services) == 0 {
+		return nil, fmt.Errorf("no services defined in services file")
+	}
+
+	services := make([]Service, 0, len(f.Services))
+	for name, s := range f.Services {
+		if !serviceNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid service name %q: must match %s", name, serviceNameRegexp)
+		}
+		if s.Image == "" {
+			return nil, fmt.Errorf("service %q: no image specified", name)
+		}
+
+		svc := Service{Name: name, Image: s.Image}
+		if !strings.Contains(svc.Image, "://") {
+			svc.Image = "docker://" + svc.Image
+		}
+		if s.Platform != "" {
+			p, err := ParsePlatform(s.Platform)
+			if err != nil {
+				return nil, fmt.Errorf("service %q: %v", name, err)
+			}
+			svc.Platform = &p
+		}
+		services = append(services, svc)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
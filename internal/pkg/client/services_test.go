@@ -0,0 +1,91 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadServices(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		wantError bool
+		want      []Service
+	}{
+		{
+			name: "Valid",
+			file: `
+version: "3"
+services:
+  web:
+    image: nginx:1.25
+    ports:
+      - "80:80"
+  db:
+    image: library://alpine:3.18
+    platform: linux/arm/v7
+volumes:
+  data: {}
+`,
+			want: []Service{
+				{
+					Name:     "db",
+					Image:    "library://alpine:3.18",
+					Platform: &Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+				},
+				{
+					Name:  "web",
+					Image: "docker://nginx:1.25",
+				},
+			},
+		},
+		{
+			name:      "Empty",
+			file:      "version: \"3\"\n",
+			wantError: true,
+		},
+		{
+			name:      "NoImage",
+			file:      "services:\n  web:\n    build: .\n",
+			wantError: true,
+		},
+		{
+			name:      "BadName",
+			file:      "services:\n  ../web:\n    image: nginx\n",
+			wantError: true,
+		},
+		{
+			name:      "BadPlatform",
+			file:      "services:\n  web:\n    image: nginx\n    platform: arm64\n",
+			wantError: true,
+		},
+		{
+			name:      "NotLinux",
+			file:      "services:\n  web:\n    image: nginx\n    platform: windows/amd64\n",
+			wantError: true,
+		},
+		{
+			name:      "BadYAML",
+			file:      "services: [",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadServices(strings.NewReader(tt.file))
+			if (err != nil) != tt.wantError {
+				t.Fatalf("got error %v, want error %v", err, tt.wantError)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got services %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/sylabs/singularity/internal/pkg/client"
 	"github.com/sylabs/singularity/pkg/sylog"
 	useragent "github.com/sylabs/singularity/pkg/util/user-agent"
 )
@@ -52,7 +53,8 @@ type APIResponse struct {
 func GetManifest(uri URI, noHTTPS bool) (APIResponse, error) {
 	// Create a new http Hub client
 	httpc := http.Client{
-		Timeout: 30 * time.Second,
+		Transport: client.Transport(),
+		Timeout:   30 * time.Second,
 	}
 
 	if uri.registry != defaultRegistry+shubAPIRoute {
@@ -51,7 +51,8 @@ func DownloadImage(ctx context.Context, manifest APIResponse, filePath, shubRef
 
 	// Get the image based on the manifest
 	httpc := http.Client{
-		Timeout: pullTimeout * time.Second,
+		Transport: client.Transport(),
+		Timeout:   pullTimeout * time.Second,
 	}
 
 	req, err := http.NewRequest(http.MethodGet, manifest.Image, nil)
@@ -0,0 +1,148 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	socks5Version = 5
+
+	socks5NoAuth       = 0x00
+	socks5UserPassAuth = 0x02
+	socks5NoAcceptable = 0xff
+
+	// socks5UserPassVersion is the version of the username/password
+	// authentication sub-negotiation, from RFC 1929.
+	socks5UserPassVersion = 1
+)
+
+// ParseSOCKS5 parses a SOCKS5 proxy address of the form
+// [socks5://][user[:password]@]host:port into a socks5:// URL.
+func ParseSOCKS5(s string) (*url.URL, error) {
+	if !strings.Contains(s, "://") {
+		s = "socks5://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: %v", s, err)
+	}
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		u.Scheme = "socks5"
+	default:
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: unsupported scheme %s", s, u.Scheme)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: must be host:port", s)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return nil, fmt.Errorf("invalid SOCKS5 proxy %q: unexpected path or query", s)
+	}
+	u.Path = ""
+	return u, nil
+}
+
+// SOCKS5FromEnvironment returns the SOCKS5 proxy set by the ALL_PROXY (or
+// all_proxy) environment variable, or "" if it doesn't set a SOCKS5 proxy.
+func SOCKS5FromEnvironment() string {
+	for _, k := range []string{"ALL_PROXY", "all_proxy"} {
+		v := os.Getenv(k)
+		if v == "" {
+			continue
+		}
+		if strings.HasPrefix(v, "socks5://") || strings.HasPrefix(v, "socks5h://") {
+			return v
+		}
+		return ""
+	}
+	return ""
+}
+
+// CheckSOCKS5 checks that the SOCKS5 proxy u is reachable, and accepts its
+// credentials if any, by negotiating authentication with it.
+func CheckSOCKS5(ctx context.Context, u *url.URL) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return fmt.Errorf("SOCKS5 proxy %s is not reachable: %v", u.Host, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	method := byte(socks5NoAuth)
+	if u.User != nil {
+		method = socks5UserPassAuth
+	}
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return fmt.Errorf("while negotiating with SOCKS5 proxy %s: %v", u.Host, err)
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("while negotiating with SOCKS5 proxy %s: %v", u.Host, err)
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("%s is not a SOCKS5 proxy", u.Host)
+	}
+	if reply[1] == socks5NoAcceptable {
+		if u.User == nil {
+			return fmt.Errorf("SOCKS5 proxy %s requires authentication", u.Host)
+		}
+		return fmt.Errorf("SOCKS5 proxy %s does not accept username/password authentication", u.Host)
+	}
+	if reply[1] != method {
+		return fmt.Errorf("SOCKS5 proxy %s selected unsupported authentication method %d", u.Host, reply[1])
+	}
+	if method != socks5UserPassAuth {
+		return nil
+	}
+
+	user := u.User.Username()
+	pass, _ := u.User.Password()
+	if len(user) > 255 || len(pass) > 255 {
+		return fmt.Errorf("SOCKS5 username and password must not exceed 255 bytes")
+	}
+	req := []byte{socks5UserPassVersion, byte(len(user))}
+	req = append(req, user...)
+	req = append(req, byte(len(pass)))
+	req = append(req, pass...)
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("while authenticating with SOCKS5 proxy %s: %v", u.Host, err)
+	}
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("while authenticating with SOCKS5 proxy %s: %v", u.Host, err)
+	}
+	if reply[1] != 0 {
+		return fmt.Errorf("SOCKS5 proxy %s rejected the credentials of %s", u.Host, user)
+	}
+	return nil
+}
+
+// socks5Proxy is the proxy of the HTTP transports built by singularity, if
+// set by UseSOCKS5.
+var socks5Proxy *url.URL
+
+// UseSOCKS5 routes the HTTP(S) connections of the transports built by
+// singularity (see Transport) through the SOCKS5 proxy u, including on
+// redirects, instead of the proxy of the environment. The environment is left
+// unchanged, docker/OCI registries are still reached through its proxy. It
+// must be called before any HTTP request is made.
+func UseSOCKS5(u *url.URL) {
+	socks5Proxy = u
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+// pullSourceName is the name of the SIF descriptor holding the PullSource of
+// a SIF converted from a docker/OCI image.
+const pullSourceName = "pull-source.json"
+
+// PullSource records the docker/OCI image a SIF was converted from, so that
+// a later pull can tell whether the SIF is current without reading it.
+type PullSource struct {
+	// Digest is the digest of the manifest of the image, e.g. sha256:...
+	Digest string `json:"digest"`
+	// Variant identifies the options the image was converted with.
+	Variant string `json:"variant,omitempty"`
+}
+
+// WritePullSource records s in the SIF at path, replacing any PullSource
+// already recorded.
+func WritePullSource(path string, s PullSource) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return replaceJSONObject(path, pullSourceName, b)
+}
+
+// ReadPullSource returns the PullSource recorded in the SIF at path. ok is
+// false if the SIF has none, e.g. as it was not converted by a pull.
+func ReadPullSource(path string) (s PullSource, ok bool, err error) {
+	f, err := sif.LoadContainerFromPath(path, sif.OptLoadWithFlag(os.O_RDONLY))
+	if err != nil {
+		return PullSource{}, false, fmt.Errorf("could not load SIF %s: %v", path, err)
+	}
+	defer f.UnloadContainer()
+
+	b, ok, err := readJSONObject(f, pullSourceName)
+	if err != nil || !ok {
+		return PullSource{}, false, err
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return PullSource{}, false, fmt.Errorf("could not decode %s of %s: %v", pullSourceName, path, err)
+	}
+	return s, true, nil
+}
+
+// withName selects the descriptor named name.
+func withName(name string) sif.DescriptorSelectorFunc {
+	return func(d sif.Descriptor) (bool, error) {
+		return d.Name() == name, nil
+	}
+}
+
+// readJSONObject returns the data of the generic JSON object name of f. ok
+// is false if f has no such object.
+func readJSONObject(f *sif.FileImage, name string) (b []byte, ok bool, err error) {
+	d, err := f.GetDescriptor(sif.WithDataType(sif.DataGenericJSON), withName(name))
+	if errors.Is(err, sif.ErrObjectNotFound) || errors.Is(err, sif.ErrNoObjects) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	b, err = d.GetData()
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read %s: %v", name, err)
+	}
+	return b, true, nil
+}
+
+// replaceJSONObject sets the data of the generic JSON object name of the SIF
+// at path to b, adding the object if the SIF has none.
+func replaceJSONObject(path, name string, b []byte) error {
+	f, err := sif.LoadContainerFromPath(path)
+	if err != nil {
+		return fmt.Errorf("could not load SIF %s: %v", path, err)
+	}
+	d, err := f.GetDescriptor(sif.WithDataType(sif.DataGenericJSON), withName(name))
+	switch {
+	case err == nil:
+		// The descriptors in memory are not updated by DeleteObject, so the
+		// SIF is loaded again to add the object.
+		err = f.DeleteObject(d.ID(), sif.OptDeleteZero(true))
+		if unloadErr := f.UnloadContainer(); err == nil {
+			err = unloadErr
+		}
+		if err != nil {
+			return fmt.Errorf("while removing %s: %v", name, err)
+		}
+		if f, err = sif.LoadContainerFromPath(path); err != nil {
+			return fmt.Errorf("could not load SIF %s: %v", path, err)
+		}
+	case !errors.Is(err, sif.ErrObjectNotFound) && !errors.Is(err, sif.ErrNoObjects):
+		f.UnloadContainer()
+		return err
+	}
+	defer f.UnloadContainer()
+
+	di, err := sif.NewDescriptorInput(sif.DataGenericJSON, bytes.NewReader(b), sif.OptObjectName(name))
+	if err != nil {
+		return err
+	}
+	if err := f.AddObject(di); err != nil {
+		return fmt.Errorf("while writing %s: %v", name, err)
+	}
+	return nil
+}
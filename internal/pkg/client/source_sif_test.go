@@ -0,0 +1,55 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sylabs/sif/v2/pkg/sif"
+)
+
+func TestPullSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image.sif")
+	testSIF(t, path, sif.FsSquash, "amd64", "rootfs", false)
+
+	if _, ok, err := ReadPullSource(path); err != nil || ok {
+		t.Fatalf("got source %v, error %v for a SIF without one", ok, err)
+	}
+
+	first := PullSource{Digest: "sha256:1111", Variant: "-abc"}
+	if err := WritePullSource(path, first); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok, err := ReadPullSource(path); err != nil || !ok || s != first {
+		t.Fatalf("got source %+v, %v, error %v, want %+v", s, ok, err, first)
+	}
+
+	// A new source replaces the one recorded.
+	second := PullSource{Digest: "sha256:2222"}
+	if err := WritePullSource(path, second); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok, err := ReadPullSource(path); err != nil || !ok || s != second {
+		t.Fatalf("got source %+v, %v, error %v, want %+v", s, ok, err, second)
+	}
+
+	f, err := sif.LoadContainerFromPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.UnloadContainer()
+	ds, err := f.GetDescriptors(sif.WithDataType(sif.DataGenericJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds) != 1 {
+		t.Errorf("got %d JSON objects, want 1", len(ds))
+	}
+	if _, err := f.GetDescriptor(sif.WithPartitionType(sif.PartPrimSys)); err != nil {
+		t.Errorf("root filesystem lost: %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// SplitManifestSuffix is appended to the name of a split file to name the
+// manifest of its chunks, e.g. alpine.sif.manifest.json.
+const SplitManifestSuffix = ".manifest.json"
+
+// SplitManifest describes the chunks a file is split into. The chunks are
+// in the directory of the manifest, and the file is their concatenation, in
+// order.
+type SplitManifest struct {
+	// File is the name of the file split.
+	File string `json:"file"`
+	// Size and Digest are the size and sha256 digest of the file.
+	Size   int64         `json:"size"`
+	Digest digest.Digest `json:"digest"`
+	// ChunkSize is the size of every chunk but the last.
+	ChunkSize int64 `json:"chunkSize"`
+	// Chunks are the chunks of the file, in order.
+	Chunks []SplitChunk `json:"chunks"`
+}
+
+// SplitChunk is a chunk of a split file.
+type SplitChunk struct {
+	// Name is the file name of the chunk, e.g. alpine.sif.001.
+	Name string `json:"name"`
+	// Size and Digest are the size and sha256 digest of the chunk.
+	Size   int64         `json:"size"`
+	Digest digest.Digest `json:"digest"`
+}
+
+// SplitFile splits the file at path into chunks of chunkSize bytes, the last
+// one being smaller, named after it with a 3 digit sequence number, e.g.
+// alpine.sif.001, alpine.sif.002, ..., and writes their manifest next to
+// them. The file is removed once it is split. Existing chunks, or manifest,
+// are only overwritten if overwrite is set. The path of the manifest is
+// returned.
+func SplitFile(path string, chunkSize int64, overwrite bool) (string, error) {
+	if chunkSize <= 0 {
+		return "", fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	m := SplitManifest{
+		File:      filepath.Base(path),
+		Size:      fi.Size(),
+		ChunkSize: chunkSize,
+	}
+	var written []string
+	cleanup := func() {
+		for _, p := range written {
+			os.Remove(p)
+		}
+	}
+
+	fileDigester := digest.SHA256.Digester()
+	r := io.TeeReader(f, fileDigester.Hash())
+	for i := 1; i == 1 || int64(i-1)*chunkSize < fi.Size(); i++ {
+		name := fmt.Sprintf("%s.%03d", m.File, i)
+		chunkPath := filepath.Join(filepath.Dir(path), name)
+		c, err := os.OpenFile(chunkPath, flags, 0o644)
+		if err != nil {
+			cleanup()
+			return "", fmt.Errorf("while creating chunk: %v", err)
+		}
+		written = append(written, chunkPath)
+
+		chunkDigester := digest.SHA256.Digester()
+		n, err := io.CopyN(io.MultiWriter(c, chunkDigester.Hash()), r, chunkSize)
+		if cerr := c.Close(); err == nil || errors.Is(err, io.EOF) {
+			err = cerr
+		}
+		if err != nil {
+			cleanup()
+			return "", fmt.Errorf("while writing chunk %s: %v", name, err)
+		}
+		m.Chunks = append(m.Chunks, SplitChunk{Name: name, Size: n, Digest: chunkDigester.Digest()})
+	}
+	m.Digest = fileDigester.Digest()
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		cleanup()
+		return "", err
+	}
+	manifestPath := path + SplitManifestSuffix
+	mf, err := os.OpenFile(manifestPath, flags, 0o644)
+	if err != nil {
+		cleanup()
+		return "", fmt.Errorf("while creating manifest: %v", err)
+	}
+	_, err = mf.Write(append(b, '\n'))
+	if cerr := mf.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		cleanup()
+		os.Remove(manifestPath)
+		return "", fmt.Errorf("while writing manifest: %v", err)
+	}
+
+	f.Close()
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return manifestPath, nil
+}
+
+// ReadSplitManifest reads the manifest of a split file at path.
+func ReadSplitManifest(path string) (*SplitManifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m SplitManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("invalid split manifest %s: %v", path, err)
+	}
+	if m.File == "" || len(m.Chunks) == 0 {
+		return nil, fmt.Errorf("invalid split manifest %s: no file or chunks", path)
+	}
+	for _, c := range m.Chunks {
+		if c.Name != filepath.Base(c.Name) {
+			return nil, fmt.Errorf("invalid split manifest %s: chunk %q is not in its directory", path, c.Name)
+		}
+	}
+	return &m, nil
+}
+
+// JoinChunks reassembles the file split into the chunks described by the
+// manifest at manifestPath into dest. Each chunk, and the reassembled file,
+// is checked against the size and digest recorded in the manifest, and dest
+// is only written if they all match. An existing dest is only overwritten if
+// overwrite is set.
+func JoinChunks(manifestPath, dest string, overwrite bool) error {
+	m, err := ReadSplitManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); err == nil && !overwrite {
+		return fmt.Errorf("%s already exists", dest)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), filepath.Base(dest)+".join-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	fileDigester := digest.SHA256.Digester()
+	w := io.MultiWriter(tmp, fileDigester.Hash())
+	var size int64
+	for _, c := range m.Chunks {
+		n, err := appendChunk(w, filepath.Join(filepath.Dir(manifestPath), c.Name), c)
+		if err != nil {
+			return err
+		}
+		size += n
+	}
+
+	if size != m.Size {
+		return fmt.Errorf("reassembled %s is %d bytes, expected %d", m.File, size, m.Size)
+	}
+	if d := fileDigester.Digest(); d != m.Digest {
+		return fmt.Errorf("reassembled %s has digest %s, expected %s", m.File, d, m.Digest)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// appendChunk copies the chunk c at path to w, checking its size and digest.
+func appendChunk(w io.Writer, path string, c SplitChunk) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("while reading chunk: %v", err)
+	}
+	defer f.Close()
+
+	d := digest.SHA256.Digester()
+	n, err := io.Copy(io.MultiWriter(w, d.Hash()), f)
+	if err != nil {
+		return n, fmt.Errorf("while reading chunk %s: %v", c.Name, err)
+	}
+	if n != c.Size {
+		return n, fmt.Errorf("chunk %s is %d bytes, expected %d", c.Name, n, c.Size)
+	}
+	if got := d.Digest(); got != c.Digest {
+		return n, fmt.Errorf("chunk %s has digest %s, expected %s", c.Name, got, c.Digest)
+	}
+	return n, nil
+}
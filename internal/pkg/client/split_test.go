@@ -0,0 +1,126 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitJoin(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		chunkSize  int64
+		wantChunks int
+	}{
+		{name: "Empty", size: 0, chunkSize: 10, wantChunks: 1},
+		{name: "Smaller", size: 5, chunkSize: 10, wantChunks: 1},
+		{name: "Exact", size: 30, chunkSize: 10, wantChunks: 3},
+		{name: "Remainder", size: 35, chunkSize: 10, wantChunks: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "image.sif")
+			content := make([]byte, tt.size)
+			for i := range content {
+				content[i] = byte(i)
+			}
+			if err := os.WriteFile(path, content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			manifestPath, err := SplitFile(path, tt.chunkSize, false)
+			if err != nil {
+				t.Fatalf("unexpected split error: %v", err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("split file %s was not removed", path)
+			}
+			m, err := ReadSplitManifest(manifestPath)
+			if err != nil {
+				t.Fatalf("unexpected manifest error: %v", err)
+			}
+			if len(m.Chunks) != tt.wantChunks {
+				t.Errorf("got %d chunks, want %d", len(m.Chunks), tt.wantChunks)
+			}
+			if m.Chunks[0].Name != "image.sif.001" {
+				t.Errorf("got first chunk %q, want image.sif.001", m.Chunks[0].Name)
+			}
+
+			out := filepath.Join(dir, "joined.sif")
+			if err := JoinChunks(manifestPath, out, false); err != nil {
+				t.Fatalf("unexpected join error: %v", err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) {
+				t.Errorf("joined content does not match original")
+			}
+			if err := JoinChunks(manifestPath, out, false); err == nil {
+				t.Errorf("unexpected success overwriting %s", out)
+			}
+		})
+	}
+}
+
+func TestJoinChunksCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.sif")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("sif"), 10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath, err := SplitFile(path, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A chunk of the right size with the wrong content.
+	if err := os.WriteFile(filepath.Join(dir, "image.sif.002"), []byte("corrupt!"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "joined.sif")
+	if err := JoinChunks(manifestPath, out, false); err == nil {
+		t.Errorf("unexpected success joining a corrupt chunk")
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("%s was written from a corrupt chunk", out)
+	}
+
+	// A missing chunk.
+	if err := os.Remove(filepath.Join(dir, "image.sif.002")); err != nil {
+		t.Fatal(err)
+	}
+	if err := JoinChunks(manifestPath, out, false); err == nil {
+		t.Errorf("unexpected success joining a missing chunk")
+	}
+}
+
+func TestSplitFileExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.sif")
+	if err := os.WriteFile(path, []byte("image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".001", []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SplitFile(path, 4, false); err == nil {
+		t.Errorf("unexpected success overwriting an existing chunk")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("file was removed after a failed split: %v", err)
+	}
+	if _, err := SplitFile(path, 4, true); err != nil {
+		t.Errorf("unexpected error overwriting: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// RegistryTimeouts holds the timeouts applied to connections to registries.
+// A timeout bounds the time taken to connect to a registry, and the time
+// waiting for each read from the connection, but not the whole duration of a
+// request, so that large blobs can still be downloaded.
+type RegistryTimeouts struct {
+	// Default applies to registries without a timeout in Hosts. No timeout
+	// is applied if it is zero.
+	Default time.Duration
+	// Hosts holds the timeouts of registries, by host or host:port.
+	Hosts map[string]time.Duration
+}
+
+// ParseRegistryTimeouts parses HOST=DURATION specifications, e.g.
+// registry.example.com=2m, into RegistryTimeouts. A DURATION without a HOST
+// sets the default timeout.
+func ParseRegistryTimeouts(specs []string) (RegistryTimeouts, error) {
+	t := RegistryTimeouts{Hosts: make(map[string]time.Duration)}
+	for _, spec := range specs {
+		host, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			host, value = "", spec
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return t, fmt.Errorf("invalid timeout %q: %v", spec, err)
+		}
+		if d < 0 {
+			return t, fmt.Errorf("invalid timeout %q: must not be negative", spec)
+		}
+		if !ok {
+			t.Default = d
+			continue
+		}
+		if host == "" {
+			return t, fmt.Errorf("invalid timeout %q: missing host", spec)
+		}
+		t.Hosts[strings.ToLower(host)] = d
+	}
+	return t, nil
+}
+
+// For returns the timeout for host, which may include a port. A timeout set
+// for host:port takes precedence over one set for host alone.
+func (t RegistryTimeouts) For(host string) time.Duration {
+	host = strings.ToLower(host)
+	if d, ok := t.Hosts[host]; ok {
+		return d
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		if d, ok := t.Hosts[h]; ok {
+			return d
+		}
+	}
+	return t.Default
+}
+
+// HTTPClient returns an http.Client applying the timeout of each registry to
+// its connections, with the settings of Transport.
+func (t RegistryTimeouts) HTTPClient() *http.Client {
+	return &http.Client{Transport: traced(&timeoutTransport{
+		timeouts:   t,
+		transports: make(map[string]http.RoundTripper),
+	})}
+}
+
+// timeoutTransport dispatches requests to a transport per host, configured
+// with the timeout of the host.
+type timeoutTransport struct {
+	timeouts   RegistryTimeouts
+	mu         sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+func (tt *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return tt.transport(req.URL.Host).RoundTrip(req)
+}
+
+func (tt *timeoutTransport) transport(host string) http.RoundTripper {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	if tr, ok := tt.transports[host]; ok {
+		return tr
+	}
+
+	d := tt.timeouts.For(host)
+	if d <= 0 {
+		sylog.Verbosef("Using no connect and read timeout for %s", host)
+		tr := newTransport(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+		tt.transports[host] = tr
+		return tr
+	}
+
+	sylog.Verbosef("Using connect and read timeout of %v for %s", d, host)
+	tr := newTransport(&net.Dialer{Timeout: d, KeepAlive: 30 * time.Second})
+	dial := tr.DialContext
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &readTimeoutConn{Conn: conn, timeout: d}, nil
+	}
+	tr.TLSHandshakeTimeout = d
+	tt.transports[host] = tr
+	return tr
+}
+
+// readTimeoutConn fails a read that doesn't complete within timeout.
+type readTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (c *readTimeoutConn) Read(p []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(p)
+}
+
+type registryTimeoutsKey struct{}
+
+// WithRegistryTimeouts returns a copy of ctx carrying t, which is applied by
+// registry clients using ctx.
+func WithRegistryTimeouts(ctx context.Context, t RegistryTimeouts) context.Context {
+	return context.WithValue(ctx, registryTimeoutsKey{}, t)
+}
+
+// RegistryTimeoutsFromContext returns the RegistryTimeouts carried by ctx. No
+// timeout applies if there are none.
+func RegistryTimeoutsFromContext(ctx context.Context) RegistryTimeouts {
+	t, _ := ctx.Value(registryTimeoutsKey{}).(RegistryTimeouts)
+	return t
+}
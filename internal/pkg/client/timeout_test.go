@@ -0,0 +1,129 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseRegistryTimeouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		specs     []string
+		wantError bool
+		host      string
+		want      time.Duration
+	}{
+		{
+			name: "None",
+			host: "example.com",
+			want: 0,
+		},
+		{
+			name:  "Default",
+			specs: []string{"30s"},
+			host:  "example.com",
+			want:  30 * time.Second,
+		},
+		{
+			name:  "Host",
+			specs: []string{"30s", "slow.example.com=5m"},
+			host:  "slow.example.com",
+			want:  5 * time.Minute,
+		},
+		{
+			name:  "HostWithPort",
+			specs: []string{"30s", "slow.example.com=5m"},
+			host:  "slow.example.com:5000",
+			want:  5 * time.Minute,
+		},
+		{
+			name:  "HostPortPrecedence",
+			specs: []string{"slow.example.com=5m", "slow.example.com:5000=10m"},
+			host:  "slow.example.com:5000",
+			want:  10 * time.Minute,
+		},
+		{
+			name:  "HostCase",
+			specs: []string{"Slow.Example.com=5m"},
+			host:  "slow.example.COM",
+			want:  5 * time.Minute,
+		},
+		{
+			name:  "OtherHost",
+			specs: []string{"30s", "slow.example.com=5m"},
+			host:  "example.com",
+			want:  30 * time.Second,
+		},
+		{
+			name:      "BadDuration",
+			specs:     []string{"example.com=soon"},
+			wantError: true,
+		},
+		{
+			name:      "Negative",
+			specs:     []string{"example.com=-1s"},
+			wantError: true,
+		},
+		{
+			name:      "MissingHost",
+			specs:     []string{"=1s"},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeouts, err := ParseRegistryTimeouts(tt.specs)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("got error %v, want error %v", err, tt.wantError)
+			}
+			if tt.wantError {
+				return
+			}
+			if got := timeouts.For(tt.host); got != tt.want {
+				t.Errorf("got timeout %v for %s, want %v", got, tt.host, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryTimeoutsHTTPClient(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	slowURL, err := url.Parse(slow.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeouts, err := ParseRegistryTimeouts([]string{slowURL.Host + "=50ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithRegistryTimeouts(context.Background(), timeouts)
+	c := RegistryTimeoutsFromContext(ctx).HTTPClient()
+
+	if _, err := c.Get(slow.URL); err == nil {
+		t.Errorf("unexpected success reading from %s with a timeout", slow.URL)
+	}
+
+	res, err := c.Get(fast.URL)
+	if err != nil {
+		t.Fatalf("unexpected error reading from %s: %v", fast.URL, err)
+	}
+	res.Body.Close()
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	units "github.com/docker/go-units"
+	"github.com/sylabs/singularity/pkg/sylog"
+	"golang.org/x/sys/unix"
+)
+
+// SharedMemoryDir is the tmpfs mounted on all Linux hosts, where an
+// unprivileged user can create a tmpfs-backed work directory.
+const SharedMemoryDir = "/dev/shm"
+
+// meminfoPath is the file MemAvailable reads the memory of the host from.
+var meminfoPath = "/proc/meminfo"
+
+// MemAvailable returns the memory available on the host, in bytes, without
+// swapping, as estimated by the kernel.
+func MemAvailable() (int64, error) {
+	f, err := os.Open(meminfoPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "MemAvailable:" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid MemAvailable in %s: %v", meminfoPath, err)
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no MemAvailable in %s", meminfoPath)
+}
+
+// TmpfsWorkDir creates a tmpfs-backed work directory for a work load of an
+// estimated size, in bytes, limited to limit bytes, or to half of the
+// available memory if limit is 0. An error is returned if the estimated size
+// is over the limit or the available memory. As root, a tmpfs of size limit is
+// mounted in a new directory of parent, or the default directory for
+// temporary files. Otherwise, the directory is created in SharedMemoryDir and
+// its size can't be enforced. The returned cleanup function removes the
+// directory.
+func TmpfsWorkDir(parent string, estimate, limit int64) (dir string, cleanup func(), err error) {
+	avail, err := MemAvailable()
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read available memory: %v", err)
+	}
+	if limit <= 0 {
+		limit = avail / 2
+	}
+	if estimate > limit {
+		return "", nil, fmt.Errorf("estimated size %s is over the tmpfs limit of %s",
+			units.BytesSize(float64(estimate)), units.BytesSize(float64(limit)))
+	}
+	if estimate > avail {
+		return "", nil, fmt.Errorf("estimated size %s is over the available memory of %s",
+			units.BytesSize(float64(estimate)), units.BytesSize(float64(avail)))
+	}
+
+	if os.Geteuid() == 0 {
+		return mountTmpfs(parent, limit)
+	}
+
+	var st unix.Statfs_t
+	if err := unix.Statfs(SharedMemoryDir, &st); err != nil {
+		return "", nil, fmt.Errorf("could not stat %s: %v", SharedMemoryDir, err)
+	}
+	if st.Type != unix.TMPFS_MAGIC {
+		return "", nil, fmt.Errorf("%s is not a tmpfs", SharedMemoryDir)
+	}
+	if free := int64(st.Bavail) * st.Bsize; estimate > free {
+		return "", nil, fmt.Errorf("estimated size %s is over the free space of %s in %s",
+			units.BytesSize(float64(estimate)), units.BytesSize(float64(free)), SharedMemoryDir)
+	}
+	dir, err = os.MkdirTemp(SharedMemoryDir, "singularity-work-")
+	if err != nil {
+		return "", nil, err
+	}
+	return dir, func() {
+		if err := os.RemoveAll(dir); err != nil {
+			sylog.Warningf("Could not remove tmpfs work directory %s: %v", dir, err)
+		}
+	}, nil
+}
+
+// mountTmpfs mounts a tmpfs of size bytes in a new directory of parent.
+func mountTmpfs(parent string, size int64) (dir string, cleanup func(), err error) {
+	dir, err = os.MkdirTemp(parent, "singularity-work-")
+	if err != nil {
+		return "", nil, err
+	}
+	opts := fmt.Sprintf("size=%d,mode=0700", size)
+	if err := unix.Mount("tmpfs", dir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, opts); err != nil {
+		os.Remove(dir)
+		return "", nil, fmt.Errorf("could not mount tmpfs on %s: %v", dir, err)
+	}
+	return dir, func() {
+		if err := unix.Unmount(dir, unix.MNT_DETACH); err != nil {
+			sylog.Warningf("Could not unmount tmpfs work directory %s: %v", dir, err)
+			return
+		}
+		if err := os.Remove(dir); err != nil {
+			sylog.Warningf("Could not remove tmpfs work directory %s: %v", dir, err)
+		}
+	}, nil
+}
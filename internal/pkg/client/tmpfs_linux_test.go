@@ -0,0 +1,140 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemAvailable(t *testing.T) {
+	defer func(p string) { meminfoPath = p }(meminfoPath)
+
+	meminfo := filepath.Join(t.TempDir(), "meminfo")
+	meminfoPath = meminfo
+
+	os.WriteFile(meminfo, []byte("MemTotal:       16000000 kB\nMemFree:         1000000 kB\nMemAvailable:    8000000 kB\n"), 0o644)
+	if got, err := MemAvailable(); err != nil || got != 8000000*1024 {
+		t.Errorf("got %d %v, want %d", got, err, 8000000*1024)
+	}
+
+	os.WriteFile(meminfo, []byte("MemTotal:       16000000 kB\n"), 0o644)
+	if _, err := MemAvailable(); err == nil {
+		t.Errorf("unexpected success without MemAvailable")
+	}
+}
+
+func TestTmpfsWorkDir(t *testing.T) {
+	if _, err := MemAvailable(); err != nil {
+		t.Skipf("could not read available memory: %v", err)
+	}
+
+	if _, _, err := TmpfsWorkDir(t.TempDir(), 2<<20, 1<<20); err == nil {
+		t.Errorf("unexpected success with estimate over limit")
+	}
+	if _, _, err := TmpfsWorkDir(t.TempDir(), 1<<62, 0); err == nil {
+		t.Errorf("unexpected success with estimate over available memory")
+	}
+
+	dir, cleanup, err := TmpfsWorkDir(t.TempDir(), 1<<20, 64<<20)
+	if err != nil {
+		t.Skipf("could not create tmpfs work directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("data"), 0o644); err != nil {
+		t.Errorf("could not write to %s: %v", dir, err)
+	}
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("%s not removed: %v", dir, err)
+	}
+}
+
+// layerTar returns a tar archive of n files of size bytes, as an image layer.
+func layerTar(b *testing.B, n, size int) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	data := make([]byte, size)
+	for i := 0; i < n; i++ {
+		hdr := &tar.Header{Name: fmt.Sprintf("usr/lib/%d/file", i), Mode: 0o644, Size: int64(size)}
+		if err := tw.WriteHeader(hdr); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// extractTar extracts the tar archive layer into dir, syncing each file as
+// the extraction of a root file system to disk does before it is squashed.
+func extractTar(b *testing.B, layer []byte, dir string) {
+	tr := tar.NewReader(bytes.NewReader(layer))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+		path := filepath.Join(dir, hdr.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			b.Fatal(err)
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			b.Fatal(err)
+		}
+		if err := f.Sync(); err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+// BenchmarkConversionWorkDir measures the extraction of a layer of 500 files
+// of 64KiB, as in the conversion of an image, in a work directory on disk and
+// in a tmpfs-backed work directory.
+func BenchmarkConversionWorkDir(b *testing.B) {
+	layer := layerTar(b, 500, 64<<10)
+
+	bench := func(b *testing.B, parent string) {
+		b.SetBytes(int64(len(layer)))
+		for i := 0; i < b.N; i++ {
+			dir, err := os.MkdirTemp(parent, "bench-")
+			if err != nil {
+				b.Fatal(err)
+			}
+			extractTar(b, layer, dir)
+			os.RemoveAll(dir)
+		}
+	}
+
+	b.Run("Disk", func(b *testing.B) {
+		bench(b, b.TempDir())
+	})
+
+	b.Run("Tmpfs", func(b *testing.B) {
+		dir, cleanup, err := TmpfsWorkDir(b.TempDir(), int64(len(layer)), 0)
+		if err != nil {
+			b.Skipf("could not create tmpfs work directory: %v", err)
+		}
+		defer cleanup()
+		bench(b, dir)
+	})
+}
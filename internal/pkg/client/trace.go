@@ -0,0 +1,140 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sylabs/singularity/pkg/sylog"
+)
+
+// Redacted replaces the value of a header holding credentials in traces.
+const Redacted = "REDACTED"
+
+// sensitiveHeaders are the canonical names of the headers whose values are
+// always redacted in traces.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Registry-Auth":     true,
+}
+
+// tracef writes a line of the trace, at the TRACE message level of sylog.
+var tracef = sylog.Tracef
+
+// traceTransport writes the headers of each request made through base, and
+// of its response, to the trace, with credentials redacted.
+type traceTransport struct {
+	base http.RoundTripper
+	// n numbers the requests, to match their responses in the trace.
+	n int64
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := atomic.AddInt64(&t.n, 1)
+	tracef("[%d] > %s %s %s", n, req.Method, redactURL(req.URL), req.Proto)
+	traceHeader(n, ">", "Host", req.Host)
+	for _, l := range redactHeader(req.Header) {
+		tracef("[%d] > %s", n, l)
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		tracef("[%d] < error: %v", n, err)
+		return nil, err
+	}
+	tracef("[%d] < %s %s", n, res.Proto, res.Status)
+	for _, l := range redactHeader(res.Header) {
+		tracef("[%d] < %s", n, l)
+	}
+	return res, nil
+}
+
+// traceHeader logs the header name of the request or response n, in the
+// direction dir, if value is set.
+func traceHeader(n int64, dir, name, value string) {
+	if value != "" {
+		tracef("[%d] %s %s: %s", n, dir, name, value)
+	}
+}
+
+// redactHeader returns the lines "Name: value" of h, sorted by name, with the
+// values of headers that may hold credentials redacted. The scheme of an
+// authorization is kept, as it tells which authentication was attempted. The
+// query of a Location, which may hold a signed token, is redacted.
+func redactHeader(h http.Header) []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, v := range h[name] {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, redactValue(name, v)))
+		}
+	}
+	return lines
+}
+
+// redactValue returns the value v of the header name, redacted if it may hold
+// credentials.
+func redactValue(name, v string) string {
+	canonical := http.CanonicalHeaderKey(name)
+	if canonical == "Authorization" || canonical == "Proxy-Authorization" {
+		if scheme, _, ok := strings.Cut(v, " "); ok {
+			return scheme + " " + Redacted
+		}
+		return Redacted
+	}
+	if sensitiveHeaders[canonical] {
+		return Redacted
+	}
+	lower := strings.ToLower(canonical)
+	for _, s := range []string{"token", "secret", "password", "api-key", "apikey"} {
+		if strings.Contains(lower, s) {
+			return Redacted
+		}
+	}
+	if canonical == "Location" {
+		if u, err := url.Parse(v); err == nil {
+			return redactURL(u)
+		}
+		return Redacted
+	}
+	return v
+}
+
+// traceEnabled is set by UseTrace.
+var traceEnabled bool
+
+// UseTrace writes the headers of the requests made through the HTTP
+// transports built by singularity, see Transport, and of their responses, to
+// the trace, with credentials redacted. Docker/OCI registries are reached by
+// containers/image through transports of its own, which are not traced. It
+// must be called before any transport is built.
+func UseTrace() {
+	traceEnabled = true
+}
+
+// tracing reports whether UseTrace was called.
+func tracing() bool {
+	return traceEnabled
+}
+
+// defaultTransport returns the default HTTP transport, which the transports
+// built by singularity are cloned from, and which is never modified.
+func defaultTransport() *http.Transport {
+	return http.DefaultTransport.(*http.Transport)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	sharedTransportOnce sync.Once
+	sharedTransport     http.RoundTripper
+)
+
+// Transport returns the HTTP transport shared by the clients built by
+// singularity to pull images from library, http(s), oras and shub sources,
+// with the settings applied by UseSOCKS5 and UseDNSCache. Docker/OCI
+// registries are reached
+// by containers/image through transports of its own. The settings must be
+// applied before Transport is first called.
+func Transport() http.RoundTripper {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = traced(newTransport(&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}))
+	})
+	return sharedTransport
+}
+
+// newTransport returns a new HTTP transport configured as the default one,
+// dialing with d, with the settings applied by UseSOCKS5 and UseDNSCache.
+func newTransport(d *net.Dialer) *http.Transport {
+	tr := defaultTransport().Clone()
+	tr.DialContext = dialContext(d)
+	if socks5Proxy != nil {
+		tr.Proxy = http.ProxyURL(socks5Proxy)
+	}
+	if tlsSessions != nil {
+		tr.MaxIdleConnsPerHost = 16
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		}
+		tr.TLSClientConfig.ClientSessionCache = tlsSessions
+	}
+	return tr
+}
+
+// traced returns tr, wrapped to trace its requests if UseTrace was called.
+func traced(tr http.RoundTripper) http.RoundTripper {
+	if tracing() {
+		return &traceTransport{base: tr}
+	}
+	return tr
+}
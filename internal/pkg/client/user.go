@@ -0,0 +1,62 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sylabs/singularity/pkg/image"
+)
+
+// ImageUser returns the default user of the SIF image at path, from the OCI
+// image config it holds. It returns false if the SIF holds no OCI image
+// config, as for images that were not converted from docker/OCI images.
+func ImageUser(path string) (user string, ok bool, err error) {
+	conf, err := imageConfig(path)
+	if conf == nil || err != nil {
+		return "", false, err
+	}
+	return conf.User, true, nil
+}
+
+// imageConfig returns the OCI image config held by the SIF image at path, or
+// nil if it holds none.
+func imageConfig(path string) (*imgspecv1.ImageConfig, error) {
+	img, err := image.Init(path, false)
+	if err != nil {
+		return nil, fmt.Errorf("could not open image %s: %v", path, err)
+	}
+	defer img.File.Close()
+
+	r, err := image.NewSectionReader(img, image.SIFDescOCIConfigJSON, -1)
+	if err == image.ErrNoSection {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read OCI config of %s: %v", path, err)
+	}
+
+	var conf imgspecv1.ImageConfig
+	if err := json.NewDecoder(r).Decode(&conf); err != nil {
+		return nil, fmt.Errorf("could not decode OCI config of %s: %v", path, err)
+	}
+	return &conf, nil
+}
+
+// IsRootUser reports whether user, as the User of an OCI image config, runs
+// the container as root. The user, before any :group, is root if it is unset,
+// root or 0.
+func IsRootUser(user string) bool {
+	u, _, _ := strings.Cut(user, ":")
+	switch strings.TrimSpace(u) {
+	case "", "root", "0":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package client
+
+import "testing"
+
+func TestIsRootUser(t *testing.T) {
+	tests := []struct {
+		user string
+		want bool
+	}{
+		{user: "", want: true},
+		{user: "root", want: true},
+		{user: "0", want: true},
+		{user: "0:0", want: true},
+		{user: "root:wheel", want: true},
+		{user: "1000", want: false},
+		{user: "1000:0", want: false},
+		{user: "nobody", want: false},
+		{user: "app:app", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRootUser(tt.user); got != tt.want {
+			t.Errorf("IsRootUser(%q) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+}
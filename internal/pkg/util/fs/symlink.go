@@ -0,0 +1,201 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// maxSymlinks is the maximum number of symlinks followed while resolving a
+// path, as in the Linux kernel.
+const maxSymlinks = 40
+
+// ConfinedSymlink describes a symlink changed by ConfineSymlinks.
+type ConfinedSymlink struct {
+	// Path is the path of the symlink, relative to the root filesystem.
+	Path string
+	// Target is the original target of the symlink.
+	Target string
+	// NewTarget is the target the symlink was rewritten to.
+	NewTarget string
+	// Clamped is true if the symlink climbed above rootfs with "..", and
+	// was rewritten to the path it resolves to in a container, where ".."
+	// stops at the root.
+	Clamped bool
+}
+
+// ConfineSymlinks works through the root filesystem rootfs, so that its
+// symlinks resolve within rootfs when followed from the host, as they do in
+// a container. Absolute symlinks are rewritten to the equivalent relative
+// symlinks, and symlinks climbing above rootfs are rewritten to the path
+// they resolve to in a container, relative to rootfs. The rewritten symlinks
+// are returned.
+func ConfineSymlinks(rootfs string) (rewritten []ConfinedSymlink, err error) {
+	err = PermWalkRaiseError(rootfs, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		rel, err := filepath.Rel(rootfs, path)
+		if err != nil {
+			return err
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		link := ConfinedSymlink{Path: rel, Target: target, NewTarget: target}
+
+		dir := splitPath(filepath.Dir(rel))
+		if filepath.IsAbs(target) {
+			link.NewTarget = relativeTarget(len(dir), target)
+		}
+
+		resolved, escapes, err := resolveInRoot(rootfs, dir, link.NewTarget)
+		if err != nil {
+			return fmt.Errorf("while resolving symlink %s: %v", rel, err)
+		}
+		if escapes {
+			link.NewTarget = relativeTarget(len(dir), "/"+strings.Join(resolved, "/"))
+			link.Clamped = true
+		}
+
+		if link.NewTarget != target {
+			if err := replaceSymlink(path, link.NewTarget, f); err != nil {
+				return err
+			}
+			rewritten = append(rewritten, link)
+		}
+		return nil
+	})
+	return rewritten, err
+}
+
+// relativeTarget returns the relative equivalent of the absolute symlink
+// target, for a symlink in a directory depth levels below the root.
+func relativeTarget(depth int, target string) string {
+	rel := strings.Repeat("../", depth) + strings.TrimLeft(target, "/")
+	rel = strings.TrimSuffix(rel, "/")
+	if rel == "" {
+		return "."
+	}
+	return rel
+}
+
+// resolveInRoot resolves the symlink target, relative to the directory dir
+// of rootfs, as in a container: symlinks met are followed, an absolute one
+// resolving from rootfs, and ".." stops at the root of rootfs. It returns
+// the components of the resolved path, and whether target climbed above the
+// root, which it would escape when followed from the host. A symlink loop
+// doesn't resolve at all, target is then only resolved lexically.
+func resolveInRoot(rootfs string, dir []string, target string) (resolved []string, escapes bool, err error) {
+	resolved = append([]string{}, dir...)
+	pending := splitPath(target)
+	followed := 0
+
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		switch name {
+		case ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				escapes = true
+				continue
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+
+		path := filepath.Join(append([]string{rootfs}, append(resolved, name)...)...)
+		fi, err := os.Lstat(path)
+		if err != nil || fi.Mode()&os.ModeSymlink == 0 {
+			// A missing component, and those after it, can't be
+			// symlinks, but may still climb out with "..".
+			resolved = append(resolved, name)
+			continue
+		}
+
+		followed++
+		if followed > maxSymlinks {
+			return clampPath(dir, target), escapes, nil
+		}
+		link, err := os.Readlink(path)
+		if err != nil {
+			return nil, false, err
+		}
+		if filepath.IsAbs(link) {
+			resolved = resolved[:0]
+		}
+		pending = append(splitPath(link), pending...)
+	}
+	return resolved, escapes, nil
+}
+
+// clampPath resolves target, relative to the directory dir, without following
+// symlinks, ".." stopping at the root.
+func clampPath(dir []string, target string) []string {
+	resolved := append([]string{}, dir...)
+	if filepath.IsAbs(target) {
+		resolved = resolved[:0]
+	}
+	for _, name := range splitPath(target) {
+		switch name {
+		case ".":
+		case "..":
+			if len(resolved) > 0 {
+				resolved = resolved[:len(resolved)-1]
+			}
+		default:
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved
+}
+
+// replaceSymlink atomically replaces the symlink at path, with file
+// information f, by a symlink to target with the same ownership.
+func replaceSymlink(path, target string, f os.FileInfo) error {
+	tmp := path + ".singularity-symlink"
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if st, ok := f.Sys().(*syscall.Stat_t); ok && os.Geteuid() == 0 {
+		if err := os.Lchown(tmp, int(st.Uid), int(st.Gid)); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// splitPath returns the non-empty components of path, without a leading
+// "." for the current directory.
+func splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 1 && names[0] == "." {
+		return nil
+	}
+	return names
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfineSymlinks(t *testing.T) {
+	rootfs := t.TempDir()
+
+	for _, dir := range []string{"etc", "usr/bin", "usr/lib"} {
+		if err := os.MkdirAll(filepath.Join(rootfs, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links := map[string]string{
+		// Left untouched.
+		"usr/bin/sh":   "bash",
+		"usr/lib/libc": "../lib/libc.so.6",
+		"usr/bin/self": ".",
+		"loop":         "loop",
+		// Absolute, rewritten relative.
+		"bin":          "/usr/bin",
+		"usr/bin/vi":   "/usr/bin/vim",
+		"usr/lib/root": "/",
+		"etc/mtab":     "/proc/self/mounts",
+		// Climbing above the root, clamped at the root. etc/shadow resolves
+		// to itself in a container.
+		"etc/shadow":      "../../../../etc/shadow",
+		"up":              "..",
+		"abs-up":          "/../../etc/passwd",
+		"usr/bin/through": "self/../../../..",
+		"usr/lib/chain":   "root/../etc",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(rootfs, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rewritten, err := ConfineSymlinks(rootfs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantRewritten := map[string]string{
+		"bin":             "usr/bin",
+		"usr/bin/vi":      "../../usr/bin/vim",
+		"usr/lib/root":    "../..",
+		"etc/mtab":        "../proc/self/mounts",
+		"etc/shadow":      "../etc/shadow",
+		"up":              ".",
+		"abs-up":          "etc/passwd",
+		"usr/bin/through": "../..",
+		"usr/lib/chain":   "../../etc",
+	}
+	wantClamped := map[string]bool{
+		"etc/shadow":      true,
+		"up":              true,
+		"abs-up":          true,
+		"usr/bin/through": true,
+		"usr/lib/chain":   true,
+	}
+	if len(rewritten) != len(wantRewritten) {
+		t.Errorf("got %d rewritten symlinks, want %d: %+v", len(rewritten), len(wantRewritten), rewritten)
+	}
+	for _, l := range rewritten {
+		if want := wantRewritten[l.Path]; l.NewTarget != want {
+			t.Errorf("symlink %s rewritten to %q, want %q", l.Path, l.NewTarget, want)
+		}
+		if l.Target != links[l.Path] {
+			t.Errorf("symlink %s has original target %q, want %q", l.Path, l.Target, links[l.Path])
+		}
+		if l.Clamped != wantClamped[l.Path] {
+			t.Errorf("symlink %s clamped %v, want %v", l.Path, l.Clamped, wantClamped[l.Path])
+		}
+	}
+
+	for link, target := range links {
+		path := filepath.Join(rootfs, link)
+		got, err := os.Readlink(path)
+		if err != nil {
+			t.Errorf("while reading symlink %s: %v", link, err)
+			continue
+		}
+		switch {
+		case wantRewritten[link] != "":
+			if got != wantRewritten[link] {
+				t.Errorf("symlink %s points to %q, want %q", link, got, wantRewritten[link])
+			}
+		default:
+			if got != target {
+				t.Errorf("symlink %s points to %q, want %q", link, got, target)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package uri
+
+import (
+	"fmt"
+	"strings"
+)
+
+// templateFields lists the placeholders that may be used in a name template.
+var templateFields = []string{"name", "tag", "arch", "transport"}
+
+// ValidateNameTemplate checks that tmpl only contains well formed, known
+// placeholders, e.g. "{name}_{tag}_{arch}.sif".
+func ValidateNameTemplate(tmpl string) error {
+	if strings.TrimSpace(tmpl) == "" {
+		return fmt.Errorf("name template is empty")
+	}
+
+	rest := tmpl
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			return nil
+		}
+		if rest[open] == '}' {
+			return fmt.Errorf("unexpected '}' in name template %q", tmpl)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return fmt.Errorf("unterminated placeholder in name template %q", tmpl)
+		}
+		field := rest[open+1 : open+1+end]
+		if !isTemplateField(field) {
+			return fmt.Errorf("unknown placeholder {%s} in name template %q (valid: %s)", field, tmpl, strings.Join(templateFields, ", "))
+		}
+		rest = rest[open+1+end+1:]
+	}
+}
+
+// ExpandNameTemplate computes an image file name from tmpl, substituting
+// placeholders with values derived from the transport:ref URI and arch.
+// The tag defaults to "latest" for non-http(s) URIs without an explicit tag.
+func ExpandNameTemplate(tmpl, uri, arch string) (string, error) {
+	if err := ValidateNameTemplate(tmpl); err != nil {
+		return "", err
+	}
+
+	transport, ref := Split(uri)
+	if transport == "" {
+		return "", fmt.Errorf("%s not in transport:ref format", uri)
+	}
+
+	ref = strings.TrimLeft(ref, "/")
+	refSplit := strings.Split(ref, "/")
+	name := refSplit[len(refSplit)-1]
+	tag := ""
+
+	if transport != HTTP && transport != HTTPS {
+		tag = "latest"
+		if i := strings.Index(name, "@"); i >= 0 {
+			tag = strings.ReplaceAll(name[i+1:], ":", "_")
+			name = name[:i]
+		} else if i := strings.Index(name, ":"); i >= 0 {
+			tag = strings.Split(name[i+1:], ",")[0]
+			name = name[:i]
+		}
+	}
+
+	r := strings.NewReplacer(
+		"{name}", name,
+		"{tag}", tag,
+		"{arch}", arch,
+		"{transport}", transport,
+	)
+	return r.Replace(tmpl), nil
+}
+
+func isTemplateField(field string) bool {
+	for _, f := range templateFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026, Sylabs Inc. All rights reserved.
+// This software is licensed under a 3-clause BSD license. Please consult the
+// LICENSE.md file distributed with the sources of this project regarding your
+// rights to use or distribute this software.
+
+package uri
+
+import (
+	"testing"
+)
+
+func TestValidateNameTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr bool
+	}{
+		{"plain", "image.sif", false},
+		{"all fields", "{transport}/{name}_{tag}_{arch}.sif", false},
+		{"empty", "", true},
+		{"unknown field", "{name}_{version}.sif", true},
+		{"unterminated", "{name.sif", true},
+		{"stray close", "name}.sif", true},
+		{"nested", "{na{me}}.sif", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNameTemplate(tt.tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error state for %q: %v", tt.tmpl, err)
+			}
+		})
+	}
+}
+
+func TestExpandNameTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		tmpl     string
+		uri      string
+		arch     string
+		expected string
+		wantErr  bool
+	}{
+		{"docker default tag", "{name}_{tag}_{arch}.sif", "docker://alpine", "amd64", "alpine_latest_amd64.sif", false},
+		{"docker tag", "{arch}/{name}-{tag}.sif", "docker://sylabs.io/lolcow:3.7", "arm64", "arm64/lolcow-3.7.sif", false},
+		{"docker digest", "{name}_{tag}.sif", "docker://alpine@sha256:abc", "amd64", "alpine_sha256_abc.sif", false},
+		{"library", "{transport}_{name}_{tag}.sif", "library://user/collection/image:v1", "amd64", "library_image_v1.sif", false},
+		{"https", "{name}", "https://example.com/images/image.sif", "amd64", "image.sif", false},
+		{"no transport", "{name}", "alpine", "amd64", "", true},
+		{"bad template", "{nam}", "docker://alpine", "amd64", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := ExpandNameTemplate(tt.tmpl, tt.uri, tt.arch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if n != tt.expected {
+				t.Errorf("expanded name as %q (expected %q)", n, tt.expected)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	ocitypes "github.com/containers/image/v5/types"
 	scskeyclient "github.com/sylabs/scs-key-client/client"
@@ -30,6 +31,7 @@ type Bundle struct {
 
 	RootfsPath string `json:"rootfsPath"` // where actual fs to chroot will appear
 	TmpDir     string `json:"tmpPath"`    // where temp files required during build will appear
+	Platform   string `json:"platform"`   // architecture of the image the root filesystem comes from, if known
 
 	parentPath string // parent directory for RootfsPath
 }
@@ -76,6 +78,55 @@ type Options struct {
 	// To warn when the above is needed, we need to know if the target of this
 	// bundle will be a sandbox
 	SandboxTarget bool
+	// ImportAnnotations lists the OCI manifest and config annotation keys to
+	// import as labels of the container. A key ending in '*' matches as a prefix, and
+	// "all" imports every annotation.
+	ImportAnnotations []string
+	// NoXattrs removes all extended attributes from the files extracted
+	// from OCI layers.
+	NoXattrs bool
+	// NormalizePerms sets the permissions of the files extracted from OCI
+	// layers to 0755 for directories and executables, and 0644 otherwise.
+	NormalizePerms bool
+	// BuildTime, if set, is used as the build date label and the creation
+	// time of the SIF, instead of the current time, so that builds can be
+	// reproduced.
+	BuildTime time.Time
+	// Architecture and Variant select the platform of the OCI image to use
+	// from a multi-platform image, instead of the host platform, if set.
+	Architecture string
+	Variant      string
+	// RawSymlinks keeps the symlinks of a sandbox as they are, instead of
+	// rewriting those that resolve outside of the sandbox when followed from
+	// the host.
+	RawSymlinks bool
+	// ConfineSymlinks rewrites the symlinks of the root filesystem of a SIF,
+	// as those of a sandbox, before the root filesystem is examined from the
+	// host and the SIF is created.
+	ConfineSymlinks bool
+	// DetectOS records the OS distribution of an OCI image, read from its
+	// os-release file, as labels of the container.
+	DetectOS bool
+	// RecordCreated records the created field of the config of an OCI image
+	// as the org.opencontainers.image.created label of the container, unless
+	// the image already has that label.
+	RecordCreated bool
+	// ExcludePaths lists patterns, as for path.Match, of the absolute paths
+	// of the files and directories removed from the root filesystem extracted
+	// from OCI layers.
+	ExcludePaths []string
+	// Dedup replaces files of the root filesystem with the same content and
+	// metadata as another file by hardlinks to it, before the SIF is created.
+	Dedup bool
+	// ExportRootfs, if set, is the path the root filesystem is written to as
+	// a tar archive, gzip compressed if ExportRootfsGzip is set, when the SIF
+	// is created.
+	ExportRootfs     string
+	ExportRootfsGzip bool
+	// SetArch, if set, is the architecture recorded in the SIF, instead of
+	// the architecture of the executables of the root filesystem, or of the
+	// platform of the image. It must match the executables, if recognized.
+	SetArch string
 }
 
 // NewEncryptedBundle creates an Encrypted Bundle environment.
@@ -141,6 +141,23 @@ func NewDefinitionFromURI(uri string) (d Definition, err error) {
 	return d, nil
 }
 
+// NewDefinitionFromURIWithPost creates a new Definition from the supplied
+// URI, like NewDefinitionFromURI, with post as its %post section.
+func NewDefinitionFromURIWithPost(uri, post string) (d Definition, err error) {
+	d, err = NewDefinitionFromURI(uri)
+	if err != nil {
+		return d, err
+	}
+
+	d.BuildData.Post.Script = post
+
+	var buf bytes.Buffer
+	populateRaw(&d, &buf)
+	d.Raw = buf.Bytes()
+
+	return d, nil
+}
+
 // NewDefinitionFromJSON creates a new Definition using the supplied JSON.
 func NewDefinitionFromJSON(r io.Reader) (d Definition, err error) {
 	decoder := json.NewDecoder(r)
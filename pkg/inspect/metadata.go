@@ -27,6 +27,7 @@ type Attributes struct {
 	Helpfile    string                    `json:"helpfile,omitempty"`
 	Deffile     string                    `json:"deffile,omitempty"`
 	Startscript string                    `json:"startscript,omitempty"`
+	Arch        string                    `json:"arch,omitempty"`
 }
 
 // Data holds the container metadata attributes.
@@ -255,7 +255,7 @@ func (b *Bundle) fetchLayout(ctx context.Context) (layoutRef types.ImageReferenc
 		return nil, "", nil, err
 	}
 
-	_, err = copy.Image(ctx, policyCtx, tmpfsRef, srcRef, &copy.Options{
+	_, err = oci.CopyImage(ctx, policyCtx, tmpfsRef, srcRef, &copy.Options{
 		ReportWriter: sylog.Writer(),
 		SourceCtx:    b.sysCtx,
 	})
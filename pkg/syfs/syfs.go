@@ -22,6 +22,7 @@ const (
 	RemoteConfFile = "remote.yaml"
 	RemoteCache    = "remote-cache"
 	DockerConfFile = "docker-config.json"
+	AliasesFile    = "aliases.json"
 	singularityDir = ".singularity"
 )
 
@@ -71,6 +72,12 @@ func DockerConf() string {
 	return filepath.Join(ConfigDir(), DockerConfFile)
 }
 
+// Aliases returns the path of the store of the image aliases recorded with
+// pull --alias.
+func Aliases() string {
+	return filepath.Join(ConfigDir(), AliasesFile)
+}
+
 // ConfigDirForUsername returns the directory where the singularity
 // configuration and data for the specified username is located.
 func ConfigDirForUsername(username string) (string, error) {
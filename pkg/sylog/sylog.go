@@ -126,6 +126,13 @@ func Debugf(format string, a ...interface{}) {
 	writef(DebugLevel, format, a...)
 }
 
+// Tracef writes a TRACE level message to the log. TRACE level messages, such
+// as the headers of HTTP requests, are more detailed than DEBUG level ones,
+// and are only output when the TRACE level is set explicitly.
+func Tracef(format string, a ...interface{}) {
+	writef(TraceLevel, format, a...)
+}
+
 // SetLevel explicitly sets the loggerLevel
 func SetLevel(l int, color bool) {
 	loggerLevel = messageLevel(l)
@@ -19,6 +19,7 @@ const (
 	Verbose2Level                         // Verbose2Level : 3
 	Verbose3Level                         // Verbose3Level : 4
 	DebugLevel                            // DebugLevel    : 5
+	TraceLevel                            // TraceLevel    : 6
 )
 
 func (l messageLevel) String() string {
@@ -41,4 +42,5 @@ var messageLabels = map[messageLevel]string{
 	Verbose2Level: "VERBOSE",
 	Verbose3Level: "VERBOSE",
 	DebugLevel:    "DEBUG",
+	TraceLevel:    "TRACE",
 }
@@ -33,6 +33,9 @@ func Verbosef(format string, a ...interface{}) {}
 // Debugf is a dummy function doing nothing
 func Debugf(format string, a ...interface{}) {}
 
+// Tracef is a dummy function doing nothing.
+func Tracef(format string, a ...interface{}) {}
+
 // SetLevel is a dummy function doing nothing.
 func SetLevel(l int, color bool) {}
 
@@ -1048,6 +1048,22 @@ func (keyring *Handle) importPublicKey(entity *openpgp.Entity) error {
 	return keyring.appendPubKey(entity)
 }
 
+// CachePubKey adds the public key of entity e to the public keyring, unless a
+// key with the same fingerprint is already there, so that it can be used
+// offline later. It returns true if the key was added.
+func (keyring *Handle) CachePubKey(e *openpgp.Entity) (bool, error) {
+	if err := keyring.PathsCheck(); err != nil {
+		return false, err
+	}
+
+	err := keyring.importPublicKey(e)
+	var kee *KeyExistsError
+	if errors.As(err, &kee) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // ImportKey imports one or more keys from the specified file. The keys
 // can be either a public or private keys, and the file can be either in
 // binary or ascii-armored format.
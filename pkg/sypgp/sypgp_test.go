@@ -805,6 +805,30 @@ func TestGlobalKeyRing(t *testing.T) {
 	}
 }
 
+func TestCachePubKey(t *testing.T) {
+	keyring := NewHandle(filepath.Join(t.TempDir(), "keys"))
+	e := testEntity
+
+	// The key is only added once, however many times it is cached.
+	for i, want := range []bool{true, false, false} {
+		added, err := keyring.CachePubKey(e)
+		if err != nil {
+			t.Fatalf("unexpected error while caching public key (%d): %s", i, err)
+		}
+		if added != want {
+			t.Errorf("got added %v while caching public key (%d), want %v", added, i, want)
+		}
+	}
+
+	el, err := keyring.LoadPubKeyring()
+	if err != nil {
+		t.Fatalf("unexpected error while loading public keyring: %s", err)
+	}
+	if len(el) != 1 {
+		t.Errorf("unexpected number of PGP keys: got %d instead of 1", len(el))
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Set TZ to UTC so that the code converting a time.Time value
 	// to a string produces consistent output.
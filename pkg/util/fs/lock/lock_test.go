@@ -44,6 +44,45 @@ func TestExclusive(t *testing.T) {
 	}
 }
 
+func TestShared(t *testing.T) {
+	test.DropPrivilege(t)
+	defer test.ResetPrivilege(t)
+
+	if _, err := Shared(""); err == nil {
+		t.Errorf("unexpected success with empty path")
+	}
+
+	fd1, err := Shared("/dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd2, err := Shared("/dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Release(fd2)
+
+	ch := make(chan int, 1)
+	go func() {
+		fd, _ := Exclusive("/dev")
+		ch <- fd
+	}()
+
+	select {
+	case <-time.After(1 * time.Second):
+	case <-ch:
+		t.Errorf("exclusive lock acquired while a shared lock is held")
+	}
+
+	Release(fd1)
+	select {
+	case fd := <-ch:
+		Release(fd)
+	case <-time.After(5 * time.Second):
+		t.Errorf("exclusive lock not acquired once the shared lock was released")
+	}
+}
+
 func TestByteRange(t *testing.T) {
 	test.DropPrivilege(t)
 	defer test.ResetPrivilege(t)
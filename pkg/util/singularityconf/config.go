@@ -70,12 +70,14 @@ type File struct {
 	NvidiaContainerCliPath  string   `directive:"nvidia-container-cli path"`
 	UnsquashfsPath          string   `directive:"unsquashfs path"`
 	// Deprecated: ImageDriver is deprecated and will be removed in 4.0.
-	ImageDriver         string `directive:"image driver"`
-	DownloadConcurrency uint   `default:"3" directive:"download concurrency"`
-	DownloadPartSize    uint   `default:"5242880" directive:"download part size"`
-	DownloadBufferSize  uint   `default:"32768" directive:"download buffer size"`
-	SystemdCgroups      bool   `default:"yes" authorized:"yes,no" directive:"systemd cgroups"`
-	SIFFUSE             bool   `default:"no" authorized:"yes,no" directive:"sif fuse"`
+	ImageDriver         string   `directive:"image driver"`
+	DownloadConcurrency uint     `default:"3" directive:"download concurrency"`
+	DownloadPartSize    uint     `default:"5242880" directive:"download part size"`
+	DownloadBufferSize  uint     `default:"32768" directive:"download buffer size"`
+	AllowedRegistries   []string `directive:"allowed registries"`
+	AllowedLibraryHosts []string `directive:"allowed library hosts"`
+	SystemdCgroups      bool     `default:"yes" authorized:"yes,no" directive:"systemd cgroups"`
+	SIFFUSE             bool     `default:"no" authorized:"yes,no" directive:"sif fuse"`
 }
 
 const TemplateAsset = `# SINGULARITY.CONF
@@ -471,6 +473,28 @@ download part size = {{ .DownloadPartSize }}
 # are enabled.
 download buffer size = {{ .DownloadBufferSize }}
 
+# ALLOWED REGISTRIES: [STRING]
+# DEFAULT: NULL
+# Only allow images to be pulled from the docker/OCI and oras registries
+# listed, by host name, with their port if not the default. Docker Hub must
+# be listed as docker.io. If this configuration is undefined (commented or
+# set to NULL), images can be pulled from any registry. Users can restrict
+# the registries further with the --allowed-registry option of pull.
+#allowed registries = docker.io, ghcr.io, registry.example.com:5000
+{{ range $index, $host := .AllowedRegistries }}
+{{- if eq $index 0 }}allowed registries = {{ else }}, {{ end }}{{$host}}
+{{- end }}
+
+# ALLOWED LIBRARY HOSTS: [STRING]
+# DEFAULT: NULL
+# Only allow images to be pulled from the libraries listed, by host name. If
+# this configuration is undefined (commented or set to NULL), images can be
+# pulled from any library.
+#allowed library hosts = library.sylabs.io
+{{ range $index, $host := .AllowedLibraryHosts }}
+{{- if eq $index 0 }}allowed library hosts = {{ else }}, {{ end }}{{$host}}
+{{- end }}
+
 # SYSTEMD CGROUPS: [BOOL]
 # DEFAULT: yes
 # Whether to use systemd to manage container cgroups. Required for rootless cgroups